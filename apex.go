@@ -0,0 +1,20 @@
+package infomaniak
+
+import (
+	"github.com/libdns/libdns"
+)
+
+// resolveApexCnames returns an *ApexCnameError for CNAME records at the zone apex, or converts them to ALIAS records if configured
+func (p *Provider) resolveApexCnames(zone string, records []libdns.Record) ([]libdns.Record, error) {
+	result := make([]libdns.Record, 0, len(records))
+	for _, rec := range records {
+		if RecordType(normalizeType(rec.Type)) == TypeCNAME && isApexName(rec.Name) {
+			if !p.ConvertApexCnameToAlias {
+				return nil, &ApexCnameError{Zone: zone, Target: rec.Value}
+			}
+			rec.Type = "ALIAS"
+		}
+		result = append(result, rec)
+	}
+	return result, nil
+}
@@ -0,0 +1,56 @@
+package infomaniak
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/libdns/libdns"
+)
+
+func Test_SetRecords_ReturnsErrorForCnameAtApex(t *testing.T) {
+	client := TestClient{
+		setter: func(ctx context.Context, zone string, record IkRecord) (*IkRecord, error) {
+			t.Fatalf("Expected that CNAME at apex is not set")
+			return nil, nil
+		},
+	}
+	provider := Provider{client: &client}
+	_, err := provider.SetRecords(context.TODO(), "example.com", []libdns.Record{{Name: "@", Type: "CNAME", Value: "example.org"}})
+
+	var apexErr *ApexCnameError
+	if !errors.As(err, &apexErr) {
+		t.Fatalf("Expected ApexCnameError, got %#v", err)
+	}
+}
+
+func Test_AppendRecords_ConvertsCnameAtApexToAliasIfConfigured(t *testing.T) {
+	client := TestClient{
+		setter: func(ctx context.Context, zone string, record IkRecord) (*IkRecord, error) {
+			assertEquals(t, "Type", "ALIAS", record.Type)
+			return &record, nil
+		},
+	}
+	provider := Provider{client: &client, ConvertApexCnameToAlias: true}
+	recs, err := provider.AppendRecords(context.TODO(), "example.com", []libdns.Record{{Name: "", Type: "CNAME", Value: "example.org"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "records", 1, len(recs))
+}
+
+func Test_SetRecords_ReturnsErrorForLowercaseCnameAtApex(t *testing.T) {
+	client := TestClient{
+		setter: func(ctx context.Context, zone string, record IkRecord) (*IkRecord, error) {
+			t.Fatalf("Expected that CNAME at apex is not set")
+			return nil, nil
+		},
+	}
+	provider := Provider{client: &client}
+	_, err := provider.SetRecords(context.TODO(), "example.com", []libdns.Record{{Name: "@", Type: "cname", Value: "example.org"}})
+
+	var apexErr *ApexCnameError
+	if !errors.As(err, &apexErr) {
+		t.Fatalf("Expected ApexCnameError, got %#v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package infomaniak
+
+import (
+	"encoding/json"
+)
+
+// errorExplanation describes an error code of the infomaniak API and how to fix its cause
+type errorExplanation struct {
+	explanation string
+	hint        string
+}
+
+// Explanations of error codes returned by the infomaniak API, more specific codes of nested errors are preferred
+var errorExplanations = map[string]errorExplanation{
+	"not_authorized": {
+		explanation: "the API token is not allowed to perform the request",
+		hint:        "issue a token whose scopes include 'domain' at https://manager.infomaniak.com/v3/infomaniak-api",
+	},
+	"invalid_token": {
+		explanation: "the API token is invalid or expired",
+		hint:        "issue a new token at https://manager.infomaniak.com/v3/infomaniak-api",
+	},
+	"object_not_found": {
+		explanation: "the domain or record does not exist or is not part of the account the API token belongs to",
+		hint:        "check that the domain is managed by the account and that the record was not deleted in the meantime",
+	},
+	"too_many_requests": {
+		explanation: "the rate limit of the API was exceeded",
+		hint:        "retry later or reduce the number of calls, e.g. by enabling the record cache with RecordCacheTtl",
+	},
+	"validation_failed": {
+		explanation: "the API rejected the record as invalid",
+		hint:        "check the name, type, value and TTL of the record",
+	},
+	"validation_rule_record_dns_ttl": {
+		explanation: "the TTL of the record is not accepted by infomaniak",
+		hint:        "use one of the TTLs listed in TTLPresets",
+	},
+	"validation_rule_record_dns_target": {
+		explanation: "the value of the record is not valid for its type",
+		hint:        "check the format of the record's value, host names must be fully qualified",
+	},
+	"validation_rule_record_dns_source": {
+		explanation: "the name of the record is not valid or not part of the zone",
+		hint:        "check that the record's name is relative to the zone and only contains valid labels",
+	},
+	"record_already_exists": {
+		explanation: "a record with the same name, type and value already exists",
+		hint:        "use SetRecords to update existing records instead of appending them",
+	},
+	"cname_conflict": {
+		explanation: "a CNAME record cannot coexist with other records of the same name",
+		hint:        "delete the other records of the name or choose a different name",
+	},
+}
+
+// ikErrorDetail error as returned by the infomaniak API, which may contain nested errors
+type ikErrorDetail struct {
+	Code        string          `json:"code"`
+	Description string          `json:"description"`
+	Errors      []ikErrorDetail `json:"errors"`
+}
+
+// errorCodes returns the codes of the errors returned by the API, outer errors first
+func errorCodes(rawErrors json.RawMessage) []string {
+	var detail ikErrorDetail
+	if len(rawErrors) == 0 || json.Unmarshal(rawErrors, &detail) != nil {
+		return nil
+	}
+	codes := make([]string, 0)
+	pending := []ikErrorDetail{detail}
+	for len(pending) > 0 {
+		current := pending[0]
+		pending = append(pending[1:], current.Errors...)
+		if current.Code != "" {
+			codes = append(codes, current.Code)
+		}
+	}
+	return codes
+}
+
+// explainErrorCodes returns the explanation of the most specific known error code
+func explainErrorCodes(codes []string) (errorExplanation, bool) {
+	for i := len(codes) - 1; i >= 0; i-- {
+		if explanation, ok := errorExplanations[codes[i]]; ok {
+			return explanation, true
+		}
+	}
+	return errorExplanation{}, false
+}
@@ -0,0 +1,42 @@
+package infomaniak
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func Test_DoRequest_ExplainsKnownErrorCodes(t *testing.T) {
+	httpClient := newHttpTestClient(func(req *http.Request) *http.Response {
+		return &http.Response{
+			StatusCode: 422,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"result":"error","error":{"code":"validation_failed","errors":[{"code":"validation_rule_record_dns_ttl","description":"invalid ttl"}]}}`)),
+			Header:     make(http.Header),
+		}
+	})
+	client := Client{domains: &[]IkDomain{{Name: "example.com", ID: 100}}, HttpClient: httpClient}
+
+	_, err := client.CreateOrUpdateRecord(context.TODO(), "example.com", IkRecord{Type: "A", TtlInSec: 42})
+	var apiErr *ApiError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected ApiError, got %v", err)
+	}
+	assertEquals(t, "codes", "validation_failed,validation_rule_record_dns_ttl", strings.Join(apiErr.Codes, ","))
+	assertEquals(t, "explanation", errorExplanations["validation_rule_record_dns_ttl"].explanation, apiErr.Explanation)
+	if !strings.Contains(err.Error(), "TTLPresets") {
+		t.Fatalf("Expected hint in error message, got %s", err.Error())
+	}
+}
+
+func Test_NewApiError_KeepsUnknownErrorCodesUnexplained(t *testing.T) {
+	apiErr := newApiError(500, nil, []byte(`{"code":"something_new"}`))
+	assertEquals(t, "codes", "something_new", strings.Join(apiErr.Codes, ","))
+	assertEquals(t, "explanation", "", apiErr.Explanation)
+
+	apiErr = newApiError(500, nil, []byte(`"not an object"`))
+	assertEqualsInt(t, "number of codes", 0, len(apiErr.Codes))
+}
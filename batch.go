@@ -0,0 +1,157 @@
+package infomaniak
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+// setBatch records of SetRecords calls to the same zone that are applied together
+type setBatch struct {
+	records []libdns.Record
+	done    chan struct{}
+	result  []libdns.Record
+	err     error
+
+	// context the batch is applied with, it is only cancelled once all callers of the batch are gone
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// number of callers that still wait for the batch
+	waiting int
+
+	// key of the batch within the pending batches
+	key string
+}
+
+// setBatcher collects the pending batch of SetRecords calls per zone and context overrides
+type setBatcher struct {
+	pending map[string]*setBatch
+	mu      sync.Mutex
+}
+
+// batchContext context of a batch that carries the values of the context of the call that started the batch,
+// e.g. WithDryRun, without being cancelled together with that context - all callers of a batch override the same values
+type batchContext struct {
+	context.Context
+	values context.Context
+}
+
+// Value returns the value of the context of the call that started the batch
+func (c batchContext) Value(key interface{}) interface{} {
+	return c.values.Value(key)
+}
+
+// batchKey returns the key of the pending batch for the zone and the context, calls only share a batch if their contexts
+// override the same values, so that e.g. a dry run never swallows the records of a call that is not a dry run
+func batchKey(ctx context.Context, zone string) string {
+	token, _ := getRequestToken(ctx)
+	timeout, _ := getRequestTimeout(ctx)
+	return fmt.Sprintf("%s|%t|%t|%s|%q", zone, isDryRun(ctx), skipsRecordDescriptions(ctx), timeout, token)
+}
+
+// join adds the records to the pending batch of the zone and the context's overrides and returns the batch together with the information
+// if the caller started the batch and therefore has to apply it
+func (b *setBatcher) join(ctx context.Context, zone string, records []libdns.Record) (*setBatch, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.pending == nil {
+		b.pending = make(map[string]*setBatch)
+	}
+	key := batchKey(ctx, zone)
+	batch, ok := b.pending[key]
+	if ok && batch.ctx.Err() != nil {
+		// all callers of the pending batch are gone, it is applied without the records of later calls
+		ok = false
+	}
+	if !ok {
+		batchCtx, cancel := context.WithCancel(context.Background())
+		batch = &setBatch{done: make(chan struct{}), ctx: batchContext{Context: batchCtx, values: ctx}, cancel: cancel, key: key}
+		b.pending[key] = batch
+	}
+	batch.records = append(batch.records, records...)
+	batch.waiting++
+	return batch, !ok
+}
+
+// leave removes a caller whose context is done from the batch, the batch is cancelled once no caller waits for it anymore
+func (b *setBatcher) leave(batch *setBatch) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	batch.waiting--
+	if batch.waiting <= 0 {
+		batch.cancel()
+	}
+}
+
+// close removes the batch from the pending batches so that later calls start a new batch and returns its records
+func (b *setBatcher) close(batch *setBatch) []libdns.Record {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.pending[batch.key] == batch {
+		delete(b.pending, batch.key)
+	}
+	return batch.records
+}
+
+// setRecordsBatched sets the records together with the records of all other SetRecords calls to the same zone that are
+// made within the SetRecordsBatchWindow after the first one. Only calls whose contexts override the same values, e.g. WithDryRun,
+// WithRequestToken or WithRequestTimeout, share a batch. The batch is applied in a single pass with these values, but it is
+// only cancelled once the contexts of all its callers are done.
+// Each caller receives the records of the batch that have the same name and type as its own records.
+func (p *Provider) setRecordsBatched(ctx context.Context, zone string, records []libdns.Record) ([]libdns.Record, error) {
+	batch, first := p.batcher.join(ctx, zone, records)
+	if first {
+		go p.applyBatch(zone, batch)
+	}
+
+	select {
+	case <-batch.done:
+	case <-ctx.Done():
+		p.batcher.leave(batch)
+		return nil, ctx.Err()
+	}
+	if batch.err != nil {
+		return nil, batch.err
+	}
+	return filterBatchResult(batch.result, records), nil
+}
+
+// applyBatch waits for the batch window and applies the records of the batch
+func (p *Provider) applyBatch(zone string, batch *setBatch) {
+	defer batch.cancel()
+	ctx := batch.ctx
+	timer := time.NewTimer(p.SetRecordsBatchWindow)
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		timer.Stop()
+	}
+	batchRecs := p.batcher.close(batch)
+	batch.result, batch.err = p.withZoneLock(ctx, zone, func() ([]libdns.Record, error) {
+		return p.withJournal(zone, "set", batchRecs, func() ([]libdns.Record, error) {
+			return p.withChangeLog(ctx, zone, "set", func() ([]libdns.Record, error) {
+				return p.setRecords(ctx, zone, batchRecs, nil)
+			})
+		})
+	})
+	close(batch.done)
+}
+
+// filterBatchResult returns the records of the batch's result that have the same name and type as one of the given records
+func filterBatchResult(result []libdns.Record, records []libdns.Record) []libdns.Record {
+	coords := make(map[string]bool, len(records))
+	for _, rec := range records {
+		coords[getCoordinates(rec)] = true
+	}
+	filtered := make([]libdns.Record, 0, len(records))
+	for _, rec := range result {
+		if coords[getCoordinates(rec)] {
+			filtered = append(filtered, rec)
+		}
+	}
+	return filtered
+}
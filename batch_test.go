@@ -0,0 +1,123 @@
+package infomaniak
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+func Test_SetRecords_CoalescesCallsWithinBatchWindow(t *testing.T) {
+	var getterCalls int32
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			atomic.AddInt32(&getterCalls, 1)
+			return []IkRecord{}, nil
+		},
+		setter: func(ctx context.Context, zone string, record IkRecord) (*IkRecord, error) {
+			record.ID = record.SourceIdn
+			return &record, nil
+		},
+	}
+	provider := Provider{client: &client, SetRecordsBatchWindow: 50 * time.Millisecond, RecordCacheTtl: time.Minute}
+
+	names := []string{"_acme-challenge.a", "_acme-challenge.b", "_acme-challenge.c"}
+	results := make([][]libdns.Record, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			recs, err := provider.SetRecords(context.TODO(), "example.com", []libdns.Record{{Type: "TXT", Name: name, Value: "token"}})
+			if err != nil {
+				t.Error(err)
+			}
+			results[i] = recs
+		}(i, name)
+	}
+	wg.Wait()
+
+	assertEqualsInt(t, "API calls to list records", 1, int(atomic.LoadInt32(&getterCalls)))
+	for i, name := range names {
+		assertEqualsInt(t, "records of "+name, 1, len(results[i]))
+		assertEquals(t, "name", name, results[i][0].Name)
+	}
+}
+
+func Test_SetRecords_AppliesBatchIfFirstCallerIsCancelled(t *testing.T) {
+	client := TestClient{
+		setter: func(ctx context.Context, zone string, record IkRecord) (*IkRecord, error) {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			record.ID = record.SourceIdn
+			return &record, nil
+		},
+	}
+	provider := Provider{client: &client, SetRecordsBatchWindow: 50 * time.Millisecond}
+
+	firstCtx, cancelFirst := context.WithCancel(context.TODO())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := provider.SetRecords(firstCtx, "example.com", []libdns.Record{{Type: "TXT", Name: "_acme-challenge.a", Value: "token"}})
+		firstErr <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	secondResult := make(chan []libdns.Record, 1)
+	go func() {
+		recs, err := provider.SetRecords(context.TODO(), "example.com", []libdns.Record{{Type: "TXT", Name: "_acme-challenge.b", Value: "token"}})
+		if err != nil {
+			t.Error(err)
+		}
+		secondResult <- recs
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancelFirst()
+
+	if err := <-firstErr; err != context.Canceled {
+		t.Fatalf("Expected first call to be cancelled, got %v", err)
+	}
+	recs := <-secondResult
+	assertEqualsInt(t, "records of second call", 1, len(recs))
+	assertEquals(t, "name", "_acme-challenge.b", recs[0].Name)
+}
+
+func Test_SetRecords_DoesNotBatchDryRunWithOtherCalls(t *testing.T) {
+	var mu sync.Mutex
+	written := make([]string, 0)
+	client := TestClient{
+		setter: func(ctx context.Context, zone string, record IkRecord) (*IkRecord, error) {
+			if !isDryRun(ctx) {
+				mu.Lock()
+				written = append(written, record.SourceIdn)
+				mu.Unlock()
+			}
+			record.ID = record.SourceIdn
+			return &record, nil
+		},
+	}
+	provider := Provider{client: &client, SetRecordsBatchWindow: 50 * time.Millisecond}
+
+	var wg sync.WaitGroup
+	ctxs := []context.Context{WithDryRun(context.TODO()), context.TODO()}
+	names := []string{"_acme-challenge.a", "_acme-challenge.b"}
+	for i := range ctxs {
+		wg.Add(1)
+		go func(ctx context.Context, name string) {
+			defer wg.Done()
+			recs, err := provider.SetRecords(ctx, "example.com", []libdns.Record{{Type: "TXT", Name: name, Value: "token"}})
+			if err != nil {
+				t.Error(err)
+			}
+			assertEqualsInt(t, "records of "+name, 1, len(recs))
+		}(ctxs[i], names[i])
+		time.Sleep(10 * time.Millisecond)
+	}
+	wg.Wait()
+
+	assertEqualsInt(t, "written records", 1, len(written))
+	assertEquals(t, "written record", "_acme-challenge.b.example.com", written[0])
+}
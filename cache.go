@@ -0,0 +1,60 @@
+package infomaniak
+
+import (
+	"sync"
+	"time"
+)
+
+// recordCache is a short-lived cache of the records listed per zone
+type recordCache struct {
+	// cached records by zone
+	entries map[string]recordCacheEntry
+
+	// mutex to prevent race conditions
+	mu sync.Mutex
+}
+
+// recordCacheEntry records listed for a zone and the point in time until they are valid
+type recordCacheEntry struct {
+	records   []IkRecord
+	expiresAt time.Time
+}
+
+// get returns a copy of the cached records of the given zone if they did not expire yet
+func (c *recordCache) get(zone string) ([]IkRecord, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[zone]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return append([]IkRecord(nil), entry.records...), true
+}
+
+// getStale returns a copy of the cached records of the given zone even if they expired already
+func (c *recordCache) getStale(zone string) ([]IkRecord, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[zone]
+	if !ok {
+		return nil, false
+	}
+	return append([]IkRecord(nil), entry.records...), true
+}
+
+// put caches a copy of the records of the given zone for the given duration
+func (c *recordCache) put(zone string, records []IkRecord, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]recordCacheEntry)
+	}
+	c.entries[zone] = recordCacheEntry{records: append([]IkRecord(nil), records...), expiresAt: time.Now().Add(ttl)}
+}
+
+// invalidate removes all cached records - as zones can be nested, a write to one zone can affect others
+func (c *recordCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = nil
+}
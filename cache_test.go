@@ -0,0 +1,35 @@
+package infomaniak
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_RecordCache_ReturnsCachedRecords(t *testing.T) {
+	cache := recordCache{}
+	cache.put("example.com", []IkRecord{{ID: "1"}}, time.Minute)
+
+	recs, ok := cache.get("example.com")
+	if !ok || len(recs) != 1 {
+		t.Fatalf("Expected 1 cached record, got %d", len(recs))
+	}
+}
+
+func Test_RecordCache_DoesNotReturnExpiredRecords(t *testing.T) {
+	cache := recordCache{}
+	cache.put("example.com", []IkRecord{{ID: "1"}}, -time.Second)
+
+	if _, ok := cache.get("example.com"); ok {
+		t.Fatalf("Expected expired records not to be returned")
+	}
+}
+
+func Test_RecordCache_DoesNotReturnInvalidatedRecords(t *testing.T) {
+	cache := recordCache{}
+	cache.put("example.com", []IkRecord{{ID: "1"}}, time.Minute)
+	cache.invalidate()
+
+	if _, ok := cache.get("example.com"); ok {
+		t.Fatalf("Expected invalidated records not to be returned")
+	}
+}
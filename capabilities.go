@@ -0,0 +1,39 @@
+package infomaniak
+
+// Capabilities describes what the provider supports, so generic tooling can adapt its behavior without provider specific code
+type Capabilities struct {
+	// RecordTypes that can be managed
+	RecordTypes []string
+
+	// MinTtlInSec smallest TTL in seconds that is accepted, TTLPresets lists all accepted TTLs
+	MinTtlInSec uint
+
+	// MaxTtlInSec largest TTL in seconds that is accepted
+	MaxTtlInSec uint
+
+	// BulkOperations is set if multiple records can be changed with a single API call
+	BulkOperations bool
+
+	// Pagination is set if records are listed page by page
+	Pagination bool
+
+	// ZoneListing is set if the zones of the account can be listed
+	ZoneListing bool
+}
+
+// Capabilities returns what the provider supports. Each record is changed with its own API call
+// and all records of a zone are listed at once.
+func (p *Provider) Capabilities() Capabilities {
+	recordTypes := make([]string, 0, len(supportedTypes))
+	for _, recType := range supportedTypes {
+		recordTypes = append(recordTypes, string(recType))
+	}
+	return Capabilities{
+		RecordTypes:    recordTypes,
+		MinTtlInSec:    uint(TTLPresets[0]),
+		MaxTtlInSec:    uint(TTLPresets[len(TTLPresets)-1]),
+		BulkOperations: false,
+		Pagination:     false,
+		ZoneListing:    false,
+	}
+}
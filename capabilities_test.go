@@ -0,0 +1,16 @@
+package infomaniak
+
+import (
+	"testing"
+)
+
+func Test_Capabilities_ReturnsCopyOfRecordTypes(t *testing.T) {
+	provider := Provider{}
+	capabilities := provider.Capabilities()
+	capabilities.RecordTypes[0] = "changed"
+
+	assertEquals(t, "first type", "A", provider.Capabilities().RecordTypes[0])
+	if provider.Capabilities().MinTtlInSec > provider.Capabilities().MaxTtlInSec {
+		t.Fatalf("Expected min TTL to be smaller than max TTL")
+	}
+}
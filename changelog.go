@@ -0,0 +1,133 @@
+package infomaniak
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+// ChangeLogEntry describes the RRsets changed by a single SetRecords or DeleteRecords call
+type ChangeLogEntry struct {
+	// Time the operation finished
+	Time time.Time `json:"time"`
+
+	// Operation that changed the records: "set" or "delete"
+	Operation string `json:"operation"`
+
+	// Zone the records belong to
+	Zone string `json:"zone"`
+
+	// RRsets changed by the operation, sorted by name and type
+	RRsets []RRsetChange `json:"rrsets"`
+}
+
+// RRsetChange records of a RRset before and after an operation
+type RRsetChange struct {
+	// Name of the RRset relative to the zone
+	Name string `json:"name"`
+
+	// Type of the RRset
+	Type string `json:"type"`
+
+	// Before records of the RRset before the operation
+	Before []ChangeLogRecord `json:"before"`
+
+	// After records of the RRset after the operation
+	After []ChangeLogRecord `json:"after"`
+}
+
+// ChangeLogRecord a record as it is written to the change log
+type ChangeLogRecord struct {
+	ID       string `json:"id,omitempty"`
+	Value    string `json:"value"`
+	TtlInSec uint   `json:"ttl"`
+	Priority uint   `json:"priority,omitempty"`
+}
+
+// isChangeLogEnabled returns if changes have to be logged
+func (p *Provider) isChangeLogEnabled() bool {
+	return p.ChangeLog != nil || p.ChangeLogHook != nil
+}
+
+// withChangeLog runs the operation and emits a change log entry with the RRsets it changed if a change log is configured,
+// the returned records have to be the records that were set or deleted by the operation
+func (p *Provider) withChangeLog(ctx context.Context, zone string, operation string, apply func() ([]libdns.Record, error)) ([]libdns.Record, error) {
+	if !p.isChangeLogEnabled() {
+		return apply()
+	}
+
+	before, err := p.getRecords(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+	changedRecs, err := apply()
+	if len(changedRecs) > 0 {
+		p.emitChangeLog(buildChangeLogEntry(zone, operation, before, changedRecs))
+	}
+	return changedRecs, err
+}
+
+// buildChangeLogEntry returns the entry describing the RRsets of the changed records before and after the operation
+func buildChangeLogEntry(zone string, operation string, before []libdns.Record, changedRecs []libdns.Record) ChangeLogEntry {
+	model := newZoneModel(before)
+	changedCoords := make(map[string]libdns.Record)
+	for _, rec := range changedRecs {
+		changedCoords[getCoordinates(rec)] = rec
+	}
+
+	rrsets := make([]RRsetChange, 0, len(changedCoords))
+	for _, rec := range changedCoords {
+		rrsets = append(rrsets, RRsetChange{Name: normalizeName(rec.Name), Type: rec.Type, Before: getChangeLogRecords(model, rec.Name, rec.Type)})
+	}
+
+	for _, rec := range changedRecs {
+		if operation == "delete" {
+			model.remove(rec)
+		} else {
+			model.put(rec)
+		}
+	}
+	for i := range rrsets {
+		rrsets[i].After = getChangeLogRecords(model, rrsets[i].Name, rrsets[i].Type)
+	}
+
+	sort.Slice(rrsets, func(i, j int) bool {
+		if rrsets[i].Name != rrsets[j].Name {
+			return rrsets[i].Name < rrsets[j].Name
+		}
+		return rrsets[i].Type < rrsets[j].Type
+	})
+	return ChangeLogEntry{Time: time.Now(), Operation: operation, Zone: zone, RRsets: rrsets}
+}
+
+// getChangeLogRecords returns the records of the RRset with the given name and type
+func getChangeLogRecords(model *zoneModel, name string, recType string) []ChangeLogRecord {
+	records := make([]ChangeLogRecord, 0)
+	for _, rec := range model.resolve(name) {
+		if rec.Type == recType {
+			records = append(records, ChangeLogRecord{ID: rec.ID, Value: rec.Value, TtlInSec: uint(rec.TTL), Priority: rec.Priority})
+		}
+	}
+	return records
+}
+
+// emitChangeLog passes the entry to the ChangeLogHook and writes it as a JSON line to the ChangeLog, entries of concurrent
+// operations are written one after another - failures to write the change log do not fail the operation
+func (p *Provider) emitChangeLog(entry ChangeLogEntry) {
+	if p.ChangeLogHook != nil {
+		p.ChangeLogHook(entry)
+	}
+	if p.ChangeLog == nil {
+		return
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	p.changeLogMu.Lock()
+	defer p.changeLogMu.Unlock()
+	_, _ = p.ChangeLog.Write(append(line, '\n'))
+}
@@ -0,0 +1,70 @@
+package infomaniak
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/libdns/libdns"
+)
+
+func Test_SetRecords_WritesChangeLogWithRRsetsBeforeAndAfter(t *testing.T) {
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			return []IkRecord{
+				{ID: "1", Type: "A", SourceIdn: "www.example.com", Target: "192.0.2.1", TtlInSec: 300},
+				{ID: "2", Type: "A", SourceIdn: "www.example.com", Target: "192.0.2.2", TtlInSec: 300},
+			}, nil
+		},
+		setter: func(ctx context.Context, zone string, record IkRecord) (*IkRecord, error) {
+			return &record, nil
+		},
+	}
+	var changeLog bytes.Buffer
+	provider := Provider{client: &client, ChangeLog: &changeLog}
+
+	_, err := provider.SetRecords(context.TODO(), "example.com", []libdns.Record{{ID: "1", Type: "A", Name: "www", Value: "192.0.2.3", TTL: 300}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var entry ChangeLogEntry
+	if err := json.Unmarshal(changeLog.Bytes(), &entry); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "operation", "set", entry.Operation)
+	assertEquals(t, "zone", "example.com", entry.Zone)
+	assertEqualsInt(t, "RRsets", 1, len(entry.RRsets))
+	assertEquals(t, "name", "www", entry.RRsets[0].Name)
+	assertEqualsInt(t, "records before", 2, len(entry.RRsets[0].Before))
+	assertEquals(t, "value before", "192.0.2.1", entry.RRsets[0].Before[0].Value)
+	assertEqualsInt(t, "records after", 2, len(entry.RRsets[0].After))
+	for _, rec := range entry.RRsets[0].After {
+		if rec.ID == "1" {
+			assertEquals(t, "value after", "192.0.2.3", rec.Value)
+		}
+	}
+}
+
+func Test_DeleteRecords_CallsChangeLogHook(t *testing.T) {
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			return []IkRecord{{ID: "1", Type: "TXT", SourceIdn: "_acme-challenge.example.com", Target: "token"}}, nil
+		},
+		deleter: func(ctx context.Context, zone string, id string) error {
+			return nil
+		},
+	}
+	entries := make([]ChangeLogEntry, 0)
+	provider := Provider{client: &client, ChangeLogHook: func(entry ChangeLogEntry) { entries = append(entries, entry) }}
+
+	_, err := provider.DeleteRecords(context.TODO(), "example.com", []libdns.Record{{Type: "TXT", Name: "_acme-challenge"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "entries", 1, len(entries))
+	assertEquals(t, "operation", "delete", entries[0].Operation)
+	assertEqualsInt(t, "records before", 1, len(entries[0].RRsets[0].Before))
+	assertEqualsInt(t, "records after", 0, len(entries[0].RRsets[0].After))
+}
@@ -0,0 +1,45 @@
+package infomaniak
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+// Label of the names at which ACME DNS-01 challenges are published
+const acmeChallengeLabel = "_acme-challenge"
+
+// CleanupStaleChallenges deletes the ACME challenge TXT records of the zone that were not changed for longer than olderThan,
+// e.g. leftovers of crashed certificate issuances. Records without update time are never deleted. It returns the deleted records.
+func (p *Provider) CleanupStaleChallenges(ctx context.Context, zone string, olderThan time.Duration) ([]libdns.Record, error) {
+	zone = getWithoutTrailingDot(zone)
+	return p.withZoneLock(ctx, zone, func() ([]libdns.Record, error) {
+		ikRecords, err := p.getDnsRecordsForZone(ctx, zone)
+		if err != nil {
+			return nil, err
+		}
+
+		threshold := time.Now().Add(-olderThan)
+		staleRecs := make([]libdns.Record, 0)
+		for _, rec := range ikRecords {
+			if isAcmeChallenge(rec, zone) && !rec.UpdatedAt.IsZero() && rec.UpdatedAt.Before(threshold) {
+				staleRecs = append(staleRecs, rec.ToLibDnsRecord(zone))
+			}
+		}
+		if len(staleRecs) <= 0 {
+			return staleRecs, nil
+		}
+		return p.deleteRecords(ctx, zone, staleRecs, nil)
+	})
+}
+
+// isAcmeChallenge returns if the record is a TXT record at a name used for ACME challenges
+func isAcmeChallenge(rec IkRecord, zone string) bool {
+	if RecordType(normalizeType(rec.Type)) != TypeTXT {
+		return false
+	}
+	name := normalizeName(toRelativeName(rec.SourceIdn, zone))
+	return name == acmeChallengeLabel || strings.HasPrefix(name, acmeChallengeLabel+".")
+}
@@ -0,0 +1,37 @@
+package infomaniak
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func Test_CleanupStaleChallenges_DeletesOnlyOldChallengeRecords(t *testing.T) {
+	old := time.Now().Add(-2 * time.Hour)
+	deletedIds := make([]string, 0)
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			return []IkRecord{
+				{ID: "1", Type: "TXT", SourceIdn: "_acme-challenge.example.com", UpdatedAt: old},
+				{ID: "2", Type: "TXT", SourceIdn: "_acme-challenge.www.example.com", UpdatedAt: time.Now()},
+				{ID: "3", Type: "TXT", SourceIdn: "other.example.com", UpdatedAt: old},
+				{ID: "4", Type: "TXT", SourceIdn: "_acme-challenge.example.com"},
+				{ID: "5", Type: "TXT", SourceIdn: "_acme-challenge.mail.example.com", UpdatedAt: old},
+			}, nil
+		},
+		deleter: func(ctx context.Context, zone string, id string) error {
+			deletedIds = append(deletedIds, id)
+			return nil
+		},
+	}
+	provider := Provider{client: &client}
+
+	deleted, err := provider.CleanupStaleChallenges(context.TODO(), "example.com.", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "deleted records", 2, len(deleted))
+	assertEqualsInt(t, "delete calls", 2, len(deletedIds))
+	assertEquals(t, "first ID", "1", deletedIds[0])
+	assertEquals(t, "second ID", "5", deletedIds[1])
+}
@@ -2,75 +2,182 @@ package infomaniak
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"sync"
-
-	"github.com/libdns/libdns"
+	"time"
 )
 
 // Base URL to infomaniak API
 const apiBaseUrl = "https://api.infomaniak.com"
 
+// Duration for which zones that are not managed by the account are remembered if not configured otherwise
+const defaultZoneNotFoundCacheTtl = 30 * time.Second
+
+// Minimum interval between two reloads of the domains that check if the domain of a zone was deleted
+const zoneGoneCheckInterval = 10 * time.Second
+
 // URL of DNS record endpoint
 const apiDnsRecord = apiBaseUrl + "/1/domain/%d/dns/record"
 
-// Client that abstracts and calls infomaniak API
+// Client that abstracts and calls infomaniak API, it is safe for concurrent use
+// as long as its exported fields are not modified after its first use
 type Client struct {
 	// infomaniak API token
 	Token string
 
-	// http client used for requests
+	// optional source of the API token, Token is ignored if set
+	TokenSource TokenSource
+
+	// http client used for requests, a client with a tuned transport is created once if not set
 	HttpClient *http.Client
 
+	// additional headers sent with every request
+	ExtraHeaders map[string]string
+
+	// optional hook called for every request right before it is sent,
+	// e.g. to sign the request - if it returns an error, the request is not sent
+	RequestHook func(req *http.Request) error
+
+	// optional hook called with every response including its status code, headers and raw body,
+	// e.g. to track rate limit counters
+	ResponseHook func(resp *IkResponse)
+
+	// optional hook called once per zone if the zone is managed by an infomaniak domain with a different name,
+	// e.g. to log which domain was chosen for a subzone
+	ZoneResolvedHook func(resolution ZoneResolution)
+
+	// if set, records are listed without their descriptions, which makes responses smaller -
+	// priorities that are only returned as part of the description are not available then
+	SkipRecordDescriptions bool
+
+	// optional domain whose records are managed for all zones, if set the domains of the account are never listed,
+	// so tokens that may only access record endpoints can be used - zones outside of the domain are not found
+	ManagedZoneOverride *IkDomain
+
+	// duration for which zones that are not managed by the account are remembered before the domains of the account
+	// are listed again to find domains added in the meantime, defaults to 30 seconds if not set
+	ZoneNotFoundCacheTtl time.Duration
+
+	// codec used to encode request bodies and decode response bodies, StdCodec if not set
+	Codec Codec
+
+	// optional names of record fields used by the API if they differ from the current API revision, e.g. {"source": "name"} -
+	// detected from the first listed record if not set
+	FieldNames FieldNames
+
+	// names of record fields detected from the first listed record
+	detectedFieldNames FieldNames
+
+	// zones that were not found, by the point in time until which the result is cached
+	notFoundZones map[string]time.Time
+
+	// zones for which the zone resolved hook was already called
+	resolvedZones map[string]bool
+
 	// cache of domains registered for the
 	// current infomaniak account to prevent
 	// that we have to load them for each request
 	domains *[]IkDomain
 
+	// set if the domains of some pages are not cached, as loading stopped early at an exact match
+	domainsPartial bool
+
+	// point in time the domains were last reloaded to check if the domain of a zone was deleted
+	zoneGoneCheckedAt time.Time
+
+	// creates the default http client if none is set
+	httpClientOnce sync.Once
+
 	// mutex to prevent race conditions
 	mu sync.Mutex
 }
 
-// GetDnsRecordsForZone loads all dns records for a given zone
+// GetDnsRecordsForZone loads all dns records for a given zone, if some records cannot be decoded
+// the remaining records are returned together with a *RecordMappingError
 func (c *Client) GetDnsRecordsForZone(ctx context.Context, zone string) ([]IkRecord, error) {
 	domain, err := c.getDomainForZone(ctx, zone)
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf(apiDnsRecord, domain.ID), nil)
+	withDescription := !c.SkipRecordDescriptions && !skipsRecordDescriptions(ctx)
+	records, decodingErrs, err := c.listRecords(ctx, domain, withDescription)
 	if err != nil {
-		return nil, err
-	}
-
-	var dnsRecords []IkRecord
-	_, err = c.doRequest(req, &dnsRecords)
-	if err != nil {
-		return nil, err
+		return nil, c.checkZoneGone(ctx, zone, domain, err)
 	}
 
-	zoneRecords := make([]IkRecord, 0)
-	for _, rec := range dnsRecords {
-		if strings.HasSuffix(rec.SourceIdn, zone) {
+	zoneRecords := make([]IkRecord, 0, len(records))
+	for _, rec := range records {
+		if rec.SourceIdn == "" || rec.SourceIdn == "." {
+			rec.SourceIdn = toAbsoluteName(rec.Source, domain.Name)
+		}
+		if isInZone(rec.SourceIdn, zone) {
 			zoneRecords = append(zoneRecords, rec)
 		}
 	}
+	// records that cannot be decoded are skipped and reported together, so the remaining records are still usable
+	if len(decodingErrs) > 0 {
+		return zoneRecords, &RecordMappingError{Zone: zone, Errors: decodingErrs}
+	}
 	return zoneRecords, nil
 }
 
+// listRecords loads the records of all pages of the domain, so that callers always see all records of a zone. Records are
+// decoded while the response is read, the errors of records that cannot be decoded are returned separately.
+func (c *Client) listRecords(ctx context.Context, domain IkDomain, withDescription bool) ([]IkRecord, []error, error) {
+	query := url.Values{}
+	if withDescription {
+		query.Set("with", "records_description")
+	}
+
+	list := recordListDecoder{client: c, withDescription: withDescription}
+	for page := 1; ; page++ {
+		if page > 1 {
+			query.Set("page", strconv.Itoa(page))
+		}
+		endpoint := fmt.Sprintf(apiDnsRecord, domain.ID)
+		if len(query) > 0 {
+			endpoint += "?" + query.Encode()
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		resp, err := c.doRequest(req, &list)
+		if err != nil {
+			return nil, nil, err
+		}
+		if page >= resp.Pages {
+			return list.records, list.errors, nil
+		}
+	}
+}
+
 // CreateOrUpdateRecord creates a record if its Id property is not set, otherwise it updates the record
 func (c *Client) CreateOrUpdateRecord(ctx context.Context, zone string, record IkRecord) (*IkRecord, error) {
 	domain, err := c.getDomainForZone(ctx, zone)
 	if err != nil {
 		return nil, err
 	}
-	record.Source = libdns.RelativeName(record.SourceIdn, domain.Name)
+	record.Source = toInfomaniakSource(record.SourceIdn, domain.Name)
 
-	rawJson, err := json.Marshal(record)
+	rawJson, err := c.getCodec().Marshal(record)
+	if err == nil {
+		rawJson, err = renameFields(rawJson, c.getFieldNames(nil))
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -87,21 +194,52 @@ func (c *Client) CreateOrUpdateRecord(ctx context.Context, zone string, record I
 	}
 
 	resp, err := c.doRequest(req, nil)
-	if err != nil {
+	if err != nil && record.ID == "" {
+		return nil, c.checkZoneGone(ctx, zone, domain, err)
+	} else if err != nil {
 		return nil, err
 	}
 
-	if record.ID == "" {
-		var idString string
-		err = json.Unmarshal(resp.Data, &idString)
+	if record.ID == "" && isDryRun(ctx) {
+		record.ID = SyntheticRecordID(record)
+	} else if record.ID == "" {
+		record.ID, err = decodeFlexibleString(resp.Data)
 		if err != nil {
 			return nil, err
 		}
-		record.ID = idString
 	}
 	return &record, nil
 }
 
+// PatchRecord updates the attributes of an existing record that are set in changes, without sending its other attributes
+func (c *Client) PatchRecord(ctx context.Context, zone string, id string, changes IkRecordPatch) error {
+	domain, err := c.getDomainForZone(ctx, zone)
+	if err != nil {
+		return err
+	}
+
+	rawJson, err := c.getCodec().Marshal(changes)
+	if err == nil {
+		rawJson, err = renameFields(rawJson, c.getFieldNames(nil))
+	}
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPatch, fmt.Sprintf(apiDnsRecord, domain.ID)+"/"+id, bytes.NewBuffer(rawJson))
+	if err != nil {
+		return err
+	}
+	_, err = c.doRequest(req, nil)
+	return err
+}
+
+// SyntheticRecordID returns a pseudo ID derived from the name, type and target of the record, which is assigned to records
+// created in dry run - it is deterministic, so that code relying on record IDs can be tested without the API, e.g. by test doubles
+func SyntheticRecordID(record IkRecord) string {
+	hash := sha256.Sum256([]byte(strings.ToLower(record.SourceIdn) + "\x00" + normalizeType(record.Type) + "\x00" + record.Target))
+	return "synthetic-" + hex.EncodeToString(hash[:8])
+}
+
 // DeleteRecord deletes an existing dns record for a given zone
 func (c *Client) DeleteRecord(ctx context.Context, zone string, id string) error {
 	domain, err := c.getDomainForZone(ctx, zone)
@@ -117,58 +255,312 @@ func (c *Client) DeleteRecord(ctx context.Context, zone string, id string) error
 	return err
 }
 
-// getDomainForZone looks for the domain that this zone is under
+// getDomainForZone looks for the domain that this zone is under, the zone resolved hook is called without holding the client's mutex
 func (c *Client) getDomainForZone(ctx context.Context, zone string) (IkDomain, error) {
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	domain, err := c.resolveDomainForZone(ctx, zone)
+	resolution := c.getZoneResolution(zone, domain)
+	c.mu.Unlock()
+	if err != nil {
+		return IkDomain{}, err
+	}
+	if resolution != nil {
+		c.ZoneResolvedHook(*resolution)
+	}
+	return domain, nil
+}
+
+// resolveZone returns the name of the domain that this zone is under
+func (c *Client) resolveZone(ctx context.Context, zone string) (string, error) {
+	domain, err := c.getDomainForZone(ctx, zone)
+	return domain.Name, err
+}
+
+// resolveDomainForZone looks for the domain that this zone is under, the caller must hold the client's mutex
+func (c *Client) resolveDomainForZone(ctx context.Context, zone string) (IkDomain, error) {
+	if c.ManagedZoneOverride != nil {
+		if !isInZone(zone, c.ManagedZoneOverride.Name) {
+			return IkDomain{}, &ZoneNotFoundError{Zone: zone}
+		}
+		return *c.ManagedZoneOverride, nil
+	}
+	if until, ok := c.notFoundZones[zone]; ok && time.Now().Before(until) {
+		return IkDomain{}, &ZoneNotFoundError{Zone: zone, CachedUntil: until}
+	}
+	reloaded := false
 	if c.domains == nil {
-		req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiBaseUrl+"/1/product?service_name=domain", nil)
+		err := c.loadDomains(ctx, zone)
 		if err != nil {
 			return IkDomain{}, err
 		}
-		var domains []IkDomain
-		_, err = c.doRequest(req, &domains)
+		reloaded = true
+	}
+	domain, ok := findDomainForZone(*c.domains, zone)
+	if !reloaded && (!ok || c.domainsPartial && !strings.EqualFold(domain.Name, zone)) {
+		// the domain of the zone may have been added since the domains were cached,
+		// or a more specific domain may be on a page that was not loaded
+		err := c.loadDomains(ctx, zone)
 		if err != nil {
 			return IkDomain{}, err
 		}
-		c.domains = &domains
+		domain, ok = findDomainForZone(*c.domains, zone)
+	}
+	if !ok {
+		return IkDomain{}, c.cacheZoneNotFound(zone)
+	}
+	delete(c.notFoundZones, zone)
+	return domain, nil
+}
+
+// cacheZoneNotFound remembers that the zone is not managed by the account and returns the corresponding error,
+// the caller must hold the client's mutex
+func (c *Client) cacheZoneNotFound(zone string) error {
+	ttl := c.ZoneNotFoundCacheTtl
+	if ttl <= 0 {
+		ttl = defaultZoneNotFoundCacheTtl
 	}
-	for _, domain := range *c.domains {
-		if strings.HasSuffix(zone, domain.Name) {
-			return domain, nil
+	if c.notFoundZones == nil {
+		c.notFoundZones = make(map[string]time.Time)
+	}
+	until := time.Now().Add(ttl)
+	c.notFoundZones[zone] = until
+	return &ZoneNotFoundError{Zone: zone, CachedUntil: until}
+}
+
+// findDomainForZone returns the domain whose name equals the zone, or otherwise the domain with the longest name the zone
+// is a subzone of - names are only compared at label boundaries, so notexample.com is not managed by example.com
+func findDomainForZone(domains []IkDomain, zone string) (IkDomain, bool) {
+	var found IkDomain
+	ok := false
+	for _, domain := range domains {
+		if strings.EqualFold(zone, domain.Name) {
+			return domain, true
+		}
+		if isInZone(zone, domain.Name) && len(domain.Name) > len(found.Name) {
+			found = domain
+			ok = true
+		}
+	}
+	return found, ok
+}
+
+// loadDomains loads the domains of all pages into the cache, the caller must hold the client's mutex. Loading stops early
+// at the page that contains a domain named exactly like the zone, as no other domain would be chosen for it then - lookups
+// of other zones that are not found among the loaded domains reload them.
+func (c *Client) loadDomains(ctx context.Context, zone string) error {
+	domains, partial, err := c.fetchDomains(ctx, zone)
+	if err != nil {
+		return err
+	}
+	c.domains = &domains
+	c.domainsPartial = partial
+	return nil
+}
+
+// fetchDomains loads the domains of all pages as loadDomains does without caching them, it returns if only some pages were
+// loaded - the client's mutex is not required
+func (c *Client) fetchDomains(ctx context.Context, zone string) ([]IkDomain, bool, error) {
+	query := url.Values{}
+	query.Set("service_name", "domain")
+
+	domains := make([]IkDomain, 0)
+	for page := 1; ; page++ {
+		if page > 1 {
+			query.Set("page", strconv.Itoa(page))
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiBaseUrl+"/1/product?"+query.Encode(), nil)
+		if err != nil {
+			return nil, false, err
+		}
+		var pageDomains []IkDomain
+		resp, err := c.doRequest(req, &pageDomains)
+		if err != nil {
+			return nil, false, err
+		}
+		domains = append(domains, pageDomains...)
+		partial := page < resp.Pages
+		if !partial || containsDomainNamed(pageDomains, zone) {
+			return domains, partial, nil
 		}
 	}
-	return IkDomain{}, fmt.Errorf("could not find a domain name for zone %s in listed services", zone)
+}
+
+// containsDomainNamed returns if one of the domains has the given name
+func containsDomainNamed(domains []IkDomain, name string) bool {
+	for _, domain := range domains {
+		if strings.EqualFold(domain.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkZoneGone returns a *ZoneGoneError if an endpoint of the domain responded with HTTP 404 because the domain of the
+// zone was deleted, which is checked by reloading the cached domains - otherwise the given error is returned unchanged.
+// It must only be called for errors of endpoints of the domain, as a 404 of a record's endpoint means the record is
+// missing. The domains are reloaded without holding the client's mutex and at most once per zoneGoneCheckInterval.
+func (c *Client) checkZoneGone(ctx context.Context, zone string, domain IkDomain, err error) error {
+	var apiErr *ApiError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound || c.ManagedZoneOverride != nil {
+		return err
+	}
+	c.mu.Lock()
+	if time.Since(c.zoneGoneCheckedAt) < zoneGoneCheckInterval {
+		c.mu.Unlock()
+		return err
+	}
+	c.zoneGoneCheckedAt = time.Now()
+	c.mu.Unlock()
+
+	domains, partial, loadErr := c.fetchDomains(ctx, domain.Name)
+	if loadErr != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.domains = &domains
+	c.domainsPartial = partial
+	c.mu.Unlock()
+	for _, existingDomain := range domains {
+		if existingDomain.ID == domain.ID {
+			return err
+		}
+	}
+	return &ZoneGoneError{Zone: zone, Domain: domain.Name, Err: err}
+}
+
+// getZoneResolution returns the resolution the zone resolved hook has to be called with if the zone differs from the
+// domain's name and the hook was not yet called for the zone, nil otherwise - the caller must hold the client's mutex
+func (c *Client) getZoneResolution(zone string, domain IkDomain) *ZoneResolution {
+	if c.ZoneResolvedHook == nil || domain.Name == "" || zone == domain.Name || c.resolvedZones[zone] {
+		return nil
+	}
+	if c.resolvedZones == nil {
+		c.resolvedZones = make(map[string]bool)
+	}
+	c.resolvedZones[zone] = true
+	return &ZoneResolution{
+		Zone:   zone,
+		Domain: domain,
+		Reason: fmt.Sprintf("zone '%s' is not a domain of the account, its records are managed in the zone of the parent domain '%s' and filtered by name", zone, domain.Name),
+	}
 }
 
 // doRequest performs the API call for the given request req and parses the response's data to the given data struct - if the parameter is not nil
 func (c *Client) doRequest(req *http.Request, data interface{}) (*IkResponse, error) {
-	req.Header.Set("Authorization", "Bearer "+c.Token)
+	if req.Method != http.MethodGet && isDryRun(req.Context()) {
+		return &IkResponse{Result: "success"}, nil
+	}
+	if timeout, ok := getRequestTimeout(req.Context()); ok {
+		ctx, cancel := context.WithTimeout(req.Context(), timeout)
+		defer cancel()
+		req = req.WithContext(ctx)
+	}
+
+	for name, value := range c.ExtraHeaders {
+		req.Header.Set(name, value)
+	}
+	token, err := c.getToken(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
 
-	rawResp, err := c.HttpClient.Do(req)
+	if c.RequestHook != nil {
+		err = c.RequestHook(req)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rawResp, err := c.getHttpClient().Do(req)
 
 	if err != nil {
 		return nil, err
 	}
 	defer rawResp.Body.Close()
 
-	var resp IkResponse
-	err = json.NewDecoder(rawResp.Body).Decode(&resp)
+	body, err := getDecompressedBody(rawResp)
 	if err != nil {
 		return nil, err
 	}
+	defer body.Close()
+
+	var rawBody bytes.Buffer
+	var reader io.Reader = body
+	if c.ResponseHook != nil {
+		reader = io.TeeReader(body, &rawBody)
+	}
+
+	resp, err := decodeResponse(c.getCodec(), reader, rawResp.StatusCode, data)
+	if err != nil {
+		return nil, err
+	}
+	resp.StatusCode = rawResp.StatusCode
+	resp.Header = rawResp.Header
+	if c.ResponseHook != nil {
+		resp.RawBody = rawBody.Bytes()
+		c.ResponseHook(resp)
+	}
 
 	if rawResp.StatusCode >= 400 || resp.Result != "success" {
-		return nil, fmt.Errorf("got errors: HTTP %d: %+v", rawResp.StatusCode, string(resp.Error))
+		return nil, newApiError(rawResp.StatusCode, rawResp.Header, resp.Error)
 	}
+	return resp, nil
+}
 
-	if data != nil {
-		err = json.Unmarshal(resp.Data, data)
+// decodeResponse decodes the response body in a single pass - the data of successful responses is decoded directly into
+// the given data struct if it is not nil, otherwise it is kept as raw data of the returned response
+func decodeResponse(codec Codec, body io.Reader, statusCode int, data interface{}) (*IkResponse, error) {
+	if data == nil || statusCode >= 400 {
+		var resp IkResponse
+		err := codec.Decode(body, &resp)
 		if err != nil {
 			return nil, err
 		}
+		return &resp, nil
+	}
+
+	envelope := struct {
+		Result string          `json:"result"`
+		Data   interface{}     `json:"data,omitempty"`
+		Error  json.RawMessage `json:"error,omitempty"`
+		Page   int             `json:"page,omitempty"`
+		Pages  int             `json:"pages,omitempty"`
+	}{Data: data}
+	err := codec.Decode(body, &envelope)
+	if err != nil {
+		return nil, err
+	}
+	return &IkResponse{Result: envelope.Result, Error: envelope.Error, Page: envelope.Page, Pages: envelope.Pages}, nil
+}
+
+// getHttpClient returns the configured http client or creates one with a tuned transport on first use
+func (c *Client) getHttpClient() *http.Client {
+	c.httpClientOnce.Do(func() {
+		if c.HttpClient == nil {
+			c.HttpClient = &http.Client{Transport: newTunedTransport()}
+		}
+	})
+	return c.HttpClient
+}
+
+// getToken returns the API token for a request, a token set on the context takes precedence over the client's token
+func (c *Client) getToken(ctx context.Context) (string, error) {
+	if token, ok := getRequestToken(ctx); ok {
+		return token, nil
+	}
+	if c.TokenSource != nil {
+		return c.TokenSource.Token(ctx)
 	}
+	return c.Token, nil
+}
 
-	return &resp, nil
+// getDecompressedBody returns a reader that decompresses the response's body while it is read if the response is gzip encoded
+func getDecompressedBody(rawResp *http.Response) (io.ReadCloser, error) {
+	if !strings.EqualFold(rawResp.Header.Get("Content-Encoding"), "gzip") {
+		return ioutil.NopCloser(rawResp.Body), nil
+	}
+	return gzip.NewReader(rawResp.Body)
 }
@@ -2,12 +2,19 @@ package infomaniak
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
 )
 
 // RoundTripFunc to mock transport layer
@@ -71,6 +78,143 @@ func Test_GetDomainForZone_ReturnsErrorIfDomainForZoneNotFound(t *testing.T) {
 	}
 }
 
+func Test_GetDomainForZone_OnlyMatchesAtLabelBoundaries(t *testing.T) {
+	client := newTestClient(`[{"id":1,"customer_name":"example.com"}]`, nil)
+	_, err := client.getDomainForZone(context.TODO(), "notexample.com")
+
+	var notFoundErr *ZoneNotFoundError
+	if !errors.As(err, &notFoundErr) {
+		t.Fatalf("Expected ZoneNotFoundError, got %v", err)
+	}
+}
+
+func Test_GetDomainForZone_PrefersExactAndLongestMatch(t *testing.T) {
+	client := newTestClient(`[{"id":1,"customer_name":"example.com"},{"id":2,"customer_name":"shop.example.com"},{"id":3,"customer_name":"eu.shop.example.com"}]`, nil)
+
+	domain, err := client.getDomainForZone(context.TODO(), "shop.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "domain of exact match", 2, domain.ID)
+
+	domain, err = client.getDomainForZone(context.TODO(), "www.eu.shop.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "domain of longest match", 3, domain.ID)
+}
+
+func Test_GetDomainForZone_UsesManagedZoneOverrideWithoutListingDomains(t *testing.T) {
+	requestedUrls := make([]string, 0)
+	client := &Client{ManagedZoneOverride: &IkDomain{ID: 42, Name: "example.com"}, HttpClient: newHttpTestClient(func(req *http.Request) *http.Response {
+		requestedUrls = append(requestedUrls, req.URL.String())
+		return anIdResponse("1")
+	})}
+
+	domain, err := client.getDomainForZone(context.TODO(), "sub.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "domain ID", 42, domain.ID)
+	assertEqualsInt(t, "number of requests", 0, len(requestedUrls))
+
+	_, err = client.getDomainForZone(context.TODO(), "example.org")
+	var notFoundErr *ZoneNotFoundError
+	if !errors.As(err, &notFoundErr) {
+		t.Fatalf("Expected ZoneNotFoundError, got %v", err)
+	}
+}
+
+func Test_GetDomainForZone_CachesZoneNotFound(t *testing.T) {
+	domains := `[{"id":1,"customer_name":"example.com"}]`
+	requests := 0
+	client := &Client{HttpClient: newHttpTestClient(func(req *http.Request) *http.Response {
+		requests++
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(fmt.Sprintf(`{"result":"success", "data":%s}`, domains))),
+			Header:     make(http.Header),
+		}
+	})}
+
+	for i := 0; i < 3; i++ {
+		_, err := client.getDomainForZone(context.TODO(), "example.org")
+		var notFoundErr *ZoneNotFoundError
+		if !errors.As(err, &notFoundErr) {
+			t.Fatalf("Expected ZoneNotFoundError, got %v", err)
+		}
+		if notFoundErr.CachedUntil.IsZero() {
+			t.Fatal("Expected time until which the result is cached")
+		}
+	}
+	assertEqualsInt(t, "number of requests", 1, requests)
+
+	domains = `[{"id":1,"customer_name":"example.com"},{"id":2,"customer_name":"example.org"}]`
+	client.notFoundZones["example.org"] = time.Now().Add(-time.Second)
+	domain, err := client.getDomainForZone(context.TODO(), "example.org")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "domain ID", 2, domain.ID)
+	assertEqualsInt(t, "number of requests", 2, requests)
+}
+
+func Test_GetDomainForZone_ReloadsCachedDomainsIfZoneNotFound(t *testing.T) {
+	client := newTestClient(`[{"id":2,"customer_name":"example.org"}]`, &[]IkDomain{{ID: 1, Name: "example.com"}})
+	domain, err := client.getDomainForZone(context.TODO(), "example.org")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "domain ID", 2, domain.ID)
+}
+
+// newPaginatedDomainsTestClient returns a client whose account has the domains of the given pages, the requested pages are recorded
+func newPaginatedDomainsTestClient(pages []string, requestedPages *[]string) *Client {
+	return &Client{HttpClient: newHttpTestClient(func(req *http.Request) *http.Response {
+		page := req.URL.Query().Get("page")
+		if page == "" {
+			page = "1"
+		}
+		*requestedPages = append(*requestedPages, page)
+		index, _ := strconv.Atoi(page)
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(fmt.Sprintf(`{"result":"success","data":%s,"page":%d,"pages":%d}`, pages[index-1], index, len(pages)))),
+			Header:     make(http.Header),
+		}
+	})}
+}
+
+func Test_GetDomainForZone_LoadsAllPagesOfDomains(t *testing.T) {
+	requestedPages := make([]string, 0)
+	client := newPaginatedDomainsTestClient([]string{`[{"id":1,"customer_name":"example.com"}]`, `[{"id":2,"customer_name":"shop.example.com"}]`, `[{"id":3,"customer_name":"example.org"}]`}, &requestedPages)
+
+	domain, err := client.getDomainForZone(context.TODO(), "www.shop.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "domain ID", 2, domain.ID)
+	assertEquals(t, "requested pages", "1,2,3", strings.Join(requestedPages, ","))
+}
+
+func Test_GetDomainForZone_StopsLoadingDomainsAtExactMatch(t *testing.T) {
+	requestedPages := make([]string, 0)
+	client := newPaginatedDomainsTestClient([]string{`[{"id":1,"customer_name":"example.com"}]`, `[{"id":2,"customer_name":"shop.example.com"}]`, `[{"id":3,"customer_name":"example.org"}]`}, &requestedPages)
+
+	domain, err := client.getDomainForZone(context.TODO(), "shop.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "domain ID", 2, domain.ID)
+	assertEquals(t, "requested pages", "1,2", strings.Join(requestedPages, ","))
+
+	domain, err = client.getDomainForZone(context.TODO(), "example.org")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "domain ID of zone on later page", 3, domain.ID)
+}
+
 func Test_GetDnsRecordsForZone_OnlyReturnsRecordsForSpecifiedZone(t *testing.T) {
 	domainName := "example.com"
 	zone := "subzone." + domainName
@@ -132,3 +276,367 @@ func Test_CreateOrUpdateRecord_CreatesNewRecord(t *testing.T) {
 		t.Fatalf("Expected ID to be %s, got %s", id, rec.ID)
 	}
 }
+
+func Test_PatchRecord_SendsOnlyChangedAttributes(t *testing.T) {
+	httpClient := newHttpTestClient(func(req *http.Request) *http.Response {
+		if req.Method != http.MethodPatch {
+			t.Fatalf("Expected http method %s, got %s", http.MethodPatch, req.Method)
+		}
+		assertEquals(t, "path", "/1/domain/100/dns/record/984", req.URL.Path)
+		body, _ := ioutil.ReadAll(req.Body)
+		assertEquals(t, "body", `{"ttl":600}`, string(body))
+		return anIdResponse("984")
+	})
+
+	ttl := uint(600)
+	client := Client{domains: &[]IkDomain{{Name: "example.com", ID: 100}}, HttpClient: httpClient}
+	err := client.PatchRecord(context.TODO(), "example.com", "984", IkRecordPatch{TtlInSec: &ttl})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_DoRequest_SendsExtraHeadersAndCallsRequestHook(t *testing.T) {
+	hookCalled := false
+	httpClient := newHttpTestClient(func(req *http.Request) *http.Response {
+		if req.Header.Get("CF-Access-Client-Id") != "client-id" {
+			t.Fatalf("Expected extra header to be sent")
+		}
+		if req.Header.Get("Authorization") != "Bearer token" {
+			t.Fatalf("Expected authorization header to be sent")
+		}
+		return anIdResponse("1")
+	})
+
+	client := Client{
+		Token:        "token",
+		HttpClient:   httpClient,
+		ExtraHeaders: map[string]string{"CF-Access-Client-Id": "client-id"},
+		RequestHook: func(req *http.Request) error {
+			hookCalled = true
+			return nil
+		},
+	}
+	req, _ := http.NewRequest(http.MethodGet, apiBaseUrl, nil)
+	_, err := client.doRequest(req, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hookCalled {
+		t.Fatalf("Expected request hook to be called")
+	}
+}
+
+func Test_DoRequest_DoesNotSendRequestIfHookFails(t *testing.T) {
+	httpClient := newHttpTestClient(func(req *http.Request) *http.Response {
+		t.Fatalf("Expected request not to be sent")
+		return nil
+	})
+
+	client := Client{
+		HttpClient:  httpClient,
+		RequestHook: func(req *http.Request) error { return errors.New("signing failed") },
+	}
+	req, _ := http.NewRequest(http.MethodGet, apiBaseUrl, nil)
+	_, err := client.doRequest(req, nil)
+	if err == nil {
+		t.Fatalf("Expected error of request hook")
+	}
+}
+
+func Test_DoRequest_DecodesGzipEncodedResponse(t *testing.T) {
+	httpClient := newHttpTestClient(func(req *http.Request) *http.Response {
+		if req.Header.Get("Accept-Encoding") != "gzip" {
+			t.Fatalf("Expected gzip to be accepted")
+		}
+		var compressed bytes.Buffer
+		writer := gzip.NewWriter(&compressed)
+		writer.Write([]byte(`{"result":"success", "data":"1893"}`))
+		writer.Close()
+
+		header := make(http.Header)
+		header.Set("Content-Encoding", "gzip")
+		return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(&compressed), Header: header}
+	})
+
+	client := Client{HttpClient: httpClient}
+	req, _ := http.NewRequest(http.MethodGet, apiBaseUrl, nil)
+	var id string
+	_, err := client.doRequest(req, &id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "ID", "1893", id)
+}
+
+func Test_GetDnsRecordsForZone_ReturnsApexRecordsWithoutSourceIdn(t *testing.T) {
+	client := newTestClient(`[ { "id":"1", "source":".", "type":"A" }, { "id":"2", "source":"www", "type":"A" } ]`, &[]IkDomain{{Name: "example.com", ID: 100}})
+
+	recs, err := client.GetDnsRecordsForZone(context.TODO(), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 2 {
+		t.Fatalf("Expected %d records, got %d", 2, len(recs))
+	}
+	assertEquals(t, "SourceIdn", "example.com", recs[0].SourceIdn)
+	assertEquals(t, "SourceIdn", "www.example.com", recs[1].SourceIdn)
+}
+
+func Test_CreateOrUpdateRecord_SendsDotAsSourceOfApexRecord(t *testing.T) {
+	httpClient := newHttpTestClient(func(req *http.Request) *http.Response {
+		var sentRec IkRecord
+		json.NewDecoder(req.Body).Decode(&sentRec)
+		assertEquals(t, "Source", ".", sentRec.Source)
+		return anIdResponse("1")
+	})
+
+	client := Client{domains: &[]IkDomain{{Name: "example.com", ID: 100}}, HttpClient: httpClient}
+	_, err := client.CreateOrUpdateRecord(context.TODO(), "example.com", IkRecord{SourceIdn: "example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_DoRequest_PassesResponseDetailsToResponseHook(t *testing.T) {
+	body := `{"result":"success", "data":"1"}`
+	httpClient := newHttpTestClient(func(req *http.Request) *http.Response {
+		header := make(http.Header)
+		header.Set("X-RateLimit-Remaining", "59")
+		return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewBufferString(body)), Header: header}
+	})
+
+	var hookResp *IkResponse
+	client := Client{HttpClient: httpClient, ResponseHook: func(resp *IkResponse) { hookResp = resp }}
+	req, _ := http.NewRequest(http.MethodGet, apiBaseUrl, nil)
+	_, err := client.doRequest(req, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "StatusCode", 200, hookResp.StatusCode)
+	assertEquals(t, "X-RateLimit-Remaining", "59", hookResp.Header.Get("X-RateLimit-Remaining"))
+	assertEquals(t, "RawBody", body, string(hookResp.RawBody))
+}
+
+func Test_DoRequest_ReturnsApiErrorWithStatusCode(t *testing.T) {
+	httpClient := newHttpTestClient(func(req *http.Request) *http.Response {
+		return &http.Response{
+			StatusCode: 404,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"result":"error", "error":{"code":"not_found"}}`)),
+			Header:     make(http.Header),
+		}
+	})
+
+	client := Client{HttpClient: httpClient}
+	req, _ := http.NewRequest(http.MethodGet, apiBaseUrl, nil)
+	_, err := client.doRequest(req, nil)
+
+	var apiErr *ApiError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected ApiError, got %#v", err)
+	}
+	assertEqualsInt(t, "StatusCode", 404, apiErr.StatusCode)
+}
+
+func Test_GetDnsRecordsForZone_ReturnsZoneGoneErrorIfDomainWasDeleted(t *testing.T) {
+	domains := `[{"id":100,"customer_name":"example.com"}]`
+	httpClient := newHttpTestClient(func(req *http.Request) *http.Response {
+		if strings.HasPrefix(req.URL.Path, "/1/product") {
+			return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewBufferString(`{"result":"success","data":` + domains + `}`)), Header: make(http.Header)}
+		}
+		return &http.Response{StatusCode: 404, Body: ioutil.NopCloser(bytes.NewBufferString(`{"result":"error","error":{"code":"not_found"}}`)), Header: make(http.Header)}
+	})
+	client := Client{HttpClient: httpClient, domains: &[]IkDomain{{ID: 100, Name: "example.com"}}}
+
+	_, err := client.GetDnsRecordsForZone(context.TODO(), "example.com")
+	if errors.Is(err, ErrZoneGone) {
+		t.Fatalf("Expected not found error while domain exists, got %v", err)
+	}
+
+	domains = `[]`
+	client.zoneGoneCheckedAt = time.Time{}
+	_, err = client.GetDnsRecordsForZone(context.TODO(), "example.com")
+	var goneErr *ZoneGoneError
+	if !errors.Is(err, ErrZoneGone) || !errors.As(err, &goneErr) {
+		t.Fatalf("Expected ZoneGoneError, got %#v", err)
+	}
+	assertEquals(t, "domain", "example.com", goneErr.Domain)
+	assertEqualsInt(t, "cached domains", 0, len(*client.domains))
+}
+
+func Test_DeleteRecord_DoesNotReloadDomainsIfRecordIsMissing(t *testing.T) {
+	domainRequests := 0
+	httpClient := newHttpTestClient(func(req *http.Request) *http.Response {
+		if strings.HasPrefix(req.URL.Path, "/1/product") {
+			domainRequests++
+		}
+		return &http.Response{StatusCode: 404, Body: ioutil.NopCloser(bytes.NewBufferString(`{"result":"error","error":{"code":"not_found"}}`)), Header: make(http.Header)}
+	})
+	client := Client{HttpClient: httpClient, domains: &[]IkDomain{{ID: 100, Name: "example.com"}}}
+
+	err := client.DeleteRecord(context.TODO(), "example.com", "1")
+	if !isRecordNotFound(err) {
+		t.Fatalf("Expected record not found error, got %v", err)
+	}
+	assertEqualsInt(t, "domain requests", 0, domainRequests)
+}
+
+func Test_GetDnsRecordsForZone_ReturnsDecodableRecordsTogetherWithMappingError(t *testing.T) {
+	domains := []IkDomain{{ID: 1, Name: "example.com"}}
+	client := newTestClient(`[{"id":1,"type":"A","source_idn":"www.example.com","ttl":300},{"id":2,"type":"A","source_idn":"ftp.example.com","ttl":"invalid"}]`, &domains)
+
+	records, err := client.GetDnsRecordsForZone(context.TODO(), "example.com")
+	var mappingErr *RecordMappingError
+	if !errors.As(err, &mappingErr) {
+		t.Fatalf("Expected *RecordMappingError, got %v", err)
+	}
+	assertEqualsInt(t, "errors", 1, len(mappingErr.Errors))
+	assertEqualsInt(t, "records", 1, len(records))
+	assertEquals(t, "ID", "1", records[0].ID)
+}
+
+func Test_GetDomainForZone_CallsZoneResolvedHookOnceForSubzone(t *testing.T) {
+	domains := []IkDomain{{ID: 1, Name: "example.com"}}
+	client := newTestClient(`[]`, &domains)
+	resolutions := make([]ZoneResolution, 0)
+	client.ZoneResolvedHook = func(resolution ZoneResolution) {
+		resolutions = append(resolutions, resolution)
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err := client.getDomainForZone(context.TODO(), "sub.example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := client.getDomainForZone(context.TODO(), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertEqualsInt(t, "resolutions", 1, len(resolutions))
+	assertEquals(t, "Zone", "sub.example.com", resolutions[0].Zone)
+	assertEquals(t, "Domain", "example.com", resolutions[0].Domain.Name)
+}
+
+func Test_GetDomainForZone_CallsZoneResolvedHookWithoutHoldingLock(t *testing.T) {
+	domains := []IkDomain{{ID: 1, Name: "example.com"}}
+	client := newTestClient(`[]`, &domains)
+	client.ZoneResolvedHook = func(resolution ZoneResolution) {
+		// calls the client again from within the hook, which would deadlock if the hook was called while holding the lock
+		if _, err := client.getDomainForZone(context.TODO(), resolution.Domain.Name); err != nil {
+			t.Error(err)
+		}
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.getDomainForZone(context.TODO(), "sub.example.com")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected hook to be called without holding the client's lock")
+	}
+}
+
+func Test_GetDnsRecordsForZone_SkipsDescriptionsIfRequested(t *testing.T) {
+	var queries []string
+	httpClient := newHttpTestClient(func(req *http.Request) *http.Response {
+		queries = append(queries, req.URL.RawQuery)
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"result":"success","data":[{"id":1,"type":"MX","source_idn":"example.com","ttl":300,"description":{"priority":{"value":5}}}]}`)),
+			Header:     make(http.Header),
+		}
+	})
+	client := Client{domains: &[]IkDomain{{Name: "example.com", ID: 100}}, HttpClient: httpClient}
+
+	recs, err := client.GetDnsRecordsForZone(context.TODO(), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "query", "with=records_description", queries[0])
+	assertEqualsInt(t, "priority", 5, int(recs[0].Priority))
+
+	recs, err = client.GetDnsRecordsForZone(WithoutRecordDescriptions(context.TODO()), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "query", "", queries[1])
+	assertEqualsInt(t, "priority", 0, int(recs[0].Priority))
+	assertEqualsInt(t, "description attributes", 0, len(recs[0].DescriptionRaw))
+}
+
+// newLargeZoneTestClient returns a client whose API returns a zone with the given number of records
+func newLargeZoneTestClient(count int) *Client {
+	var payload bytes.Buffer
+	payload.WriteString(`{"result":"success","data":[`)
+	for i := 0; i < count; i++ {
+		if i > 0 {
+			payload.WriteString(",")
+		}
+		fmt.Fprintf(&payload, `{"id":%d,"type":"TXT","source":"rec%d","source_idn":"rec%d.example.com","target":"\"value %d\"","ttl":300,"updated_at":1700000000}`, i, i, i, i)
+	}
+	payload.WriteString(`]}`)
+	body := payload.Bytes()
+
+	httpClient := newHttpTestClient(func(req *http.Request) *http.Response {
+		return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewReader(body)), Header: make(http.Header)}
+	})
+	return &Client{HttpClient: httpClient, domains: &[]IkDomain{{Name: "example.com", ID: 100}}, SkipRecordDescriptions: true}
+}
+
+func Benchmark_GetDnsRecordsForZone_5000Records(b *testing.B) {
+	client := newLargeZoneTestClient(5000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		recs, err := client.GetDnsRecordsForZone(context.TODO(), "example.com")
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(recs) != 5000 {
+			b.Fatalf("Expected 5000 records, got %d", len(recs))
+		}
+	}
+}
+
+func Test_SetRecords_OverwritesDuplicateRecordsOnLaterPages(t *testing.T) {
+	pages := []string{
+		`[{"id":1,"type":"A","source_idn":"www.example.com","target":"192.0.2.1","ttl":300}]`,
+		`[{"id":2,"type":"A","source_idn":"www.example.com","target":"192.0.2.9","ttl":300}]`,
+	}
+	updatedIds := make([]string, 0)
+	httpClient := newHttpTestClient(func(req *http.Request) *http.Response {
+		if req.Method == http.MethodGet {
+			page := 1
+			if req.URL.Query().Get("page") == "2" {
+				page = 2
+			}
+			body := fmt.Sprintf(`{"result":"success","data":%s,"page":%d,"pages":2}`, pages[page-1], page)
+			return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewBufferString(body)), Header: make(http.Header)}
+		}
+		updatedIds = append(updatedIds, req.URL.Path[strings.LastIndex(req.URL.Path, "/")+1:])
+		return anIdResponse("")
+	})
+	client := Client{HttpClient: httpClient, domains: &[]IkDomain{{ID: 100, Name: "example.com"}}}
+	provider := NewProviderWithClient(&client)
+
+	records, err := provider.GetRecords(context.TODO(), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "records of all pages", 2, len(records))
+
+	_, err = provider.SetRecords(context.TODO(), "example.com", []libdns.Record{{Type: "A", Name: "www", Value: "192.0.2.2", TTL: 300}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "updated records", 2, len(updatedIds))
+	assertEquals(t, "updated record of second page", "2", updatedIds[1])
+}
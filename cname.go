@@ -0,0 +1,143 @@
+package infomaniak
+
+import (
+	"context"
+	"errors"
+
+	"github.com/libdns/libdns"
+)
+
+// Maximum number of CNAME records followed for a single record, longer chains are reported as loops
+const maxCnameChainLength = 8
+
+// CnameResolution the result of following the chain of a CNAME record
+type CnameResolution struct {
+	// Record the chain starts at
+	Record libdns.Record
+
+	// Chain of fully qualified names the record resolves through, starting with the record's target
+	Chain []string
+
+	// Addresses of the A and AAAA records of the last name of the chain
+	Addresses []string
+
+	// External is set if the chain leaves the zones managed by the account, its addresses are unknown then
+	External bool
+
+	// Loop is set if the chain points back to one of its names or is longer than 8 names
+	Loop bool
+}
+
+// ResolveCnames follows the chains of the CNAME records among the given records of the zone, e.g. as returned by GetRecords,
+// through the records of all zones managed by the account and returns the addresses each chain ends at. It is meant for
+// diagnostics, as every zone the chains pass through is loaded from the API unless the record cache is enabled.
+func (p *Provider) ResolveCnames(ctx context.Context, zone string, records []libdns.Record) ([]CnameResolution, error) {
+	zone = getWithoutTrailingDot(zone)
+	resolver := cnameResolver{provider: p, zone: zone, loaded: make(map[string][]libdns.Record)}
+	resolutions := make([]CnameResolution, 0)
+	for _, rec := range records {
+		if RecordType(normalizeType(rec.Type)) != TypeCNAME {
+			continue
+		}
+		resolution, err := resolver.resolve(ctx, rec)
+		if err != nil {
+			return nil, err
+		}
+		resolutions = append(resolutions, resolution)
+	}
+	return resolutions, nil
+}
+
+// resolveZone returns the name of the domain whose records are managed for the zone if the client can resolve zones,
+// otherwise the zone itself
+func (p *Provider) resolveZone(ctx context.Context, zone string) (string, error) {
+	if _, err := p.getClient(); err != nil {
+		return "", err
+	}
+	p.mu.Lock()
+	client := p.client
+	p.mu.Unlock()
+	if resolver, ok := client.(zoneResolver); ok {
+		return resolver.resolveZone(ctx, zone)
+	}
+	return zone, nil
+}
+
+// cnameResolver follows CNAME chains and loads every zone at most once, names outside of the resolver's zone are resolved
+// to the domain that manages them if the client can resolve zones
+type cnameResolver struct {
+	provider *Provider
+	zone     string
+	loaded   map[string][]libdns.Record
+}
+
+// resolve follows the chain of the CNAME record
+func (r *cnameResolver) resolve(ctx context.Context, rec libdns.Record) (CnameResolution, error) {
+	resolution := CnameResolution{Record: rec, Chain: make([]string, 0), Addresses: make([]string, 0)}
+	visited := map[string]bool{normalizeName(toAbsoluteName(rec.Name, r.zone)): true}
+	target := normalizeName(rec.Value)
+	for {
+		if visited[target] || len(resolution.Chain) >= maxCnameChainLength {
+			resolution.Loop = true
+			return resolution, nil
+		}
+		visited[target] = true
+		resolution.Chain = append(resolution.Chain, target)
+
+		recs, err := r.recordsAt(ctx, target)
+		var notFoundErr *ZoneNotFoundError
+		if errors.As(err, &notFoundErr) {
+			resolution.External = true
+			return resolution, nil
+		}
+		if err != nil {
+			return resolution, err
+		}
+
+		next := ""
+		for _, targetRec := range recs {
+			switch RecordType(normalizeType(targetRec.Type)) {
+			case TypeCNAME:
+				next = normalizeName(targetRec.Value)
+			case TypeA, TypeAAAA:
+				resolution.Addresses = append(resolution.Addresses, targetRec.Value)
+			}
+		}
+		if next == "" {
+			return resolution, nil
+		}
+		target = next
+	}
+}
+
+// recordsAt returns the records of the fully qualified name, names outside of the resolver's zone are
+// looked up in the domain of the account that manages them
+func (r *cnameResolver) recordsAt(ctx context.Context, fqdn string) ([]libdns.Record, error) {
+	zone := r.zone
+	if !isInZone(fqdn, r.zone) {
+		var err error
+		zone, err = r.provider.resolveZone(ctx, fqdn)
+		if err != nil {
+			return nil, err
+		}
+	}
+	recs, ok := r.loaded[zone]
+	if !ok {
+		var err error
+		recs, err = r.provider.getRecords(ctx, zone)
+		var mappingErr *RecordMappingError
+		if err != nil && !errors.As(err, &mappingErr) {
+			return nil, err
+		}
+		r.loaded[zone] = recs
+	}
+
+	name := normalizeName(toRelativeName(fqdn, zone))
+	recsAtName := make([]libdns.Record, 0)
+	for _, rec := range recs {
+		if normalizeName(rec.Name) == name {
+			recsAtName = append(recsAtName, rec)
+		}
+	}
+	return recsAtName, nil
+}
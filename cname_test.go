@@ -0,0 +1,83 @@
+package infomaniak
+
+import (
+	"context"
+	"testing"
+)
+
+func Test_ResolveCnames_FollowsChainsThroughZonesOfAccount(t *testing.T) {
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			switch zone {
+			case "example.com":
+				return []IkRecord{
+					{ID: "1", Type: "CNAME", SourceIdn: "www.example.com", Target: "web.example.com."},
+					{ID: "2", Type: "CNAME", SourceIdn: "web.example.com", Target: "lb.example.net."},
+					{ID: "3", Type: "CNAME", SourceIdn: "cdn.example.com", Target: "cdn.provider.test."},
+					{ID: "4", Type: "CNAME", SourceIdn: "loop.example.com", Target: "loop.example.com."},
+				}, nil
+			case "lb.example.net":
+				return []IkRecord{{ID: "5", Type: "A", SourceIdn: "lb.example.net", Target: "192.0.2.1"}}, nil
+			}
+			return nil, &ZoneNotFoundError{Zone: zone}
+		},
+	}
+	provider := Provider{client: &client}
+	records, err := provider.GetRecords(context.TODO(), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resolutions, err := provider.ResolveCnames(context.TODO(), "example.com", records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := make(map[string]CnameResolution)
+	for _, resolution := range resolutions {
+		byName[resolution.Record.Name] = resolution
+	}
+	assertEqualsInt(t, "resolutions", 4, len(resolutions))
+	assertEqualsInt(t, "chain of www", 2, len(byName["www"].Chain))
+	assertEquals(t, "address of www", "192.0.2.1", byName["www"].Addresses[0])
+	if !byName["cdn"].External {
+		t.Fatalf("Expected chain of cdn to be external")
+	}
+	if !byName["loop"].Loop {
+		t.Fatalf("Expected chain of loop to be a loop")
+	}
+}
+
+func Test_ResolveCnames_LoadsEveryDomainOnlyOnce(t *testing.T) {
+	client, err := NewFakeClient(Fixture{
+		Domains: []IkDomain{{ID: 1, Name: "example.com"}, {ID: 2, Name: "example.net"}},
+		Records: []IkRecord{
+			{ID: "1", Type: "CNAME", SourceIdn: "www.example.com", Target: "a.example.net."},
+			{ID: "2", Type: "CNAME", SourceIdn: "shop.example.com", Target: "b.example.net."},
+			{ID: "3", Type: "A", SourceIdn: "a.example.net", Target: "192.0.2.1"},
+			{ID: "4", Type: "A", SourceIdn: "b.example.net", Target: "192.0.2.2"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := make([]string, 0)
+	counting := func(next IkClient) IkClient {
+		return &namingClient{IkClient: next, name: "list", calls: &calls}
+	}
+	provider := Provider{client: client, ClientMiddlewares: []ClientMiddleware{counting}}
+	records, err := provider.GetRecords(context.TODO(), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resolutions, err := provider.ResolveCnames(context.TODO(), "example.com", records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "resolutions", 2, len(resolutions))
+	for _, resolution := range resolutions {
+		assertEqualsInt(t, "addresses of "+resolution.Record.Name, 1, len(resolution.Addresses))
+	}
+	// records of example.com are listed by GetRecords, those of example.net only once for both chains
+	assertEqualsInt(t, "API calls to list records", 2, len(calls))
+}
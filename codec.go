@@ -0,0 +1,44 @@
+package infomaniak
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// Codec encodes the bodies of API requests and decodes the bodies of API responses, e.g. to plug in a faster JSON
+// implementation or to inject decoding failures in tests. Values passed to the codec may contain json.RawMessage fields
+// and implement json.Marshaler or json.Unmarshaler, e.g. listed records are decoded by an unmarshaler - the attributes
+// of single records are always decoded with encoding/json.
+type Codec interface {
+	// Marshal returns the JSON encoding of v
+	Marshal(v interface{}) ([]byte, error)
+
+	// Decode reads the next JSON value from r and stores it in v
+	Decode(r io.Reader, v interface{}) error
+}
+
+// StdCodec codec based on encoding/json, used if no codec is configured
+type StdCodec struct{}
+
+// Marshal returns the JSON encoding of v
+func (StdCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Decode decodes the JSON value read from r into v without buffering the whole body
+func (StdCodec) Decode(r io.Reader, v interface{}) error {
+	return json.NewDecoder(r).Decode(v)
+}
+
+// getCodec returns the configured codec or StdCodec if none is set
+func (c *Client) getCodec() Codec {
+	if c.Codec == nil {
+		return StdCodec{}
+	}
+	return c.Codec
+}
+
+// Interface guards
+var (
+	_ Codec = StdCodec{}
+)
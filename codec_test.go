@@ -0,0 +1,39 @@
+package infomaniak
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+)
+
+// failingCodec codec whose decoding always fails and that counts the encoded values
+type failingCodec struct {
+	StdCodec
+	marshaled int
+}
+
+// Marshal counts the value and encodes it with encoding/json
+func (c *failingCodec) Marshal(v interface{}) ([]byte, error) {
+	c.marshaled++
+	return c.StdCodec.Marshal(v)
+}
+
+// Decode always fails
+func (c *failingCodec) Decode(r io.Reader, v interface{}) error {
+	return errors.New("injected decoding failure")
+}
+
+func Test_Client_UsesConfiguredCodec(t *testing.T) {
+	codec := &failingCodec{}
+	client := Client{Codec: codec, domains: &[]IkDomain{{Name: "example.com", ID: 100}}, HttpClient: newHttpTestClient(func(req *http.Request) *http.Response {
+		return anIdResponse("1")
+	})}
+
+	_, err := client.CreateOrUpdateRecord(context.TODO(), "example.com", IkRecord{Type: "A", Target: "127.0.0.1"})
+	if err == nil || err.Error() != "injected decoding failure" {
+		t.Fatalf("Expected injected decoding failure, got %v", err)
+	}
+	assertEqualsInt(t, "marshaled values", 1, codec.marshaled)
+}
@@ -0,0 +1,146 @@
+package infomaniak
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+// newConcurrentTestProvider returns a provider whose client calls a fake API that is safe for concurrent use
+func newConcurrentTestProvider() *Provider {
+	var nextId int
+	var mu sync.Mutex
+	httpClient := newHttpTestClient(func(req *http.Request) *http.Response {
+		mu.Lock()
+		defer mu.Unlock()
+		data := `[]`
+		switch {
+		case strings.Contains(req.URL.Path, "/1/product"):
+			data = `[{"id":1,"customer_name":"example.com"},{"id":2,"customer_name":"example.org"}]`
+		case req.Method == http.MethodPost:
+			nextId++
+			data = fmt.Sprintf(`"%d"`, nextId)
+		case req.Method == http.MethodPut:
+			data = `true`
+		}
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(fmt.Sprintf(`{"result":"success", "data":%s}`, data))),
+			Header:     make(http.Header),
+		}
+	})
+	return &Provider{client: &Client{HttpClient: httpClient}, RecordCacheTtl: time.Minute}
+}
+
+func Test_Provider_IsSafeForConcurrentUseAcrossZones(t *testing.T) {
+	provider := newConcurrentTestProvider()
+	zones := []string{"example.com.", "example.org.", "sub.example.com."}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(zones)*40)
+	for i := 0; i < 20; i++ {
+		for _, zone := range zones {
+			wg.Add(2)
+			go func(zone string, i int) {
+				defer wg.Done()
+				_, err := provider.GetRecords(context.TODO(), zone)
+				errs <- err
+			}(zone, i)
+			go func(zone string, i int) {
+				defer wg.Done()
+				_, err := provider.SetRecords(context.TODO(), zone, []libdns.Record{{Name: fmt.Sprintf("host%d", i), Type: "A", Value: "127.0.0.1"}})
+				errs <- err
+			}(zone, i)
+		}
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func Test_Provider_InitializesClientOnceForConcurrentCalls(t *testing.T) {
+	provider := Provider{APIToken: "token"}
+
+	var wg sync.WaitGroup
+	clients := make([]IkClient, 10)
+	for i := range clients {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			clients[i], _ = provider.getClient()
+		}(i)
+	}
+	wg.Wait()
+
+	for _, client := range clients {
+		if client != clients[0] {
+			t.Fatalf("Expected all calls to share the same client")
+		}
+	}
+}
+
+func Test_Provider_RebuildsClientIfOptionsChanged(t *testing.T) {
+	provider := Provider{APIToken: "token"}
+	first, err := provider.getClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	same, _ := provider.getClient()
+	if same != first {
+		t.Fatalf("Expected client to be reused while options are unchanged")
+	}
+
+	provider.APIToken = "new-token"
+	rebuilt, _ := provider.getClient()
+	if rebuilt == first {
+		t.Fatalf("Expected client to be rebuilt after the token changed")
+	}
+	assertEquals(t, "token", "new-token", provider.client.(*Client).Token)
+}
+
+func Test_Provider_KeepsGivenClientIfOptionsChanged(t *testing.T) {
+	client := &TestClient{}
+	provider := NewProviderWithClient(client)
+	provider.APIToken = "token"
+
+	actual, _ := provider.getClient()
+	if actual != client {
+		t.Fatalf("Expected given client to be kept")
+	}
+}
+
+func Test_Provider_RebuildsClientOnceForConcurrentCallsAfterOptionsChanged(t *testing.T) {
+	provider := Provider{APIToken: "token"}
+	provider.getClient()
+	provider.APIToken = "new-token"
+
+	var wg sync.WaitGroup
+	clients := make([]IkClient, 10)
+	for i := range clients {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			clients[i], _ = provider.getClient()
+		}(i)
+	}
+	wg.Wait()
+
+	for _, client := range clients {
+		if client != clients[0] {
+			t.Fatalf("Expected all calls to share the same rebuilt client")
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package infomaniak
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+// Precondition describes the state a RRset is expected to have before it is modified
+type Precondition struct {
+	// Name of the RRset relative to the zone
+	Name string
+
+	// Type of the RRset
+	Type string
+
+	// Values the RRset is expected to contain - if empty, the RRset is expected to not exist
+	Values []string
+}
+
+// SetRecordsIf sets the records in the zone as SetRecords does, but only if all RRsets match the
+// given preconditions, otherwise a *PreconditionFailedError is returned and nothing is changed.
+// As the infomaniak API offers no transactions, changes made by others between the
+// check and the write can only be prevented by configuring a Locker.
+func (p *Provider) SetRecordsIf(ctx context.Context, zone string, records []libdns.Record, preconditions []Precondition) ([]libdns.Record, error) {
+	zone = getWithoutTrailingDot(zone)
+	return p.withZoneLock(ctx, zone, func() ([]libdns.Record, error) {
+		return p.setRecordsIf(ctx, zone, records, preconditions)
+	})
+}
+
+// setRecordsIf sets the records if all preconditions are met without acquiring the zone's lock
+func (p *Provider) setRecordsIf(ctx context.Context, zone string, records []libdns.Record, preconditions []Precondition) ([]libdns.Record, error) {
+	existingRecs, err := p.getRecords(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+
+	model := newZoneModel(existingRecs)
+	for _, precondition := range preconditions {
+		actualValues := make([]string, 0)
+		for _, rec := range model.resolve(precondition.Name) {
+			if normalizeType(rec.Type) == normalizeType(precondition.Type) {
+				actualValues = append(actualValues, rec.Value)
+			}
+		}
+		if !equalValues(precondition.Values, actualValues) {
+			return nil, &PreconditionFailedError{Precondition: precondition, ActualValues: actualValues}
+		}
+	}
+	return p.setRecords(ctx, zone, records, nil)
+}
+
+// equalValues returns if both slices contain the same values regardless of their order
+func equalValues(expected []string, actual []string) bool {
+	if len(expected) != len(actual) {
+		return false
+	}
+	sortedExpected := append([]string(nil), expected...)
+	sortedActual := append([]string(nil), actual...)
+	sort.Strings(sortedExpected)
+	sort.Strings(sortedActual)
+	for i := range sortedExpected {
+		if sortedExpected[i] != sortedActual[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// getReplaceableBySet returns a function that decides which existing records SetRecords may overwrite if SetRecordsMinAge is set,
+// records changed within the minimum age are protected - nil is returned if all records may be overwritten
+func (p *Provider) getReplaceableBySet(ctx context.Context, zone string) (func(existingRec libdns.Record) bool, error) {
+	if p.SetRecordsMinAge <= 0 {
+		return nil, nil
+	}
+	ikRecords, err := p.getDnsRecordsForZone(ctx, zone)
+	var mappingErr *RecordMappingError
+	if err != nil && !errors.As(err, &mappingErr) {
+		return nil, err
+	}
+
+	threshold := time.Now().Add(-p.SetRecordsMinAge)
+	freshIds := make(map[string]bool)
+	for _, rec := range ikRecords {
+		if rec.UpdatedAt.After(threshold) {
+			freshIds[rec.ID] = true
+		}
+	}
+	return func(existingRec libdns.Record) bool {
+		return !freshIds[existingRec.ID]
+	}, nil
+}
+
+// filterRecords returns the records for which keep returns true
+func filterRecords(records []libdns.Record, keep func(rec libdns.Record) bool) []libdns.Record {
+	result := make([]libdns.Record, 0, len(records))
+	for _, rec := range records {
+		if keep(rec) {
+			result = append(result, rec)
+		}
+	}
+	return result
+}
@@ -0,0 +1,111 @@
+package infomaniak
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+func Test_SetRecordsIf_SetsRecordsIfPreconditionsAreMet(t *testing.T) {
+	methodCalled := false
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			return []IkRecord{{ID: "1", SourceIdn: "www.example.com", Type: "A", Target: "127.0.0.1"}}, nil
+		},
+		setter: func(ctx context.Context, zone string, record IkRecord) (*IkRecord, error) {
+			methodCalled = true
+			return &record, nil
+		},
+	}
+	provider := Provider{client: &client}
+	_, err := provider.SetRecordsIf(context.TODO(), "example.com",
+		[]libdns.Record{{Name: "www", Type: "A", Value: "127.0.0.2"}},
+		[]Precondition{{Name: "www", Type: "A", Values: []string{"127.0.0.1"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !methodCalled {
+		t.Fatalf("Expected record to be set but was not")
+	}
+}
+
+func Test_SetRecordsIf_ComparesTypeOfPreconditionCaseInsensitively(t *testing.T) {
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			return []IkRecord{{ID: "1", SourceIdn: "www.example.com", Type: "A", Target: "127.0.0.1"}}, nil
+		},
+		setter: func(ctx context.Context, zone string, record IkRecord) (*IkRecord, error) {
+			return &record, nil
+		},
+	}
+	provider := Provider{client: &client}
+	_, err := provider.SetRecordsIf(context.TODO(), "example.com",
+		[]libdns.Record{{Name: "www", Type: "A", Value: "127.0.0.2"}},
+		[]Precondition{{Name: "www", Type: "a", Values: []string{"127.0.0.1"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_SetRecordsIf_ReturnsErrorIfPreconditionIsNotMet(t *testing.T) {
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			return []IkRecord{{ID: "1", SourceIdn: "www.example.com", Type: "A", Target: "127.0.0.3"}}, nil
+		},
+		setter: func(ctx context.Context, zone string, record IkRecord) (*IkRecord, error) {
+			t.Fatalf("Expected that record is not set if precondition is not met")
+			return nil, nil
+		},
+	}
+	provider := Provider{client: &client}
+	_, err := provider.SetRecordsIf(context.TODO(), "example.com",
+		[]libdns.Record{{Name: "www", Type: "A", Value: "127.0.0.2"}},
+		[]Precondition{{Name: "www", Type: "A", Values: []string{"127.0.0.1"}}})
+
+	var preconditionErr *PreconditionFailedError
+	if !errors.As(err, &preconditionErr) {
+		t.Fatalf("Expected PreconditionFailedError, got %#v", err)
+	}
+}
+
+func Test_SetRecordsIf_ExpectsRRsetToNotExistIfNoValuesGiven(t *testing.T) {
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			return []IkRecord{{ID: "1", SourceIdn: "www.example.com", Type: "A", Target: "127.0.0.1"}}, nil
+		},
+	}
+	provider := Provider{client: &client}
+	_, err := provider.SetRecordsIf(context.TODO(), "example.com",
+		[]libdns.Record{{Name: "www", Type: "A", Value: "127.0.0.2"}},
+		[]Precondition{{Name: "www", Type: "A"}})
+	if err == nil {
+		t.Fatalf("Expected error because RRset already exists")
+	}
+}
+
+func Test_SetRecords_DoesNotOverwriteFreshRecordsIfMinAgeSet(t *testing.T) {
+	updatedIds := make([]string, 0)
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			return []IkRecord{
+				{ID: "1", Type: "TXT", SourceIdn: "_acme-challenge.example.com", Target: "old", UpdatedAt: time.Now().Add(-time.Hour)},
+				{ID: "2", Type: "TXT", SourceIdn: "_acme-challenge.example.com", Target: "fresh", UpdatedAt: time.Now()},
+			}, nil
+		},
+		setter: func(ctx context.Context, zone string, record IkRecord) (*IkRecord, error) {
+			updatedIds = append(updatedIds, record.ID)
+			return &record, nil
+		},
+	}
+	provider := Provider{client: &client, SetRecordsMinAge: time.Minute}
+
+	_, err := provider.SetRecords(context.TODO(), "example.com", []libdns.Record{{Type: "TXT", Name: "_acme-challenge", Value: "new"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "written records", 1, len(updatedIds))
+	assertEquals(t, "overwritten ID", "1", updatedIds[0])
+}
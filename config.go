@@ -0,0 +1,128 @@
+package infomaniak
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Placeholder written instead of the API token if RedactSecrets is set
+const redactedToken = "REDACTED"
+
+// configDuration duration that is written to JSON in the form "1m30s" as accepted by caddy,
+// nanoseconds are accepted as well when it is read
+type configDuration time.Duration
+
+// MarshalJSON writes the duration as a string
+func (d configDuration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON reads a duration string or a number of nanoseconds
+func (d *configDuration) UnmarshalJSON(data []byte) error {
+	var nanos int64
+	if err := json.Unmarshal(data, &nanos); err == nil {
+		*d = configDuration(nanos)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string or a number of nanoseconds: %s", data)
+	}
+	duration, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = configDuration(duration)
+	return nil
+}
+
+// configTtl TTL in seconds that is written to JSON as number of seconds, durations in the form "1h" are accepted
+// as well when it is read and converted to seconds
+type configTtl time.Duration
+
+// UnmarshalJSON reads a number of seconds or a duration string
+func (t *configTtl) UnmarshalJSON(data []byte) error {
+	var secs int64
+	if err := json.Unmarshal(data, &secs); err == nil {
+		*t = configTtl(secs)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("TTL must be a number of seconds or a duration string: %s", data)
+	}
+	duration, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*t = configTtl(duration / time.Second)
+	return nil
+}
+
+// providerAlias has the fields of Provider without its JSON methods
+type providerAlias Provider
+
+// providerConfig JSON representation of a Provider, its durations shadow the ones of the provider
+type providerConfig struct {
+	*providerAlias
+	APIToken              string         `json:"api_token,omitempty"`
+	ZoneNotFoundCacheTtl  configDuration `json:"zone_not_found_cache_ttl,omitempty"`
+	RecordCacheTtl        configDuration `json:"record_cache_ttl,omitempty"`
+	DegradedRetryInterval configDuration `json:"degraded_retry_interval,omitempty"`
+	DefaultTTL            configTtl      `json:"default_ttl,omitempty"`
+	SetRecordsMinAge      configDuration `json:"set_records_min_age,omitempty"`
+	SetRecordsBatchWindow configDuration `json:"set_records_batch_window,omitempty"`
+	DialTimeout           configDuration `json:"dial_timeout,omitempty"`
+	FallbackDelay         configDuration `json:"fallback_delay,omitempty"`
+	JournalMaxAge         configDuration `json:"journal_max_age,omitempty"`
+}
+
+// MarshalJSON writes the configuration of the provider with durations in the form "1m30s", so that
+// the output of caddy adapt can be read again - the API token is replaced if RedactSecrets is set
+func (p *Provider) MarshalJSON() ([]byte, error) {
+	config := p.config()
+	if p.RedactSecrets && config.APIToken != "" {
+		config.APIToken = redactedToken
+	}
+	return json.Marshal(config)
+}
+
+// config returns the JSON representation of the provider's current configuration
+func (p *Provider) config() providerConfig {
+	return providerConfig{
+		providerAlias:         (*providerAlias)(p),
+		APIToken:              p.APIToken,
+		ZoneNotFoundCacheTtl:  configDuration(p.ZoneNotFoundCacheTtl),
+		RecordCacheTtl:        configDuration(p.RecordCacheTtl),
+		DegradedRetryInterval: configDuration(p.DegradedRetryInterval),
+		DefaultTTL:            configTtl(p.DefaultTTL),
+		SetRecordsMinAge:      configDuration(p.SetRecordsMinAge),
+		SetRecordsBatchWindow: configDuration(p.SetRecordsBatchWindow),
+		DialTimeout:           configDuration(p.DialTimeout),
+		FallbackDelay:         configDuration(p.FallbackDelay),
+		JournalMaxAge:         configDuration(p.JournalMaxAge),
+	}
+}
+
+// UnmarshalJSON reads the configuration of the provider, durations may be strings in the form "1m30s" or numbers of nanoseconds,
+// the DefaultTTL may be a number of seconds or a duration string - a redacted API token is ignored
+func (p *Provider) UnmarshalJSON(data []byte) error {
+	config := p.config()
+	if err := json.Unmarshal(data, &config); err != nil {
+		return err
+	}
+	if config.APIToken != redactedToken {
+		p.APIToken = config.APIToken
+	}
+	p.ZoneNotFoundCacheTtl = time.Duration(config.ZoneNotFoundCacheTtl)
+	p.RecordCacheTtl = time.Duration(config.RecordCacheTtl)
+	p.DegradedRetryInterval = time.Duration(config.DegradedRetryInterval)
+	p.DefaultTTL = time.Duration(config.DefaultTTL)
+	p.SetRecordsMinAge = time.Duration(config.SetRecordsMinAge)
+	p.SetRecordsBatchWindow = time.Duration(config.SetRecordsBatchWindow)
+	p.DialTimeout = time.Duration(config.DialTimeout)
+	p.FallbackDelay = time.Duration(config.FallbackDelay)
+	p.JournalMaxAge = time.Duration(config.JournalMaxAge)
+	return nil
+}
@@ -0,0 +1,70 @@
+package infomaniak
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_MarshalJSON_RoundTripsConfiguration(t *testing.T) {
+	provider := &Provider{APIToken: "secret", RecordCacheTtl: 30 * time.Second, DefaultTTL: TTLOneHour, AutoTTL: AutoTTLInherit, MaxRecordsPerZone: 100, ManagedZoneOverride: &IkDomain{ID: 1, Name: "example.com"}}
+	rawJson, err := json.Marshal(provider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(rawJson), `"record_cache_ttl":"30s"`) {
+		t.Fatalf("Expected duration as string, got %s", rawJson)
+	}
+	if !strings.Contains(string(rawJson), `"default_ttl":3600`) {
+		t.Fatalf("Expected default TTL in seconds, got %s", rawJson)
+	}
+
+	var actual Provider
+	err = json.Unmarshal(rawJson, &actual)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "token", "secret", actual.APIToken)
+	assertEquals(t, "record cache ttl", "30s", actual.RecordCacheTtl.String())
+	assertEqualsInt(t, "default ttl", 3600, int(actual.DefaultTTL))
+	assertEquals(t, "auto ttl", string(AutoTTLInherit), string(actual.AutoTTL))
+	assertEqualsInt(t, "max records", 100, actual.MaxRecordsPerZone)
+	assertEqualsInt(t, "managed domain", 1, actual.ManagedZoneOverride.ID)
+}
+
+func Test_MarshalJSON_RedactsToken(t *testing.T) {
+	rawJson, err := json.Marshal(&Provider{APIToken: "secret", RedactSecrets: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(rawJson), `"secret"`) {
+		t.Fatalf("Expected token to be redacted, got %s", rawJson)
+	}
+
+	actual := Provider{APIToken: "from-env"}
+	err = json.Unmarshal(rawJson, &actual)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "token", "from-env", actual.APIToken)
+}
+
+func Test_UnmarshalJSON_AcceptsNanoseconds(t *testing.T) {
+	var provider Provider
+	err := json.Unmarshal([]byte(`{"record_cache_ttl":5000000000,"dial_timeout":"2s"}`), &provider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "record cache ttl", "5s", provider.RecordCacheTtl.String())
+	assertEquals(t, "dial timeout", "2s", provider.DialTimeout.String())
+}
+
+func Test_UnmarshalJSON_ReadsDefaultTtlAsDurationString(t *testing.T) {
+	var provider Provider
+	err := json.Unmarshal([]byte(`{"default_ttl":"15m"}`), &provider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "default ttl", int(TTLFifteenMinutes), int(provider.DefaultTTL))
+}
@@ -0,0 +1,32 @@
+package infomaniak
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/libdns/libdns"
+)
+
+// isConflict returns if the API responded that the record conflicts with an existing one, e.g. because an identical
+// record was created by an earlier attempt whose response got lost
+func isConflict(err error) bool {
+	var apiErr *ApiError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusConflict
+}
+
+// findIdenticalRecord looks up the existing record with the same name, type and value as the given record,
+// the records of the zone are loaded from the API as the record cache was invalidated by the failed write
+func (p *Provider) findIdenticalRecord(ctx context.Context, zone string, rec libdns.Record) (libdns.Record, bool, error) {
+	existingRecs, err := p.getRecords(ctx, zone)
+	var mappingErr *RecordMappingError
+	if err != nil && !errors.As(err, &mappingErr) {
+		return libdns.Record{}, false, err
+	}
+	for _, existingRec := range existingRecs {
+		if coordinatesOf(existingRec) == coordinatesOf(rec) && normalizeValue(existingRec.Value) == normalizeValue(rec.Value) {
+			return existingRec, true, nil
+		}
+	}
+	return libdns.Record{}, false, nil
+}
@@ -0,0 +1,49 @@
+package infomaniak
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/libdns/libdns"
+)
+
+func Test_AppendRecords_ReturnsIdenticalRecordOnConflict(t *testing.T) {
+	created := false
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			if !created {
+				return []IkRecord{}, nil
+			}
+			return []IkRecord{{ID: "42", Type: "TXT", SourceIdn: "_acme-challenge.example.com", Target: "token", TtlInSec: 300}}, nil
+		},
+		setter: func(ctx context.Context, zone string, record IkRecord) (*IkRecord, error) {
+			created = true
+			return nil, &ApiError{StatusCode: http.StatusConflict}
+		},
+	}
+	provider := Provider{client: &client}
+
+	recs, err := provider.AppendRecords(context.TODO(), "example.com", []libdns.Record{{Type: "TXT", Name: "_acme-challenge", Value: "token"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "number of records", 1, len(recs))
+	assertEquals(t, "ID", "42", recs[0].ID)
+}
+
+func Test_AppendRecords_FailsOnConflictWithoutIdenticalRecord(t *testing.T) {
+	client := TestClient{
+		setter: func(ctx context.Context, zone string, record IkRecord) (*IkRecord, error) {
+			return nil, &ApiError{StatusCode: http.StatusConflict}
+		},
+	}
+	provider := Provider{client: &client}
+
+	_, err := provider.AppendRecords(context.TODO(), "example.com", []libdns.Record{{Type: "TXT", Name: "_acme-challenge", Value: "token"}})
+	var apiErr *ApiError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusConflict {
+		t.Fatalf("Expected conflict error, got %v", err)
+	}
+}
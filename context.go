@@ -0,0 +1,62 @@
+package infomaniak
+
+import (
+	"context"
+	"time"
+)
+
+// contextKey type of the keys of context values used by this package
+type contextKey int
+
+const (
+	requestTokenKey contextKey = iota
+	requestTimeoutKey
+	dryRunKey
+	skipDescriptionsKey
+)
+
+// WithRequestToken returns a context that overrides the API token for all API calls made with it
+func WithRequestToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, requestTokenKey, token)
+}
+
+// WithRequestTimeout returns a context that limits the duration of each single API call made with it
+func WithRequestTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, requestTimeoutKey, timeout)
+}
+
+// WithDryRun returns a context for which API calls that would change records are not sent,
+// instead they are treated as if they were successful
+func WithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey, true)
+}
+
+// WithoutRecordDescriptions returns a context for which records are listed without their descriptions, e.g. to
+// speed up ACME challenges that only need TXT records
+func WithoutRecordDescriptions(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipDescriptionsKey, true)
+}
+
+// getRequestToken returns the API token set on the context if any
+func getRequestToken(ctx context.Context) (string, bool) {
+	token, ok := ctx.Value(requestTokenKey).(string)
+	return token, ok
+}
+
+// getRequestTimeout returns the timeout of single API calls set on the context if any
+func getRequestTimeout(ctx context.Context) (time.Duration, bool) {
+	timeout, ok := ctx.Value(requestTimeoutKey).(time.Duration)
+	return timeout, ok && timeout > 0
+}
+
+// isDryRun returns if API calls that would change records must not be sent for the context
+func isDryRun(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(dryRunKey).(bool)
+	return dryRun
+}
+
+// skipsRecordDescriptions returns if records are listed without their descriptions for the context
+func skipsRecordDescriptions(ctx context.Context) bool {
+	skip, _ := ctx.Value(skipDescriptionsKey).(bool)
+	return skip
+}
@@ -0,0 +1,78 @@
+package infomaniak
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func Test_DoRequest_UsesTokenOfContext(t *testing.T) {
+	httpClient := newHttpTestClient(func(req *http.Request) *http.Response {
+		assertEquals(t, "Authorization", "Bearer context-token", req.Header.Get("Authorization"))
+		return anIdResponse("1")
+	})
+
+	client := Client{Token: "client-token", HttpClient: httpClient}
+	req, _ := http.NewRequestWithContext(WithRequestToken(context.TODO(), "context-token"), http.MethodGet, apiBaseUrl, nil)
+	_, err := client.doRequest(req, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_DoRequest_AppliesTimeoutOfContext(t *testing.T) {
+	httpClient := newHttpTestClient(func(req *http.Request) *http.Response {
+		if _, ok := req.Context().Deadline(); !ok {
+			t.Fatalf("Expected request to have a deadline")
+		}
+		return anIdResponse("1")
+	})
+
+	client := Client{HttpClient: httpClient}
+	req, _ := http.NewRequestWithContext(WithRequestTimeout(context.TODO(), time.Second), http.MethodGet, apiBaseUrl, nil)
+	_, err := client.doRequest(req, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_CreateOrUpdateRecord_DoesNotSendRequestInDryRun(t *testing.T) {
+	httpClient := newHttpTestClient(func(req *http.Request) *http.Response {
+		t.Fatalf("Expected that no request is sent in dry run")
+		return nil
+	})
+
+	client := Client{domains: &[]IkDomain{{Name: "example.com", ID: 100}}, HttpClient: httpClient}
+	rec, err := client.CreateOrUpdateRecord(WithDryRun(context.TODO()), "example.com", IkRecord{ID: "1", Type: "A"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "ID", "1", rec.ID)
+}
+
+func Test_CreateOrUpdateRecord_AssignsSyntheticIdInDryRun(t *testing.T) {
+	client := &Client{domains: &[]IkDomain{{ID: 1, Name: "example.com"}}, HttpClient: newHttpTestClient(func(req *http.Request) *http.Response {
+		t.Fatalf("Expected that no request is sent in dry run")
+		return nil
+	})}
+
+	rec := IkRecord{Type: "TXT", SourceIdn: "_acme-challenge.example.com", Target: `"token"`}
+	first, err := client.CreateOrUpdateRecord(WithDryRun(context.TODO()), "example.com", rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := client.CreateOrUpdateRecord(WithDryRun(context.TODO()), "example.com", rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.ID == "" {
+		t.Fatal("Expected synthetic ID in dry run")
+	}
+	assertEquals(t, "ID of same record", first.ID, second.ID)
+
+	rec.Target = `"other"`
+	if SyntheticRecordID(rec) == first.ID {
+		t.Fatal("Expected different IDs for records with different values")
+	}
+}
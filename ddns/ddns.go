@@ -0,0 +1,232 @@
+// Package ddns keeps A and AAAA records up to date with the public IP addresses of the host,
+// e.g. for hosts on connections with dynamic IP addresses.
+package ddns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+// Default interval between two detections of the public IP addresses
+const defaultInterval = 5 * time.Minute
+
+// Default TTL in seconds of the updated records
+const defaultTtlSecs = 300
+
+// Maximum size of a response of a detection service
+const maxDetectionResponseSize = 1024
+
+// Detector detects a public IP address of the host
+type Detector interface {
+	// Detect returns the current public IP address
+	Detect(ctx context.Context) (net.IP, error)
+}
+
+// DetectorFunc allows to use a function as Detector
+type DetectorFunc func(ctx context.Context) (net.IP, error)
+
+// Detect calls the function
+func (f DetectorFunc) Detect(ctx context.Context) (net.IP, error) {
+	return f(ctx)
+}
+
+// HTTPDetector detects the public IP address by calling a service that responds with the caller's address as plain text
+type HTTPDetector struct {
+	// URL of the service, e.g. "https://api.ipify.org" for IPv4 or "https://api6.ipify.org" for IPv6
+	URL string
+
+	// Client used to call the service, the default http client is used if not set
+	Client *http.Client
+}
+
+// Detect calls the service and parses its response
+func (d *HTTPDetector) Detect(ctx context.Context) (net.IP, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	client := d.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("detection service %s responded with HTTP %d", d.URL, resp.StatusCode)
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxDetectionResponseSize))
+	if err != nil {
+		return nil, err
+	}
+	ip := net.ParseIP(strings.TrimSpace(string(body)))
+	if ip == nil {
+		return nil, fmt.Errorf("detection service %s responded with invalid IP address '%s'", d.URL, strings.TrimSpace(string(body)))
+	}
+	return ip, nil
+}
+
+// Updater periodically detects the public IPv4 and IPv6 address of the host and sets the A and AAAA records of
+// the configured names to them. Records are only set if an address changed.
+type Updater struct {
+	// Provider used to set the records, e.g. an *infomaniak.Provider
+	Provider libdns.RecordSetter
+
+	// Zone of the records
+	Zone string
+
+	// Names of the records relative to the zone, "@" for the zone apex
+	Names []string
+
+	// IPv4Detector detects the IPv4 address, no A records are set if not set
+	IPv4Detector Detector
+
+	// IPv6Detector detects the IPv6 address, no AAAA records are set if not set
+	IPv6Detector Detector
+
+	// Interval between two detections, 5 minutes if not set
+	Interval time.Duration
+
+	// TtlInSec TTL of the records in seconds, 300 if not set
+	TtlInSec uint
+
+	// Confirmations number of consecutive detections of a changed address before the records are updated,
+	// which prevents flapping records if detection services disagree - the first detection is always applied
+	Confirmations int
+
+	// OnUpdate is called after records were set to a new address if not nil
+	OnUpdate func(recType string, ip net.IP)
+
+	// addresses the records were last set to by record type
+	applied map[string]string
+
+	// changed addresses not confirmed yet by record type
+	candidates map[string]candidate
+
+	// mutex to prevent concurrent updates
+	mu sync.Mutex
+}
+
+// candidate changed address and how often it was detected in a row
+type candidate struct {
+	ip    string
+	count int
+}
+
+// Run updates the records immediately and then in the configured interval until the context is done.
+// Errors of single updates are passed to onError if it is not nil, the records are updated again in the next interval.
+func (u *Updater) Run(ctx context.Context, onError func(err error)) error {
+	interval := u.Interval
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := u.Update(ctx); err != nil && onError != nil && ctx.Err() == nil {
+			onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Update detects the public addresses once and sets the records of each address that changed
+func (u *Updater) Update(ctx context.Context) error {
+	if u.IPv4Detector == nil && u.IPv6Detector == nil {
+		return errors.New("no detector configured")
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	var errs []string
+	if u.IPv4Detector != nil {
+		if err := u.update(ctx, "A", u.IPv4Detector); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if u.IPv6Detector != nil {
+		if err := u.update(ctx, "AAAA", u.IPv6Detector); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// update detects the address of the given record type and sets the records if the address changed and was confirmed
+func (u *Updater) update(ctx context.Context, recType string, detector Detector) error {
+	ip, err := detector.Detect(ctx)
+	if err != nil {
+		return fmt.Errorf("could not detect address for %s records: %v", recType, err)
+	}
+	if (recType == "A") != (ip.To4() != nil) {
+		return fmt.Errorf("detected address %s cannot be used for %s records", ip, recType)
+	}
+
+	value := ip.String()
+	if u.applied == nil {
+		u.applied = make(map[string]string)
+		u.candidates = make(map[string]candidate)
+	}
+	applied, ok := u.applied[recType]
+	if applied == value {
+		delete(u.candidates, recType)
+		return nil
+	}
+	if ok && !u.confirm(recType, value) {
+		return nil
+	}
+
+	ttl := u.TtlInSec
+	if ttl <= 0 {
+		ttl = defaultTtlSecs
+	}
+	records := make([]libdns.Record, 0, len(u.Names))
+	for _, name := range u.Names {
+		records = append(records, libdns.Record{Type: recType, Name: name, Value: value, TTL: time.Duration(ttl)})
+	}
+	_, err = u.Provider.SetRecords(ctx, u.Zone, records)
+	if err != nil {
+		return fmt.Errorf("could not set %s records to %s: %v", recType, value, err)
+	}
+	u.applied[recType] = value
+	delete(u.candidates, recType)
+	if u.OnUpdate != nil {
+		u.OnUpdate(recType, ip)
+	}
+	return nil
+}
+
+// confirm counts the detection of a changed address and returns if it was detected often enough in a row
+func (u *Updater) confirm(recType string, value string) bool {
+	c := u.candidates[recType]
+	if c.ip != value {
+		c = candidate{ip: value}
+	}
+	c.count++
+	u.candidates[recType] = c
+	return c.count >= u.Confirmations
+}
+
+// Interface guards
+var (
+	_ Detector = (*HTTPDetector)(nil)
+)
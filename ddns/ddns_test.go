@@ -0,0 +1,81 @@
+package ddns
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/libdns/libdns"
+)
+
+// testSetter records the records passed to SetRecords
+type testSetter struct {
+	calls [][]libdns.Record
+}
+
+// SetRecords implementation to fulfill libdns.RecordSetter interface
+func (s *testSetter) SetRecords(ctx context.Context, zone string, recs []libdns.Record) ([]libdns.Record, error) {
+	s.calls = append(s.calls, recs)
+	return recs, nil
+}
+
+// staticDetector returns a detector that returns the address the given variable points to
+func staticDetector(ip *string) Detector {
+	return DetectorFunc(func(ctx context.Context) (net.IP, error) {
+		return net.ParseIP(*ip), nil
+	})
+}
+
+func Test_Update_SetsRecordsOnlyIfAddressChanged(t *testing.T) {
+	ip := "192.0.2.1"
+	setter := &testSetter{}
+	updater := Updater{Provider: setter, Zone: "example.com", Names: []string{"@", "www"}, IPv4Detector: staticDetector(&ip)}
+
+	for i := 0; i < 2; i++ {
+		if err := updater.Update(context.TODO()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(setter.calls) != 1 || len(setter.calls[0]) != 2 {
+		t.Fatalf("Expected one call with 2 records, got %v", setter.calls)
+	}
+
+	ip = "192.0.2.2"
+	if err := updater.Update(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	if len(setter.calls) != 2 || setter.calls[1][0].Value != ip {
+		t.Fatalf("Expected records to be set to %s, got %v", ip, setter.calls)
+	}
+}
+
+func Test_Update_WaitsForConfirmationOfChangedAddress(t *testing.T) {
+	ip := "2001:db8::1"
+	setter := &testSetter{}
+	updater := Updater{Provider: setter, Zone: "example.com", Names: []string{"www"}, IPv6Detector: staticDetector(&ip), Confirmations: 2}
+	if err := updater.Update(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+
+	ip = "2001:db8::2"
+	if err := updater.Update(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	if len(setter.calls) != 1 {
+		t.Fatalf("Expected changed address to not be applied before it is confirmed")
+	}
+	if err := updater.Update(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	if len(setter.calls) != 2 || setter.calls[1][0].Type != "AAAA" {
+		t.Fatalf("Expected confirmed address to be applied, got %v", setter.calls)
+	}
+}
+
+func Test_Update_RejectsAddressOfWrongFamily(t *testing.T) {
+	ip := "2001:db8::1"
+	updater := Updater{Provider: &testSetter{}, Zone: "example.com", Names: []string{"www"}, IPv4Detector: staticDetector(&ip)}
+	if err := updater.Update(context.TODO()); err == nil {
+		t.Fatalf("Expected error for IPv6 address detected for A records")
+	}
+}
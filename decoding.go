@@ -0,0 +1,310 @@
+package infomaniak
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Type with the fields of IkRecord but without its custom decoding
+type ikRecordAlias IkRecord
+
+// Fields of a record whose encoding varies between API versions and endpoints
+type ikRecordFlexibleFields struct {
+	*ikRecordAlias
+	ID          json.RawMessage `json:"id,omitempty"`
+	TtlInSec    json.RawMessage `json:"ttl"`
+	Priority    json.RawMessage `json:"priority,omitempty"`
+	UpdatedAt   json.RawMessage `json:"updated_at,omitempty"`
+	DyndnsID    json.RawMessage `json:"dyndns_id,omitempty"`
+	Description ignoredJson     `json:"description,omitempty"`
+}
+
+// ignoredJson is a JSON value that is skipped without being decoded
+type ignoredJson struct{}
+
+// UnmarshalJSON ignores the value
+func (ignoredJson) UnmarshalJSON([]byte) error {
+	return nil
+}
+
+// UnmarshalJSON decodes an infomaniak API record and normalizes the payload variations
+// returned by different API versions and endpoints in one place
+func (r *IkRecord) UnmarshalJSON(data []byte) error {
+	return unmarshalRecord(data, r, true)
+}
+
+// unmarshalRecord decodes an infomaniak API record, the record's description is only decoded if withDescription is set
+func unmarshalRecord(data []byte, r *IkRecord, withDescription bool) error {
+	aux := struct {
+		ikRecordFlexibleFields
+		Description map[string]json.RawMessage `json:"description,omitempty"`
+	}{ikRecordFlexibleFields: ikRecordFlexibleFields{ikRecordAlias: (*ikRecordAlias)(r)}}
+
+	var err error
+	if withDescription {
+		err = json.Unmarshal(data, &aux)
+	} else {
+		err = json.Unmarshal(data, &aux.ikRecordFlexibleFields)
+	}
+	if err != nil {
+		return err
+	}
+
+	r.ID, err = decodeFlexibleString(aux.ID)
+	if err != nil {
+		return fmt.Errorf("could not decode ID of record: %v", err)
+	}
+
+	ttl, err := decodeFlexibleUint(aux.TtlInSec)
+	if err != nil {
+		return fmt.Errorf("could not decode TTL of record %s: %v", r.ID, err)
+	}
+	r.TtlInSec = ttl
+
+	rawPriority := aux.Priority
+	if isEmptyJson(rawPriority) {
+		rawPriority = aux.Description["priority"]
+	}
+	priority, err := decodeFlexibleUint(unwrapDescriptionValue(rawPriority))
+	if err != nil {
+		return fmt.Errorf("could not decode priority of record %s: %v", r.ID, err)
+	}
+	r.Priority = priority
+
+	r.UpdatedAt, err = decodeTimestamp(aux.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("could not decode update time of record %s: %v", r.ID, err)
+	}
+
+	r.DyndnsID, err = decodeFlexibleString(aux.DyndnsID)
+	if err != nil {
+		return fmt.Errorf("could not decode dyndns ID of record %s: %v", r.ID, err)
+	}
+
+	if len(aux.Description) > 0 {
+		r.DescriptionRaw = aux.Description
+		r.Description, err = decodeDescription(aux.Description)
+		if err != nil {
+			return fmt.Errorf("could not decode description of record %s: %v", r.ID, err)
+		}
+	}
+	return nil
+}
+
+// recordListDecoder decodes the records of a listing one after another directly into IkRecords, the fields of other API
+// revisions are renamed while each record is decoded. Records that cannot be decoded are skipped and their errors collected,
+// so that a single broken record does not fail the whole listing. Decoding multiple pages appends their records.
+type recordListDecoder struct {
+	client          *Client
+	withDescription bool
+
+	// names of the current revision by the names of the fields used by the API, set once the first record was decoded
+	currentNames FieldNames
+	namesKnown   bool
+
+	records []IkRecord
+	errors  []error
+}
+
+// UnmarshalJSON decodes the JSON array of records, each record is decoded from the bytes of the array without copying them
+func (d *recordListDecoder) UnmarshalJSON(data []byte) error {
+	if isEmptyJson(data) {
+		return nil
+	}
+	return forEachJsonArrayElement(data, d.decodeRecord)
+}
+
+// decodeRecord decodes a single raw record and appends it, or its error, to the list
+func (d *recordListDecoder) decodeRecord(raw []byte) {
+	if !d.namesKnown {
+		d.currentNames = d.client.getFieldNames(raw).inverse()
+		d.namesKnown = true
+	}
+	var rec IkRecord
+	renamed, err := renameFields(raw, d.currentNames)
+	if err == nil {
+		err = unmarshalRecord(renamed, &rec, d.withDescription)
+	}
+	if err != nil {
+		d.errors = append(d.errors, err)
+		return
+	}
+	d.records = append(d.records, rec)
+}
+
+// forEachJsonArrayElement calls fn with the raw bytes of every element of the JSON array, which has to be syntactically
+// valid as it is the case for values passed to json.Unmarshaler - elements are only delimited, not validated
+func forEachJsonArrayElement(data []byte, fn func(raw []byte)) error {
+	data = bytes.TrimSpace(data)
+	if len(data) < 2 || data[0] != '[' || data[len(data)-1] != ']' {
+		return fmt.Errorf("expected JSON array, got %.20s", string(data))
+	}
+	depth, start, inString, escaped := 0, 1, false, false
+	for i := 1; i < len(data)-1; i++ {
+		c := data[i]
+		switch {
+		case inString:
+			if escaped {
+				escaped = false
+			} else if c == '\\' {
+				escaped = true
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case c == '{' || c == '[':
+			depth++
+		case c == '}' || c == ']':
+			depth--
+		case c == ',' && depth == 0:
+			fn(bytes.TrimSpace(data[start:i]))
+			start = i + 1
+		}
+	}
+	if depth != 0 || inString {
+		return fmt.Errorf("unterminated JSON array")
+	}
+	if last := bytes.TrimSpace(data[start : len(data)-1]); len(last) > 0 {
+		fn(last)
+	}
+	return nil
+}
+
+// decodeDescription decodes the priority-like attributes of a record's description, whose values may be wrapped in descriptive objects
+func decodeDescription(raw map[string]json.RawMessage) (*IkRecordDescription, error) {
+	description := &IkRecordDescription{}
+	numbers := map[string]*uint{
+		"priority":   &description.Priority,
+		"weight":     &description.Weight,
+		"port":       &description.Port,
+		"order":      &description.Order,
+		"preference": &description.Preference,
+	}
+	for key, target := range numbers {
+		value, err := decodeFlexibleUint(unwrapDescriptionValue(raw[key]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", key, err)
+		}
+		*target = value
+	}
+	flags, err := decodeFlexibleString(unwrapDescriptionValue(raw["flags"]))
+	if err != nil {
+		return nil, fmt.Errorf("invalid flags: %v", err)
+	}
+	description.Flags = flags
+	return description, nil
+}
+
+// unwrapDescriptionValue returns the value of descriptive objects in the form {"value":...,"label":...},
+// any other raw value is returned as it is
+func unwrapDescriptionValue(raw json.RawMessage) json.RawMessage {
+	var described struct {
+		Value json.RawMessage `json:"value"`
+	}
+	if json.Unmarshal(raw, &described) == nil && !isEmptyJson(described.Value) {
+		return described.Value
+	}
+	return raw
+}
+
+// decodeFlexibleString decodes a raw value that is either a JSON string or a JSON number to a string
+func decodeFlexibleString(raw json.RawMessage) (string, error) {
+	if isEmptyJson(raw) {
+		return "", nil
+	}
+	var value string
+	if isJsonString(raw) && json.Unmarshal(raw, &value) == nil {
+		return value, nil
+	}
+	number, err := decodeNumber(raw)
+	if err != nil {
+		return "", err
+	}
+	return number.String(), nil
+}
+
+// decodeFlexibleUint decodes a raw value that is either a JSON number or a string containing a number to an uint
+func decodeFlexibleUint(raw json.RawMessage) (uint, error) {
+	if isEmptyJson(raw) {
+		return 0, nil
+	}
+	var number json.Number
+	var value string
+	if isJsonString(raw) && json.Unmarshal(raw, &value) == nil {
+		if value == "" {
+			return 0, nil
+		}
+		number = json.Number(value)
+	} else {
+		var err error
+		number, err = decodeNumber(raw)
+		if err != nil {
+			return 0, err
+		}
+	}
+	result, err := strconv.ParseUint(number.String(), 10, 0)
+	if err != nil {
+		return 0, fmt.Errorf("invalid unsigned number %s", number)
+	}
+	return uint(result), nil
+}
+
+// decodeTimestamp decodes a raw value that is either a unix timestamp in seconds or a RFC 3339 string to a time
+func decodeTimestamp(raw json.RawMessage) (time.Time, error) {
+	var value string
+	if isJsonString(raw) && json.Unmarshal(raw, &value) == nil {
+		if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+			return parsed, nil
+		}
+	}
+	seconds, err := decodeFlexibleUint(raw)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if seconds == 0 {
+		return time.Time{}, nil
+	}
+	return time.Unix(int64(seconds), 0), nil
+}
+
+// decodeNumber decodes a raw JSON number without losing precision
+func decodeNumber(raw json.RawMessage) (json.Number, error) {
+	if isJsonInteger(raw) {
+		return json.Number(raw), nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var number json.Number
+	err := decoder.Decode(&number)
+	if err != nil {
+		return "", fmt.Errorf("expected number or string, got %s", string(raw))
+	}
+	return number, nil
+}
+
+// isEmptyJson returns if the raw value is not set or null
+func isEmptyJson(raw json.RawMessage) bool {
+	return len(raw) == 0 || string(raw) == "null"
+}
+
+// isJsonString returns if the raw value is a JSON string, so that decoding attempts of other values can be skipped
+func isJsonString(raw json.RawMessage) bool {
+	return len(raw) > 0 && raw[0] == '"'
+}
+
+// isJsonInteger returns if the raw value is a JSON integer consisting of digits only, which is the usual form of numbers returned by the API
+func isJsonInteger(raw json.RawMessage) bool {
+	if len(raw) == 0 || (raw[0] == '0' && len(raw) > 1) {
+		return false
+	}
+	for _, c := range raw {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,119 @@
+package infomaniak
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func Test_UnmarshalJSON_DecodesPlainPriority(t *testing.T) {
+	var rec IkRecord
+	err := json.Unmarshal([]byte(`{"id":"1","type":"MX","priority":20}`), &rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "ID", "1", rec.ID)
+	assertEqualsInt(t, "Priority", 20, int(rec.Priority))
+}
+
+func Test_UnmarshalJSON_DecodesPriorityWrappedInValueObject(t *testing.T) {
+	var rec IkRecord
+	err := json.Unmarshal([]byte(`{"id":"1","type":"MX","priority":{"value":20,"label":"Priority"}}`), &rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "Priority", 20, int(rec.Priority))
+}
+
+func Test_UnmarshalJSON_DecodesPriorityOfDescription(t *testing.T) {
+	var rec IkRecord
+	err := json.Unmarshal([]byte(`{"id":"1","type":"MX","description":{"priority":{"value":30,"label":"Priority"}}}`), &rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "Priority", 30, int(rec.Priority))
+}
+
+func Test_UnmarshalJSON_DecodesNumericId(t *testing.T) {
+	var rec IkRecord
+	err := json.Unmarshal([]byte(`{"id":123456789,"type":"A","ttl":300}`), &rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "ID", "123456789", rec.ID)
+}
+
+func Test_UnmarshalJSON_DecodesTtlAndPriorityGivenAsString(t *testing.T) {
+	var rec IkRecord
+	err := json.Unmarshal([]byte(`{"id":"1","type":"MX","ttl":"3600","priority":"5"}`), &rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "TTL", 3600, int(rec.TtlInSec))
+	assertEqualsInt(t, "Priority", 5, int(rec.Priority))
+}
+
+func Test_UnmarshalJSON_ReturnsErrorForNonNumericTtl(t *testing.T) {
+	var rec IkRecord
+	err := json.Unmarshal([]byte(`{"id":"1","type":"A","ttl":"one hour"}`), &rec)
+	if err == nil {
+		t.Fatalf("Expected error for non numeric TTL, got %#v", rec)
+	}
+}
+
+func Test_UnmarshalJSON_DecodesUpdateTimeAsUnixTimestampOrString(t *testing.T) {
+	var rec IkRecord
+	err := json.Unmarshal([]byte(`{"id":1,"updated_at":1700000000}`), &rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "UpdatedAt", 1700000000, int(rec.UpdatedAt.Unix()))
+
+	err = json.Unmarshal([]byte(`{"id":1,"updated_at":"2023-11-14T22:13:20Z"}`), &rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "UpdatedAt", 1700000000, int(rec.UpdatedAt.Unix()))
+}
+
+func Test_UnmarshalJSON_DecodesPriorityLikeDescriptionAttributes(t *testing.T) {
+	var rec IkRecord
+	err := json.Unmarshal([]byte(`{"id":"1","type":"NAPTR","description":{"order":{"value":100},"preference":"10","flags":{"value":"U"}}}`), &rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "Order", 100, int(rec.Description.Order))
+	assertEqualsInt(t, "Preference", 10, int(rec.Description.Preference))
+	assertEquals(t, "Flags", "U", rec.Description.Flags)
+	assertEqualsInt(t, "Priority", 100, int(rec.ToLibDnsRecord("").Priority))
+}
+
+func Test_UnmarshalJSON_KeepsAllDescriptionAttributes(t *testing.T) {
+	var rec IkRecord
+	err := json.Unmarshal([]byte(`{"id":"1","type":"MX","description":{"priority":{"value":10},"comment":"backup mx"}}`), &rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "description attributes", 2, len(rec.DescriptionRaw))
+	assertEquals(t, "comment", `"backup mx"`, string(rec.DescriptionRaw["comment"]))
+
+	encoded, err := json.Marshal(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(encoded), `"description":{"comment":"backup mx","priority":{"value":10}}`) {
+		t.Fatalf("Expected description to be encoded, got %s", encoded)
+	}
+}
+
+func Test_RecordListDecoder_DecodesRecordsWithDelimitersInStrings(t *testing.T) {
+	list := recordListDecoder{client: &Client{}}
+	err := json.Unmarshal([]byte(` [ {"id":1,"type":"TXT","target":"a,b]},{\"c\""} , {"id":2,"type":"TXT","target":"[d"},{"id":3,"ttl":"x"} ] `), &list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "records", 2, len(list.records))
+	assertEqualsInt(t, "errors", 1, len(list.errors))
+	assertEquals(t, "target of first record", `a,b]},{"c"`, list.records[0].Target)
+	assertEquals(t, "target of second record", "[d", list.records[1].Target)
+}
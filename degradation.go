@@ -0,0 +1,200 @@
+package infomaniak
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Default interval after which a degraded provider lets a read through to check if the API recovered
+const defaultDegradedRetryInterval = 30 * time.Second
+
+// Mode in which the provider operates
+type Mode string
+
+const (
+	// ModeNormal all calls are sent to the API
+	ModeNormal Mode = "normal"
+
+	// ModeDegraded the API failed repeatedly, reads are served from the last listed records or the ZoneStore and writes are rejected
+	ModeDegraded Mode = "degraded"
+)
+
+// Status of the provider for monitoring
+type Status struct {
+	// Mode the provider currently operates in
+	Mode Mode
+
+	// ConsecutiveFailures of API calls since the last successful call
+	ConsecutiveFailures int
+
+	// DegradedSince point in time the provider switched to the degraded mode, zero in normal mode
+	DegradedSince time.Time
+
+	// LastError of the last failed API call, nil if the last call succeeded
+	LastError error
+}
+
+// DegradedError is returned without calling the API while the provider operates in degraded mode
+type DegradedError struct {
+	// Since point in time the provider switched to the degraded mode
+	Since time.Time
+
+	// LastError of the API call that failed last
+	LastError error
+}
+
+// Error returns a description of the degraded mode
+func (e *DegradedError) Error() string {
+	return "infomaniak API is unavailable since " + e.Since.Format(time.RFC3339) + ", calls are rejected until it recovers: " + e.LastError.Error()
+}
+
+// Unwrap returns the error of the API call that failed last
+func (e *DegradedError) Unwrap() error {
+	return e.LastError
+}
+
+// Status returns the mode the provider currently operates in and its recent failures
+func (p *Provider) Status() Status {
+	return p.health.status()
+}
+
+// healthTracker counts consecutive failures of API calls and decides if calls are let through
+type healthTracker struct {
+	failures      int
+	degradedSince time.Time
+	retryAt       time.Time
+	lastErr       error
+	mu            sync.Mutex
+}
+
+// allow returns a *DegradedError if the provider is degraded, except for one read per retry interval that checks if the API
+// recovered - writes are never used to probe the API, as they would be applied if it recovered
+func (h *healthTracker) allow(retryInterval time.Duration, read bool) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.degradedSince.IsZero() {
+		return nil
+	}
+	now := time.Now()
+	if !read || now.Before(h.retryAt) {
+		return &DegradedError{Since: h.degradedSince, LastError: h.lastErr}
+	}
+	h.retryAt = now.Add(retryInterval)
+	return nil
+}
+
+// record counts the outcome of an API call and switches the mode once the failure threshold is reached or a call succeeded
+func (h *healthTracker) record(err error, threshold int, retryInterval time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !isServiceFailure(err) {
+		h.failures = 0
+		h.lastErr = nil
+		h.degradedSince = time.Time{}
+		return
+	}
+	h.failures++
+	h.lastErr = err
+	if h.failures >= threshold && h.degradedSince.IsZero() {
+		h.degradedSince = time.Now()
+		h.retryAt = h.degradedSince.Add(retryInterval)
+	}
+}
+
+// status returns the current status
+func (h *healthTracker) status() Status {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	mode := ModeNormal
+	if !h.degradedSince.IsZero() {
+		mode = ModeDegraded
+	}
+	return Status{Mode: mode, ConsecutiveFailures: h.failures, DegradedSince: h.degradedSince, LastError: h.lastErr}
+}
+
+// isServiceFailure returns if the error indicates that the API is not available, as opposed to errors caused by the request
+func isServiceFailure(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var apiErr *ApiError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= 500
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// degradingClient decorates a client so that its calls are tracked and rejected while the provider is degraded
+type degradingClient struct {
+	client        IkClient
+	health        *healthTracker
+	threshold     int
+	retryInterval time.Duration
+}
+
+// GetDnsRecordsForZone loads the records unless the provider is degraded
+func (c *degradingClient) GetDnsRecordsForZone(ctx context.Context, zone string) ([]IkRecord, error) {
+	if err := c.health.allow(c.retryInterval, true); err != nil {
+		return nil, err
+	}
+	records, err := c.client.GetDnsRecordsForZone(ctx, zone)
+	c.health.record(err, c.threshold, c.retryInterval)
+	return records, err
+}
+
+// CreateOrUpdateRecord writes the record unless the provider is degraded
+func (c *degradingClient) CreateOrUpdateRecord(ctx context.Context, zone string, record IkRecord) (*IkRecord, error) {
+	if err := c.health.allow(c.retryInterval, false); err != nil {
+		return nil, err
+	}
+	result, err := c.client.CreateOrUpdateRecord(ctx, zone, record)
+	c.health.record(err, c.threshold, c.retryInterval)
+	return result, err
+}
+
+// PatchRecord updates the record unless the provider is degraded
+func (c *degradingClient) PatchRecord(ctx context.Context, zone string, id string, changes IkRecordPatch) error {
+	patcher, ok := c.client.(IkRecordPatcher)
+	if !ok {
+		return errPatchNotSupported
+	}
+	if err := c.health.allow(c.retryInterval, false); err != nil {
+		return err
+	}
+	err := patcher.PatchRecord(ctx, zone, id, changes)
+	c.health.record(err, c.threshold, c.retryInterval)
+	return err
+}
+
+// DeleteRecord deletes the record unless the provider is degraded
+func (c *degradingClient) DeleteRecord(ctx context.Context, zone string, id string) error {
+	if err := c.health.allow(c.retryInterval, false); err != nil {
+		return err
+	}
+	err := c.client.DeleteRecord(ctx, zone, id)
+	c.health.record(err, c.threshold, c.retryInterval)
+	return err
+}
+
+// withDegradation returns the client decorated with the degraded mode if DegradeAfterFailures is set
+func (p *Provider) withDegradation(client IkClient) IkClient {
+	if p.DegradeAfterFailures <= 0 {
+		return client
+	}
+	retryInterval := p.DegradedRetryInterval
+	if retryInterval <= 0 {
+		retryInterval = defaultDegradedRetryInterval
+	}
+	return &degradingClient{client: client, health: &p.health, threshold: p.DegradeAfterFailures, retryInterval: retryInterval}
+}
+
+// Interface guards
+var (
+	_ IkClient        = (*degradingClient)(nil)
+	_ IkRecordPatcher = (*degradingClient)(nil)
+)
@@ -0,0 +1,119 @@
+package infomaniak
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+func Test_Provider_EntersDegradedModeAndRecovers(t *testing.T) {
+	available := true
+	getterCalls := 0
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			getterCalls++
+			if !available {
+				return nil, &ApiError{StatusCode: http.StatusServiceUnavailable}
+			}
+			return []IkRecord{{ID: "1", Type: "A", SourceIdn: "www.example.com", Target: "192.0.2.1"}}, nil
+		},
+		setter: func(ctx context.Context, zone string, record IkRecord) (*IkRecord, error) {
+			t.Fatalf("Expected write to be rejected in degraded mode")
+			return nil, nil
+		},
+	}
+	provider := Provider{client: &client, DegradeAfterFailures: 2, DegradedRetryInterval: time.Hour, RecordCacheTtl: time.Nanosecond}
+
+	_, err := provider.GetRecords(context.TODO(), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	available = false
+	for i := 0; i < 2; i++ {
+		time.Sleep(time.Millisecond)
+		records, err := provider.GetRecords(context.TODO(), "example.com")
+		if err == nil || records != nil {
+			t.Fatalf("Expected error while API is unavailable, got %v", records)
+		}
+	}
+	assertEquals(t, "mode", string(ModeDegraded), string(provider.Status().Mode))
+
+	records, err := provider.GetRecords(context.TODO(), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "cached records", 1, len(records))
+	assertEqualsInt(t, "API calls", 3, getterCalls)
+
+	_, err = provider.AppendRecords(context.TODO(), "example.com", []libdns.Record{{Type: "A", Name: "api", Value: "192.0.2.2"}})
+	var degradedErr *DegradedError
+	if !errors.As(err, &degradedErr) {
+		t.Fatalf("Expected *DegradedError, got %v", err)
+	}
+
+	provider.health.retryAt = time.Now()
+	available = true
+	time.Sleep(time.Millisecond)
+	_, err = provider.GetRecords(context.TODO(), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "mode", string(ModeNormal), string(provider.Status().Mode))
+}
+
+func Test_Provider_ServesLastListedRecordsWhileDegradedWithoutRecordCache(t *testing.T) {
+	available := true
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			if !available {
+				return nil, &ApiError{StatusCode: http.StatusServiceUnavailable}
+			}
+			return []IkRecord{{ID: "1", Type: "A", SourceIdn: "www.example.com", Target: "192.0.2.1"}}, nil
+		},
+	}
+	provider := Provider{client: &client, DegradeAfterFailures: 1, DegradedRetryInterval: time.Hour}
+
+	if _, err := provider.GetRecords(context.TODO(), "example.com"); err != nil {
+		t.Fatal(err)
+	}
+	available = false
+	if _, err := provider.GetRecords(context.TODO(), "example.com"); err == nil {
+		t.Fatalf("Expected error of the call that degrades the provider")
+	}
+	records, err := provider.GetRecords(context.TODO(), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "last listed records", 1, len(records))
+}
+
+func Test_Provider_ProbesRecoveryOnlyWithReads(t *testing.T) {
+	setterCalls := 0
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			return nil, &ApiError{StatusCode: http.StatusServiceUnavailable}
+		},
+		setter: func(ctx context.Context, zone string, record IkRecord) (*IkRecord, error) {
+			setterCalls++
+			return &record, nil
+		},
+	}
+	provider := Provider{client: &client, DegradeAfterFailures: 1, DegradedRetryInterval: time.Hour}
+	provider.GetRecords(context.TODO(), "example.com")
+	provider.health.retryAt = time.Now()
+
+	degradingClient, err := provider.getClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = degradingClient.CreateOrUpdateRecord(context.TODO(), "example.com", IkRecord{Type: "A", SourceIdn: "api.example.com", Target: "192.0.2.2"})
+	var degradedErr *DegradedError
+	if !errors.As(err, &degradedErr) {
+		t.Fatalf("Expected *DegradedError, got %v", err)
+	}
+	assertEqualsInt(t, "writes sent to the API", 0, setterCalls)
+}
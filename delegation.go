@@ -0,0 +1,68 @@
+package infomaniak
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/libdns/libdns"
+)
+
+// DelegateSubzone delegates the child zone, given relative to the parent zone, to the given nameservers by replacing the NS records
+// of the child's name. New records are created before surplus ones are deleted, so the delegation stays resolvable during the change.
+// It returns the NS records of the delegation. TTLs are given in seconds, a TTL of 0 applies the default TTL.
+func (p *Provider) DelegateSubzone(ctx context.Context, parentZone string, child string, nameservers []string, ttlSecs uint) ([]libdns.Record, error) {
+	return p.DelegateSubzoneWithGlue(ctx, parentZone, child, nameservers, nil, ttlSecs)
+}
+
+// DelegateSubzoneWithGlue delegates the child zone as DelegateSubzone does and additionally sets the A and AAAA glue records of
+// nameservers that are part of the child zone, glue maps the host names of such nameservers to their IP addresses.
+// It returns the NS and glue records of the delegation.
+func (p *Provider) DelegateSubzoneWithGlue(ctx context.Context, parentZone string, child string, nameservers []string, glue map[string][]string, ttlSecs uint) ([]libdns.Record, error) {
+	parentZone = getWithoutTrailingDot(parentZone)
+	records, err := buildDelegationRecords(parentZone, child, nameservers, glue, ttlSecs)
+	if err != nil {
+		return nil, err
+	}
+	return p.withZoneLock(ctx, parentZone, func() ([]libdns.Record, error) {
+		return p.reconcile(ctx, parentZone, records)
+	})
+}
+
+// buildDelegationRecords returns the NS records and glue records of a delegation and validates all given values
+func buildDelegationRecords(parentZone string, child string, nameservers []string, glue map[string][]string, ttlSecs uint) ([]libdns.Record, error) {
+	childName := normalizeName(child)
+	if isApexName(childName) {
+		return nil, fmt.Errorf("cannot delegate the apex of zone '%s'", parentZone)
+	}
+	if len(nameservers) <= 0 {
+		return nil, fmt.Errorf("no nameservers given to delegate '%s' to", childName)
+	}
+	childFqdn := toAbsoluteName(childName, parentZone)
+
+	set := NewRecordSet(parentZone)
+	delegatedHosts := make(map[string]bool, len(nameservers))
+	for _, ns := range nameservers {
+		set.NS(childName, ns, ttlSecs)
+		delegatedHosts[strings.ToLower(getWithoutTrailingDot(ns))] = true
+	}
+	for host, ips := range glue {
+		host = strings.ToLower(getWithoutTrailingDot(host))
+		if !delegatedHosts[host] {
+			return nil, fmt.Errorf("glue given for '%s' which is not a nameserver of the delegation", host)
+		}
+		if !isInZone(host, childFqdn) {
+			return nil, fmt.Errorf("glue given for '%s' which is not part of the delegated zone '%s'", host, childFqdn)
+		}
+		for _, ip := range ips {
+			parsed := net.ParseIP(ip)
+			if parsed != nil && parsed.To4() == nil {
+				set.AAAA(host+".", ip, ttlSecs)
+			} else {
+				set.A(host+".", ip, ttlSecs)
+			}
+		}
+	}
+	return set.Records()
+}
@@ -0,0 +1,72 @@
+package infomaniak
+
+import (
+	"context"
+	"testing"
+)
+
+func Test_DelegateSubzone_ReplacesNsRecordsOfChild(t *testing.T) {
+	created := make([]IkRecord, 0)
+	deletedIds := make([]string, 0)
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			return []IkRecord{
+				{ID: "1", Type: "NS", SourceIdn: "sub.example.com", Target: "ns1.old.com"},
+				{ID: "2", Type: "NS", SourceIdn: "sub.example.com", Target: "ns1.new.com"},
+				{ID: "3", Type: "NS", SourceIdn: "other.example.com", Target: "ns1.old.com"},
+			}, nil
+		},
+		setter: func(ctx context.Context, zone string, record IkRecord) (*IkRecord, error) {
+			created = append(created, record)
+			record.ID = "new"
+			return &record, nil
+		},
+		deleter: func(ctx context.Context, zone string, id string) error {
+			deletedIds = append(deletedIds, id)
+			return nil
+		},
+	}
+	provider := Provider{client: &client}
+
+	records, err := provider.DelegateSubzone(context.TODO(), "example.com.", "sub", []string{"ns1.new.com", "ns2.new.com."}, 3600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "records", 2, len(records))
+	assertEqualsInt(t, "created records", 1, len(created))
+	assertEquals(t, "created target", "ns2.new.com.", created[0].Target)
+	assertEqualsInt(t, "created TTL", 3600, int(created[0].TtlInSec))
+	assertEqualsInt(t, "deleted records", 1, len(deletedIds))
+	assertEquals(t, "deleted ID", "1", deletedIds[0])
+}
+
+func Test_DelegateSubzoneWithGlue_CreatesGlueRecords(t *testing.T) {
+	created := make([]IkRecord, 0)
+	client := TestClient{
+		setter: func(ctx context.Context, zone string, record IkRecord) (*IkRecord, error) {
+			created = append(created, record)
+			return &record, nil
+		},
+	}
+	provider := Provider{client: &client}
+
+	_, err := provider.DelegateSubzoneWithGlue(context.TODO(), "example.com", "sub", []string{"ns1.sub.example.com"},
+		map[string][]string{"ns1.sub.example.com": {"192.0.2.1", "2001:db8::1"}}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "created records", 3, len(created))
+	assertEquals(t, "A glue name", "ns1.sub.example.com", created[1].SourceIdn)
+	assertEquals(t, "A glue type", "A", created[1].Type)
+	assertEquals(t, "AAAA glue type", "AAAA", created[2].Type)
+}
+
+func Test_DelegateSubzoneWithGlue_RejectsGlueOutsideOfChild(t *testing.T) {
+	provider := Provider{client: &TestClient{}}
+
+	_, err := provider.DelegateSubzoneWithGlue(context.TODO(), "example.com", "sub", []string{"ns1.other.com"},
+		map[string][]string{"ns1.other.com": {"192.0.2.1"}}, 0)
+	if err == nil {
+		t.Fatalf("Expected error for glue of nameserver outside of the delegated zone")
+	}
+}
@@ -0,0 +1,76 @@
+package infomaniak
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/libdns/libdns"
+)
+
+// nilClient returns nil instead of empty slices, as custom clients may do
+type nilClient struct{}
+
+// GetDnsRecordsForZone returns no records as nil slice
+func (c *nilClient) GetDnsRecordsForZone(ctx context.Context, zone string) ([]IkRecord, error) {
+	return nil, nil
+}
+
+// CreateOrUpdateRecord returns the record
+func (c *nilClient) CreateOrUpdateRecord(ctx context.Context, zone string, record IkRecord) (*IkRecord, error) {
+	return &record, nil
+}
+
+// DeleteRecord succeeds
+func (c *nilClient) DeleteRecord(ctx context.Context, zone string, id string) error {
+	return nil
+}
+
+func Test_Provider_ReturnsEmptySlicesForEmptyZone(t *testing.T) {
+	provider := Provider{client: &nilClient{}}
+	ctx := context.TODO()
+	operations := map[string]func() ([]libdns.Record, error){
+		"GetRecords": func() ([]libdns.Record, error) { return provider.GetRecords(ctx, "example.com") },
+		"AppendRecords": func() ([]libdns.Record, error) {
+			return provider.AppendRecords(ctx, "example.com", []libdns.Record{})
+		},
+		"SetRecords": func() ([]libdns.Record, error) { return provider.SetRecords(ctx, "example.com", []libdns.Record{}) },
+		"DeleteRecords": func() ([]libdns.Record, error) {
+			return provider.DeleteRecords(ctx, "example.com", []libdns.Record{{Type: "TXT", Name: "test"}})
+		},
+		"Reconcile": func() ([]libdns.Record, error) { return provider.Reconcile(ctx, "example.com", []libdns.Record{}) },
+	}
+	for name, operation := range operations {
+		records, err := operation()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if records == nil || len(records) != 0 {
+			t.Fatalf("%s: expected empty slice, got %#v", name, records)
+		}
+	}
+
+	recordsWithMetadata, err := provider.GetRecordsWithMetadata(ctx, "example.com")
+	if err != nil || recordsWithMetadata == nil || len(recordsWithMetadata) != 0 {
+		t.Fatalf("GetRecordsWithMetadata: expected empty slice, got %#v, %v", recordsWithMetadata, err)
+	}
+}
+
+func Test_Provider_ReturnsNilForErrors(t *testing.T) {
+	apiErr := &ApiError{StatusCode: 500}
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			return nil, apiErr
+		},
+	}
+	provider := Provider{client: &client}
+
+	records, err := provider.GetRecords(context.TODO(), "example.com")
+	if !errors.Is(err, apiErr) || records != nil {
+		t.Fatalf("Expected nil records with error, got %#v, %v", records, err)
+	}
+	records, err = provider.SetRecords(context.TODO(), "example.com", []libdns.Record{{Type: "TXT", Name: "test", Value: "value"}})
+	if !errors.Is(err, apiErr) || records != nil {
+		t.Fatalf("Expected nil records with error, got %#v, %v", records, err)
+	}
+}
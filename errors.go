@@ -0,0 +1,215 @@
+package infomaniak
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+// CnameConflictError is returned if a change would result in a CNAME record
+// that coexists with other records of the same name, which is not allowed
+type CnameConflictError struct {
+	// Name relative to the zone at which the conflict occurs
+	Name string
+
+	// Records that would exist at the name after the change
+	Records []libdns.Record
+}
+
+// Error returns a description of the conflict
+func (e *CnameConflictError) Error() string {
+	return fmt.Sprintf("a CNAME record at name '%s' cannot coexist with other records, change would result in %d records", e.Name, len(e.Records))
+}
+
+// ConstraintViolationError is returned in strict mode if the zone state resulting
+// from a change would violate RFC record constraints
+type ConstraintViolationError struct {
+	// Descriptions of all violated constraints
+	Violations []string
+}
+
+// Error returns a description of all violations
+func (e *ConstraintViolationError) Error() string {
+	return "change would violate record constraints: " + strings.Join(e.Violations, "; ")
+}
+
+// PreconditionFailedError is returned by conditional writes if the current
+// state of a RRset does not match the expected state
+type PreconditionFailedError struct {
+	// Precondition that was not met
+	Precondition Precondition
+
+	// Values the RRset actually contains
+	ActualValues []string
+}
+
+// Error returns a description of the failed precondition
+func (e *PreconditionFailedError) Error() string {
+	return fmt.Sprintf("precondition failed for %s record '%s': expected values %v, got %v", e.Precondition.Type, e.Precondition.Name, e.Precondition.Values, e.ActualValues)
+}
+
+// ApiError is returned if the infomaniak API responded with an error
+type ApiError struct {
+	// StatusCode of the HTTP response
+	StatusCode int
+
+	// Header of the HTTP response
+	Header http.Header
+
+	// Errors as returned by the API
+	Errors json.RawMessage
+
+	// Codes of the errors returned by the API including nested errors, outer errors first
+	Codes []string
+
+	// Explanation of the most specific known error code, empty if none of the codes is known
+	Explanation string
+
+	// Hint how to fix the cause of the error, empty if none of the codes is known
+	Hint string
+}
+
+// newApiError returns the error for a response of the API, explained if one of its error codes is known
+func newApiError(statusCode int, header http.Header, rawErrors json.RawMessage) *ApiError {
+	apiErr := &ApiError{StatusCode: statusCode, Header: header, Errors: rawErrors, Codes: errorCodes(rawErrors)}
+	if explanation, ok := explainErrorCodes(apiErr.Codes); ok {
+		apiErr.Explanation = explanation.explanation
+		apiErr.Hint = explanation.hint
+	}
+	return apiErr
+}
+
+// Error returns the status code and the errors returned by the API together with their explanation if known
+func (e *ApiError) Error() string {
+	if e.Explanation == "" {
+		return fmt.Sprintf("got errors: HTTP %d: %+v", e.StatusCode, string(e.Errors))
+	}
+	return fmt.Sprintf("got errors: HTTP %d: %s (%s): %+v", e.StatusCode, e.Explanation, e.Hint, string(e.Errors))
+}
+
+// ApexCnameError is returned if a CNAME record would be created at the zone apex,
+// which is not allowed as the apex always holds SOA and NS records
+type ApexCnameError struct {
+	// Zone at whose apex the CNAME record would be created
+	Zone string
+
+	// Target of the CNAME record
+	Target string
+}
+
+// Error returns a description of the invalid record
+func (e *ApexCnameError) Error() string {
+	return fmt.Sprintf("a CNAME record cannot be created at the apex of zone '%s', use an ALIAS record to point to '%s' instead", e.Zone, e.Target)
+}
+
+// PrimeError is returned by Prime if some of the zones could not be primed
+type PrimeError struct {
+	// Errors by zone that could not be primed
+	Errors map[string]error
+}
+
+// Error returns the errors of all zones that could not be primed
+func (e *PrimeError) Error() string {
+	zones := make([]string, 0, len(e.Errors))
+	for zone := range e.Errors {
+		zones = append(zones, zone)
+	}
+	sort.Strings(zones)
+	descriptions := make([]string, 0, len(zones))
+	for _, zone := range zones {
+		descriptions = append(descriptions, fmt.Sprintf("%s: %v", zone, e.Errors[zone]))
+	}
+	return fmt.Sprintf("could not prime %d zones: %s", len(zones), strings.Join(descriptions, "; "))
+}
+
+// RecordMappingError is returned if some records of a zone returned by the API could not be mapped,
+// unless StrictMapping is set it is returned together with the records that could be mapped
+type RecordMappingError struct {
+	// Zone whose records could not be mapped
+	Zone string
+
+	// Errors of all records that could not be mapped
+	Errors []error
+}
+
+// Error returns the errors of all records that could not be mapped
+func (e *RecordMappingError) Error() string {
+	descriptions := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		descriptions = append(descriptions, err.Error())
+	}
+	return fmt.Sprintf("could not map %d records of zone '%s': %s", len(e.Errors), e.Zone, strings.Join(descriptions, "; "))
+}
+
+// RecordLimitError is returned if a change would exceed the maximum number of records of a zone
+type RecordLimitError struct {
+	// Zone whose limit would be exceeded
+	Zone string
+
+	// Limit of records in the zone
+	Limit int
+
+	// Count of records the zone would contain after the change
+	Count int
+}
+
+// Error returns a description of the exceeded limit
+func (e *RecordLimitError) Error() string {
+	return fmt.Sprintf("change would result in %d records in zone '%s', which exceeds the limit of %d records", e.Count, e.Zone, e.Limit)
+}
+
+// ZoneNotFoundError is returned if a zone is not managed by any domain of the account the API token belongs to
+type ZoneNotFoundError struct {
+	// Zone that was not found
+	Zone string
+
+	// CachedUntil point in time until which the result is cached, calls for the zone fail without listing
+	// the domains of the account again until then
+	CachedUntil time.Time
+}
+
+// Error returns a description of the zone that was not found
+func (e *ZoneNotFoundError) Error() string {
+	return fmt.Sprintf("could not find a domain name for zone %s in listed services", e.Zone)
+}
+
+// ErrNoRecords is returned by CheckMailSetup if the zone exists but has no records, as findings for an empty zone would
+// point to the wrong problem - all other operations return an empty slice in that case instead
+var ErrNoRecords = errors.New("zone has no records")
+
+// ErrZoneGone is matched by errors.Is for errors returned because the zone no longer exists
+var ErrZoneGone = errors.New("zone no longer exists")
+
+// ZoneGoneError is returned if the domain of a zone was deleted while the zone was in use, the cached domains
+// are reloaded so the zone can be resolved again once it was recreated
+type ZoneGoneError struct {
+	// Zone that no longer exists
+	Zone string
+
+	// Domain the zone was managed in
+	Domain string
+
+	// Err returned by the API
+	Err error
+}
+
+// Error returns a description of the deleted zone
+func (e *ZoneGoneError) Error() string {
+	return fmt.Sprintf("zone '%s' no longer exists, domain '%s' was deleted: %v", e.Zone, e.Domain, e.Err)
+}
+
+// Is returns if the target is ErrZoneGone
+func (e *ZoneGoneError) Is(target error) bool {
+	return target == ErrZoneGone
+}
+
+// Unwrap returns the error returned by the API
+func (e *ZoneGoneError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,173 @@
+// Package failover switches a DNS record between a primary and a backup target depending on the health of the primary target.
+package failover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+// Default interval between two health probes
+const defaultInterval = 30 * time.Second
+
+// Default number of consecutive probes after which the record is switched
+const defaultThreshold = 3
+
+// Default TTL in seconds of the record while it points to the primary target
+const defaultTtlSecs = 300
+
+// Default TTL in seconds of the record while it points to the backup target, kept low so that switching back propagates quickly
+const defaultFailoverTtlSecs = 60
+
+// Switcher points an A, AAAA or CNAME record to the primary target as long as it is healthy and to the backup target otherwise.
+// The record is only set when the target changes.
+type Switcher struct {
+	// Provider used to set the record, e.g. an *infomaniak.Provider
+	Provider libdns.RecordSetter
+
+	// Zone of the record
+	Zone string
+
+	// Name of the record relative to the zone
+	Name string
+
+	// Type of the record: "A", "AAAA" or "CNAME"
+	Type string
+
+	// Primary target the record points to while it is healthy
+	Primary string
+
+	// Backup target the record points to while the primary target is unhealthy
+	Backup string
+
+	// Probe returns an error if the given target is unhealthy
+	Probe func(ctx context.Context, target string) error
+
+	// Interval between two probes, 30 seconds if not set
+	Interval time.Duration
+
+	// FailureThreshold number of consecutive failed probes after which the record is switched to the backup target, 3 if not set
+	FailureThreshold int
+
+	// RecoveryThreshold number of consecutive successful probes after which the record is switched back to the primary target, 3 if not set
+	RecoveryThreshold int
+
+	// TtlInSec TTL in seconds of the record while it points to the primary target, 300 if not set
+	TtlInSec uint
+
+	// FailoverTtlInSec TTL in seconds of the record while it points to the backup target, 60 if not set
+	FailoverTtlInSec uint
+
+	// OnSwitch is called after the record was switched to a target if not nil
+	OnSwitch func(target string)
+
+	// target the record was last set to, empty before the first check
+	active string
+
+	// number of consecutive probes that contradict the active target
+	streak int
+
+	// mutex to prevent concurrent checks
+	mu sync.Mutex
+}
+
+// Run checks the primary target immediately and then in the configured interval until the context is done.
+// Errors of single checks are passed to onError if it is not nil.
+func (s *Switcher) Run(ctx context.Context, onError func(err error)) error {
+	interval := s.Interval
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := s.Check(ctx); err != nil && onError != nil && ctx.Err() == nil {
+			onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check probes the primary target once and switches the record if the threshold of consecutive probes is reached.
+// The first check sets the record to the primary target if it is healthy and to the backup target otherwise.
+func (s *Switcher) Check(ctx context.Context) error {
+	if s.Probe == nil {
+		return errors.New("no probe configured")
+	}
+	if s.Type != "A" && s.Type != "AAAA" && s.Type != "CNAME" {
+		return fmt.Errorf("unsupported record type '%s', expected A, AAAA or CNAME", s.Type)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	healthy := s.Probe(ctx, s.Primary) == nil
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	target := s.Backup
+	if healthy {
+		target = s.Primary
+	}
+
+	switch {
+	case s.active == "":
+		return s.switchTo(ctx, target)
+	case target == s.active:
+		s.streak = 0
+		return nil
+	}
+
+	s.streak++
+	threshold := s.RecoveryThreshold
+	if !healthy {
+		threshold = s.FailureThreshold
+	}
+	if threshold <= 0 {
+		threshold = defaultThreshold
+	}
+	if s.streak < threshold {
+		return nil
+	}
+	return s.switchTo(ctx, target)
+}
+
+// Active returns the target the record was last set to, or an empty string before the first check
+func (s *Switcher) Active() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.active
+}
+
+// switchTo sets the record to the given target with the TTL of the target
+func (s *Switcher) switchTo(ctx context.Context, target string) error {
+	ttl := s.TtlInSec
+	if ttl <= 0 {
+		ttl = defaultTtlSecs
+	}
+	if target != s.Primary {
+		ttl = s.FailoverTtlInSec
+		if ttl <= 0 {
+			ttl = defaultFailoverTtlSecs
+		}
+	}
+
+	record := libdns.Record{Type: s.Type, Name: s.Name, Value: target, TTL: time.Duration(ttl)}
+	_, err := s.Provider.SetRecords(ctx, s.Zone, []libdns.Record{record})
+	if err != nil {
+		return fmt.Errorf("could not switch %s record '%s' to '%s': %v", s.Type, s.Name, target, err)
+	}
+	s.active = target
+	s.streak = 0
+	if s.OnSwitch != nil {
+		s.OnSwitch(target)
+	}
+	return nil
+}
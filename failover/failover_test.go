@@ -0,0 +1,61 @@
+package failover
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/libdns/libdns"
+)
+
+// testSetter records the records passed to SetRecords
+type testSetter struct {
+	records []libdns.Record
+}
+
+// SetRecords implementation to fulfill libdns.RecordSetter interface
+func (s *testSetter) SetRecords(ctx context.Context, zone string, recs []libdns.Record) ([]libdns.Record, error) {
+	s.records = append(s.records, recs...)
+	return recs, nil
+}
+
+func Test_Check_SwitchesAfterThresholdIsReached(t *testing.T) {
+	healthy := true
+	setter := &testSetter{}
+	switcher := Switcher{
+		Provider: setter, Zone: "example.com", Name: "www", Type: "A", Primary: "192.0.2.1", Backup: "192.0.2.2",
+		FailureThreshold: 2, RecoveryThreshold: 1,
+		Probe: func(ctx context.Context, target string) error {
+			if !healthy {
+				return errors.New("unreachable")
+			}
+			return nil
+		},
+	}
+
+	check := func() {
+		if err := switcher.Check(context.TODO()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	check()
+	if switcher.Active() != "192.0.2.1" || len(setter.records) != 1 {
+		t.Fatalf("Expected record to be set to primary target, got %v", setter.records)
+	}
+
+	healthy = false
+	check()
+	if switcher.Active() != "192.0.2.1" {
+		t.Fatalf("Expected record to not be switched before threshold is reached")
+	}
+	check()
+	if switcher.Active() != "192.0.2.2" || setter.records[1].TTL != defaultFailoverTtlSecs {
+		t.Fatalf("Expected record to be switched to backup target with failover TTL, got %v", setter.records)
+	}
+
+	healthy = true
+	check()
+	if switcher.Active() != "192.0.2.1" || len(setter.records) != 3 {
+		t.Fatalf("Expected record to be switched back to primary target, got %v", setter.records)
+	}
+}
@@ -0,0 +1,278 @@
+package infomaniak
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// Fixture state of the account a FakeClient simulates, e.g. loaded from a JSON file with LoadFixture
+type Fixture struct {
+	// Domains of the account
+	Domains []IkDomain `json:"domains"`
+
+	// Records of all domains, each record belongs to the domain with the longest name it is part of
+	Records []IkRecord `json:"records"`
+}
+
+// LoadFixture reads a fixture from JSON and validates it, unknown fields are rejected so that typos in fixtures do not go unnoticed
+func LoadFixture(r io.Reader) (Fixture, error) {
+	var fixture Fixture
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&fixture); err != nil {
+		return Fixture{}, fmt.Errorf("could not decode fixture: %w", err)
+	}
+	return fixture, fixture.Validate()
+}
+
+// Validate checks that the domains have unique IDs and names and that every record has an unique ID,
+// a type, a target and a name that is part of one of the domains
+func (f Fixture) Validate() error {
+	domainIds := make(map[int]bool, len(f.Domains))
+	domainNames := make(map[string]bool, len(f.Domains))
+	for _, domain := range f.Domains {
+		if domain.Name == "" {
+			return fmt.Errorf("domain %d has no name", domain.ID)
+		}
+		if domainIds[domain.ID] || domainNames[normalizeName(domain.Name)] {
+			return fmt.Errorf("domain %d '%s' is not unique", domain.ID, domain.Name)
+		}
+		domainIds[domain.ID] = true
+		domainNames[normalizeName(domain.Name)] = true
+	}
+
+	recordIds := make(map[string]bool, len(f.Records))
+	for _, rec := range f.Records {
+		switch {
+		case rec.ID == "":
+			return fmt.Errorf("%s record '%s' has no ID", rec.Type, rec.SourceIdn)
+		case recordIds[rec.ID]:
+			return fmt.Errorf("record ID %s is not unique", rec.ID)
+		case rec.Type == "":
+			return fmt.Errorf("record %s has no type", rec.ID)
+		case rec.Target == "":
+			return fmt.Errorf("record %s has no target", rec.ID)
+		}
+		if _, ok := findDomainForZone(f.Domains, rec.SourceIdn); !ok {
+			return fmt.Errorf("record %s '%s' is not part of any domain", rec.ID, rec.SourceIdn)
+		}
+		recordIds[rec.ID] = true
+	}
+	return nil
+}
+
+// FakeClient implementation of IkClient that keeps the records of a fixture in memory instead of calling the API, e.g. for unit
+// tests of code that uses a Provider created with NewProviderWithClient. Zones are resolved to domains like by the API client
+// and errors are returned in the same form: *ZoneNotFoundError for unknown zones, *ApiError with HTTP 404 for unknown records
+// and HTTP 409 if an identical record already exists. Failures of the API can be injected with InjectFaults. It is safe for concurrent use.
+type FakeClient struct {
+	domains []IkDomain
+	records []IkRecord
+	nextId  int
+	faults  Faults
+	random  *rand.Rand
+	mu      sync.Mutex
+}
+
+// NewFakeClient returns a fake client whose account is in the state of the fixture, which is validated first
+func NewFakeClient(fixture Fixture) (*FakeClient, error) {
+	if err := fixture.Validate(); err != nil {
+		return nil, err
+	}
+	client := &FakeClient{domains: append([]IkDomain(nil), fixture.Domains...), records: append([]IkRecord(nil), fixture.Records...)}
+	for _, rec := range client.records {
+		if id, err := strconv.Atoi(rec.ID); err == nil && id > client.nextId {
+			client.nextId = id
+		}
+	}
+	return client, nil
+}
+
+// resolveZone returns the name of the domain the zone belongs to
+func (c *FakeClient) resolveZone(ctx context.Context, zone string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	domain, ok := findDomainForZone(c.domains, zone)
+	if !ok {
+		return "", &ZoneNotFoundError{Zone: zone}
+	}
+	return domain.Name, nil
+}
+
+// GetDnsRecordsForZone returns copies of the records of the given zone that belong to the zone's domain
+func (c *FakeClient) GetDnsRecordsForZone(ctx context.Context, zone string) ([]IkRecord, error) {
+	malformed, err := c.injectFault(ctx, true)
+	if err == nil {
+		err = malformed
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	domain, ok := findDomainForZone(c.domains, zone)
+	if !ok {
+		return nil, &ZoneNotFoundError{Zone: zone}
+	}
+	zoneRecords := make([]IkRecord, 0)
+	for _, rec := range c.records {
+		// like the API, only the records of the zone's domain are listed, not those of more specific domains
+		if isInZone(rec.SourceIdn, zone) && c.isRecordOfDomain(rec, domain) {
+			zoneRecords = append(zoneRecords, rec)
+		}
+	}
+	return zoneRecords, nil
+}
+
+// CreateOrUpdateRecord creates the record if it has no ID, otherwise it replaces the record with the given ID
+func (c *FakeClient) CreateOrUpdateRecord(ctx context.Context, zone string, record IkRecord) (*IkRecord, error) {
+	malformed, err := c.injectFault(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	rec, err := c.createOrUpdateRecord(zone, record)
+	if err == nil && malformed != nil {
+		return nil, malformed
+	}
+	return rec, err
+}
+
+// createOrUpdateRecord creates or replaces the record
+func (c *FakeClient) createOrUpdateRecord(zone string, record IkRecord) (*IkRecord, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	domain, ok := findDomainForZone(c.domains, zone)
+	if !ok {
+		return nil, &ZoneNotFoundError{Zone: zone}
+	}
+	if !isInZone(record.SourceIdn, zone) || !c.isRecordOfDomain(record, domain) {
+		return nil, newApiError(http.StatusUnprocessableEntity, nil, json.RawMessage(`{"code":"validation_rule_record_dns_source"}`))
+	}
+
+	if record.ID != "" {
+		index := c.indexOf(record.ID)
+		if index < 0 {
+			return nil, newApiError(http.StatusNotFound, nil, json.RawMessage(`{"code":"object_not_found"}`))
+		}
+		c.records[index] = record
+		return &record, nil
+	}
+
+	for _, existingRec := range c.records {
+		if normalizeName(existingRec.SourceIdn) == normalizeName(record.SourceIdn) && normalizeType(existingRec.Type) == normalizeType(record.Type) && existingRec.Target == record.Target {
+			return nil, newApiError(http.StatusConflict, nil, json.RawMessage(`{"code":"record_already_exists"}`))
+		}
+	}
+	c.nextId++
+	record.ID = strconv.Itoa(c.nextId)
+	c.records = append(c.records, record)
+	return &record, nil
+}
+
+// PatchRecord updates the attributes of the record with the given ID that are set in changes
+func (c *FakeClient) PatchRecord(ctx context.Context, zone string, id string, changes IkRecordPatch) error {
+	malformed, err := c.injectFault(ctx, false)
+	if err != nil {
+		return err
+	}
+	err = c.patchRecord(zone, id, changes)
+	if err == nil {
+		err = malformed
+	}
+	return err
+}
+
+// patchRecord updates the attributes of the record
+func (c *FakeClient) patchRecord(zone string, id string, changes IkRecordPatch) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := findDomainForZone(c.domains, zone); !ok {
+		return &ZoneNotFoundError{Zone: zone}
+	}
+	index := c.indexOf(id)
+	if index < 0 {
+		return newApiError(http.StatusNotFound, nil, json.RawMessage(`{"code":"object_not_found"}`))
+	}
+	if changes.Target != nil {
+		c.records[index].Target = *changes.Target
+	}
+	if changes.TtlInSec != nil {
+		c.records[index].TtlInSec = *changes.TtlInSec
+	}
+	return nil
+}
+
+// DeleteRecord deletes the record with the given ID
+func (c *FakeClient) DeleteRecord(ctx context.Context, zone string, id string) error {
+	malformed, err := c.injectFault(ctx, false)
+	if err != nil {
+		return err
+	}
+	err = c.deleteRecord(zone, id)
+	if err == nil {
+		err = malformed
+	}
+	return err
+}
+
+// deleteRecord deletes the record
+func (c *FakeClient) deleteRecord(zone string, id string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := findDomainForZone(c.domains, zone); !ok {
+		return &ZoneNotFoundError{Zone: zone}
+	}
+	index := c.indexOf(id)
+	if index < 0 {
+		return newApiError(http.StatusNotFound, nil, json.RawMessage(`{"code":"object_not_found"}`))
+	}
+	c.records = append(c.records[:index], c.records[index+1:]...)
+	return nil
+}
+
+// Fixture returns the current state of the account, e.g. to compare it with an expected fixture
+func (c *FakeClient) Fixture() Fixture {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return Fixture{Domains: append([]IkDomain(nil), c.domains...), Records: append([]IkRecord(nil), c.records...)}
+}
+
+// hasDomain returns if the account has a domain with the given name, the caller must hold the client's mutex
+func (c *FakeClient) hasDomain(name string) bool {
+	for _, domain := range c.domains {
+		if normalizeName(domain.Name) == normalizeName(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// isRecordOfDomain returns if the record belongs to the domain, which is the domain with the longest name the record is
+// part of - the caller must hold the client's mutex
+func (c *FakeClient) isRecordOfDomain(rec IkRecord, domain IkDomain) bool {
+	recDomain, ok := findDomainForZone(c.domains, rec.SourceIdn)
+	return ok && recDomain.ID == domain.ID
+}
+
+// indexOf returns the index of the record with the given ID or -1, the caller must hold the client's mutex
+func (c *FakeClient) indexOf(id string) int {
+	for i, rec := range c.records {
+		if rec.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// Interface guards
+var (
+	_ IkClient        = (*FakeClient)(nil)
+	_ IkRecordPatcher = (*FakeClient)(nil)
+	_ zoneResolver    = (*FakeClient)(nil)
+)
@@ -0,0 +1,118 @@
+package infomaniak
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/libdns/libdns"
+)
+
+// Fixture of an account with two domains used by the tests of the fake client
+const testFixture = `{
+	"domains": [{"id": 1, "customer_name": "example.com"}, {"id": 2, "customer_name": "example.org"}],
+	"records": [
+		{"id": "10", "type": "A", "source_idn": "www.example.com", "target": "127.0.0.1", "ttl": 300},
+		{"id": "11", "type": "TXT", "source_idn": "_acme-challenge.sub.example.com", "target": "\"token\"", "ttl": 300},
+		{"id": "20", "type": "MX", "source_idn": "example.org", "target": "mail.example.org", "ttl": 3600, "priority": 10}
+	]
+}`
+
+// newFakeTestProvider returns a provider whose client is a fake client in the state of the test fixture
+func newFakeTestProvider(t *testing.T) (*Provider, *FakeClient) {
+	fixture, err := LoadFixture(strings.NewReader(testFixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewFakeClient(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewProviderWithClient(client), client
+}
+
+func Test_LoadFixture_RejectsInvalidFixtures(t *testing.T) {
+	for name, fixture := range map[string]string{
+		"unknown field":     `{"domains": [], "zones": []}`,
+		"duplicate domain":  `{"domains": [{"id": 1, "customer_name": "example.com"}, {"id": 1, "customer_name": "example.org"}]}`,
+		"record without id": `{"domains": [{"id": 1, "customer_name": "example.com"}], "records": [{"type": "A", "source_idn": "example.com", "target": "127.0.0.1"}]}`,
+		"foreign record":    `{"domains": [{"id": 1, "customer_name": "example.com"}], "records": [{"id": "1", "type": "A", "source_idn": "example.net", "target": "127.0.0.1"}]}`,
+	} {
+		if _, err := LoadFixture(strings.NewReader(fixture)); err == nil {
+			t.Fatalf("Expected error for fixture with %s", name)
+		}
+	}
+}
+
+func Test_FakeClient_ReturnsRecordsOfSubzone(t *testing.T) {
+	provider, _ := newFakeTestProvider(t)
+
+	recs, err := provider.GetRecords(context.TODO(), "sub.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "number of records", 1, len(recs))
+	assertEquals(t, "value", "token", recs[0].Value)
+}
+
+func Test_FakeClient_DoesNotReturnRecordsOfNestedDomain(t *testing.T) {
+	client, err := NewFakeClient(Fixture{
+		Domains: []IkDomain{{ID: 1, Name: "example.com"}, {ID: 2, Name: "sub.example.com"}},
+		Records: []IkRecord{
+			{ID: "1", Type: "A", SourceIdn: "www.example.com", Target: "127.0.0.1"},
+			{ID: "2", Type: "A", SourceIdn: "www.sub.example.com", Target: "127.0.0.2"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recs, err := client.GetDnsRecordsForZone(context.TODO(), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "number of records", 1, len(recs))
+	assertEquals(t, "ID", "1", recs[0].ID)
+}
+
+func Test_FakeClient_ReturnsZoneNotFoundError(t *testing.T) {
+	provider, _ := newFakeTestProvider(t)
+
+	_, err := provider.GetRecords(context.TODO(), "example.net")
+	var notFoundErr *ZoneNotFoundError
+	if !errors.As(err, &notFoundErr) {
+		t.Fatalf("Expected ZoneNotFoundError, got %v", err)
+	}
+}
+
+func Test_FakeClient_AppliesChangesOfProvider(t *testing.T) {
+	provider, client := newFakeTestProvider(t)
+
+	_, err := provider.SetRecords(context.TODO(), "example.com", []libdns.Record{{Type: "A", Name: "www", Value: "127.0.0.2", TTL: 300}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	created, err := provider.AppendRecords(context.TODO(), "example.com", []libdns.Record{{Type: "AAAA", Name: "www", Value: "::1", TTL: 300}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = provider.DeleteRecords(context.TODO(), "example.org", []libdns.Record{{Type: "MX", Name: "@"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixture := client.Fixture()
+	assertEqualsInt(t, "number of records", 3, len(fixture.Records))
+	assertEquals(t, "updated value", "127.0.0.2", fixture.Records[0].Target)
+	assertEquals(t, "ID of created record", "21", created[0].ID)
+}
+
+func Test_FakeClient_ReturnsConflictForIdenticalRecord(t *testing.T) {
+	_, client := newFakeTestProvider(t)
+
+	_, err := client.CreateOrUpdateRecord(context.TODO(), "example.com", IkRecord{Type: "A", SourceIdn: "www.example.com", Target: "127.0.0.1"})
+	if !isConflict(err) {
+		t.Fatalf("Expected conflict, got %v", err)
+	}
+}
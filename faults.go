@@ -0,0 +1,81 @@
+package infomaniak
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Faults failures a FakeClient injects into its calls, so that retry and error handling can be tested against the failures of
+// the infomaniak API. Rates are probabilities between 0 and 1 that are drawn independently for every call.
+type Faults struct {
+	// rate of calls that fail with an *ApiError with HTTP 500 as returned for internal errors of the API
+	ServerErrorRate float64
+
+	// rate of record listings that fail with an *ApiError with HTTP 500 while loading a later page - like the API client,
+	// the fake then returns none of the records of the pages that were already loaded
+	PaginationFailureRate float64
+
+	// rate of calls whose response is malformed JSON, they fail with the error returned by StdCodec for a truncated body -
+	// writes are applied before the response is lost
+	MalformedResponseRate float64
+
+	// delay added to every call, calls return the context's error if the context is done before
+	Latency time.Duration
+
+	// maximum random delay added to Latency
+	LatencyJitter time.Duration
+
+	// seed of the random numbers that decide which calls fail, so that test runs are reproducible
+	Seed int64
+}
+
+// Truncated response of a record listing that is decoded to return the error of malformed JSON
+const malformedResponse = `{"result":"success","data":[{"id":"1","type":"A","source":`
+
+// InjectFaults lets the following calls of the fake fail according to faults, zero Faults stop the injection
+func (c *FakeClient) InjectFaults(faults Faults) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.faults = faults
+	c.random = rand.New(rand.NewSource(faults.Seed))
+}
+
+// injectFault delays the call and returns the error of a fault if one is drawn for it, the fault of a malformed
+// response is returned separately as it is only returned after writes were applied
+func (c *FakeClient) injectFault(ctx context.Context, listing bool) (malformed error, err error) {
+	c.mu.Lock()
+	faults := c.faults
+	var delay time.Duration
+	var serverError, paginationFailure, malformedResp bool
+	if c.random != nil {
+		delay = faults.Latency
+		if faults.LatencyJitter > 0 {
+			delay += time.Duration(c.random.Int63n(int64(faults.LatencyJitter) + 1))
+		}
+		serverError = c.random.Float64() < faults.ServerErrorRate
+		paginationFailure = listing && c.random.Float64() < faults.PaginationFailureRate
+		malformedResp = c.random.Float64() < faults.MalformedResponseRate
+	}
+	c.mu.Unlock()
+
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+	if serverError || paginationFailure {
+		return nil, newApiError(http.StatusInternalServerError, nil, json.RawMessage(`{"code":"internal_error"}`))
+	}
+	if malformedResp {
+		return StdCodec{}.Decode(strings.NewReader(malformedResponse), &IkResponse{}), nil
+	}
+	return nil, nil
+}
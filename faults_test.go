@@ -0,0 +1,92 @@
+package infomaniak
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func newFaultyFakeClient(t *testing.T, faults Faults) *FakeClient {
+	client, err := NewFakeClient(Fixture{
+		Domains: []IkDomain{{ID: 1, Name: "example.com"}},
+		Records: []IkRecord{{ID: "1", Type: "A", SourceIdn: "www.example.com", Target: "1.2.3.4", TtlInSec: 300}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.InjectFaults(faults)
+	return client
+}
+
+func Test_FakeClient_InjectsServerErrors(t *testing.T) {
+	client := newFaultyFakeClient(t, Faults{ServerErrorRate: 1})
+
+	err := client.DeleteRecord(context.TODO(), "example.com", "1")
+	var apiErr *ApiError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("Expected *ApiError with HTTP 500, got %v", err)
+	}
+	assertEqualsInt(t, "records", 1, len(client.Fixture().Records))
+
+	client.InjectFaults(Faults{})
+	err = client.DeleteRecord(context.TODO(), "example.com", "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_FakeClient_InjectsPaginationFailuresOnlyIntoListings(t *testing.T) {
+	client := newFaultyFakeClient(t, Faults{PaginationFailureRate: 1})
+
+	records, err := client.GetDnsRecordsForZone(context.TODO(), "example.com")
+	var apiErr *ApiError
+	if !errors.As(err, &apiErr) || records != nil {
+		t.Fatalf("Expected *ApiError without records, got %v and %#v", err, records)
+	}
+	_, err = client.CreateOrUpdateRecord(context.TODO(), "example.com", IkRecord{Type: "A", SourceIdn: "api.example.com", Target: "1.2.3.5", TtlInSec: 300})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_FakeClient_AppliesWritesWithMalformedResponse(t *testing.T) {
+	client := newFaultyFakeClient(t, Faults{MalformedResponseRate: 1})
+
+	_, err := client.CreateOrUpdateRecord(context.TODO(), "example.com", IkRecord{Type: "A", SourceIdn: "api.example.com", Target: "1.2.3.5", TtlInSec: 300})
+	if err == nil {
+		t.Fatalf("Expected decoding error")
+	}
+	assertEqualsInt(t, "records", 2, len(client.Fixture().Records))
+}
+
+func Test_FakeClient_ReturnsContextErrorDuringLatency(t *testing.T) {
+	client := newFaultyFakeClient(t, Faults{Latency: time.Minute})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := client.GetDnsRecordsForZone(ctx, "example.com")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected deadline exceeded, got %v", err)
+	}
+}
+
+func Test_FakeClient_InjectsSameFaultsForSameSeed(t *testing.T) {
+	failures := func() []bool {
+		client := newFaultyFakeClient(t, Faults{ServerErrorRate: 0.5, Seed: 42})
+		failed := make([]bool, 20)
+		for i := range failed {
+			_, err := client.GetDnsRecordsForZone(context.TODO(), "example.com")
+			failed[i] = err != nil
+		}
+		return failed
+	}
+
+	first, second := failures(), failures()
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("Expected same faults for same seed, call %d differs", i)
+		}
+	}
+}
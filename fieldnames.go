@@ -0,0 +1,111 @@
+package infomaniak
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// FieldNames names of record fields used by a revision of the infomaniak API, keyed by the names used by the current
+// revision, e.g. {"source": "name"} if a revision renamed the field "source" to "name" - fields that are not listed keep their name
+type FieldNames map[string]string
+
+// Names of record fields used by other revisions of the API, by the names used by the current revision
+var recordFieldAliases = map[string][]string{
+	"source":     {"name"},
+	"source_idn": {"sourceIdn", "fqdn"},
+	"target":     {"value", "content"},
+	"updated_at": {"updatedAt"},
+	"dyndns_id":  {"dyndnsId"},
+}
+
+// detectFieldNames returns the names of the record fields that are used by the API revision that returned the raw record,
+// only fields whose name differs from the current revision are returned
+func detectFieldNames(rawRecord json.RawMessage) FieldNames {
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(rawRecord, &fields) != nil {
+		return FieldNames{}
+	}
+	names := FieldNames{}
+	for name, aliases := range recordFieldAliases {
+		if _, ok := fields[name]; ok {
+			continue
+		}
+		for _, alias := range aliases {
+			if _, ok := fields[alias]; ok {
+				names[name] = alias
+				break
+			}
+		}
+	}
+	return names
+}
+
+// inverse returns the names of the current revision by the names of fields used by the other revision
+func (n FieldNames) inverse() FieldNames {
+	inverse := make(FieldNames, len(n))
+	for name, alias := range n {
+		inverse[alias] = name
+	}
+	return inverse
+}
+
+// renameFields returns the raw JSON object with its fields renamed according to names in a single pass over its fields,
+// the raw value is returned as it is if no field needs to be renamed
+func renameFields(raw json.RawMessage, names FieldNames) (json.RawMessage, error) {
+	if len(names) == 0 {
+		return raw, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil, fmt.Errorf("expected JSON object, got %.20s", string(raw))
+	}
+	renamed := bytes.NewBuffer(make([]byte, 0, len(raw)+16))
+	renamed.WriteByte('{')
+	seen := make(map[string]bool, len(names))
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		name, _ := token.(string)
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+		if newName, ok := names[name]; ok {
+			name = newName
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		if renamed.Len() > 1 {
+			renamed.WriteByte(',')
+		}
+		rawName, err := json.Marshal(name)
+		if err != nil {
+			return nil, err
+		}
+		renamed.Write(rawName)
+		renamed.WriteByte(':')
+		renamed.Write(value)
+	}
+	renamed.WriteByte('}')
+	return renamed.Bytes(), nil
+}
+
+// getFieldNames returns the names of the record fields used by the API, either as configured or as detected from the
+// first listed record - the names are detected once per client, before the first record was listed the names of the
+// current revision are used
+func (c *Client) getFieldNames(rawRecord json.RawMessage) FieldNames {
+	if c.FieldNames != nil {
+		return c.FieldNames
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.detectedFieldNames == nil && len(rawRecord) > 0 {
+		c.detectedFieldNames = detectFieldNames(rawRecord)
+	}
+	return c.detectedFieldNames
+}
@@ -0,0 +1,76 @@
+package infomaniak
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func Test_DetectFieldNames_ReturnsRenamedFields(t *testing.T) {
+	names := detectFieldNames(json.RawMessage(`{"id":"1","type":"A","name":"www","fqdn":"www.example.com","content":"1.2.3.4","ttl":300}`))
+	assertEqualsInt(t, "renamed fields", 3, len(names))
+	assertEquals(t, "source", "name", names["source"])
+	assertEquals(t, "source_idn", "fqdn", names["source_idn"])
+	assertEquals(t, "target", "content", names["target"])
+
+	names = detectFieldNames(json.RawMessage(`{"id":"1","type":"A","source":"www","source_idn":"www.example.com","target":"1.2.3.4","ttl":300}`))
+	assertEqualsInt(t, "renamed fields", 0, len(names))
+}
+
+func Test_GetDnsRecordsForZone_DecodesRecordsWithRenamedFields(t *testing.T) {
+	client := newTestClient(`[{"id":"1","type":"A","name":"www","content":"1.2.3.4","ttl":300}]`, &[]IkDomain{{Name: "example.com", ID: 100}})
+
+	records, err := client.GetDnsRecordsForZone(context.TODO(), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "records", 1, len(records))
+	assertEquals(t, "SourceIdn", "www.example.com", records[0].SourceIdn)
+	assertEquals(t, "Target", "1.2.3.4", records[0].Target)
+}
+
+func Test_CreateOrUpdateRecord_SendsDetectedFieldNames(t *testing.T) {
+	var sentBody string
+	httpClient := newHttpTestClient(func(req *http.Request) *http.Response {
+		if req.Method == http.MethodGet {
+			return &http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{"result":"success","data":[{"id":"1","type":"A","name":"www","content":"1.2.3.4","ttl":300}]}`)),
+				Header:     make(http.Header),
+			}
+		}
+		body, _ := ioutil.ReadAll(req.Body)
+		sentBody = string(body)
+		return anIdResponse("2")
+	})
+	client := Client{domains: &[]IkDomain{{Name: "example.com", ID: 100}}, HttpClient: httpClient}
+
+	_, err := client.GetDnsRecordsForZone(context.TODO(), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.CreateOrUpdateRecord(context.TODO(), "example.com", IkRecord{Type: "A", SourceIdn: "api.example.com", Target: "1.2.3.5", TtlInSec: 300})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "body", `{"type":"A","name":"api","source_idn":"api.example.com","content":"1.2.3.5","ttl":300}`, sentBody)
+}
+
+func Test_CreateOrUpdateRecord_SendsConfiguredFieldNames(t *testing.T) {
+	var sentBody string
+	httpClient := newHttpTestClient(func(req *http.Request) *http.Response {
+		body, _ := ioutil.ReadAll(req.Body)
+		sentBody = string(body)
+		return anIdResponse("2")
+	})
+	client := Client{domains: &[]IkDomain{{Name: "example.com", ID: 100}}, HttpClient: httpClient, FieldNames: FieldNames{"target": "value"}}
+
+	_, err := client.CreateOrUpdateRecord(context.TODO(), "example.com", IkRecord{Type: "A", SourceIdn: "example.com", Target: "1.2.3.5", TtlInSec: 300})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "body", `{"type":"A","source":".","source_idn":"example.com","value":"1.2.3.5","ttl":300}`, sentBody)
+}
@@ -0,0 +1,18 @@
+package infomaniak
+
+// NewInMemoryProvider returns a provider whose records are kept in memory by a FakeClient instead of being managed by infomaniak,
+// e.g. for local development and tests of applications without network access. The given domains are the domains of the
+// simulated account, zones are mapped to them and records are normalized, matched and validated like by a provider that calls
+// the API - zones that are not part of any of the domains are not found. The options of the returned provider can be set as
+// usual, except for the options of the API client and its HTTP connections.
+func NewInMemoryProvider(domains ...string) *Provider {
+	client := &FakeClient{}
+	for _, name := range domains {
+		name = getWithoutTrailingDot(name)
+		if name == "" || client.hasDomain(name) {
+			continue
+		}
+		client.domains = append(client.domains, IkDomain{ID: len(client.domains) + 1, Name: name})
+	}
+	return NewProviderWithClient(client)
+}
@@ -0,0 +1,71 @@
+package infomaniak
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/libdns/libdns"
+)
+
+func Test_InMemoryProvider_ManagesRecordsOfSubzones(t *testing.T) {
+	provider := NewInMemoryProvider("example.com.", "example.com", "example.org")
+
+	appended, err := provider.AppendRecords(context.TODO(), "dev.example.com.", []libdns.Record{{Type: "TXT", Name: "_acme-challenge", Value: "token", TTL: 300}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "appended records", 1, len(appended))
+
+	records, err := provider.GetRecords(context.TODO(), "example.com.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "records", 1, len(records))
+	assertEquals(t, "name", "_acme-challenge.dev", records[0].Name)
+
+	_, err = provider.SetRecords(context.TODO(), "dev.example.com.", []libdns.Record{{ID: appended[0].ID, Type: "TXT", Name: "_acme-challenge", Value: "other", TTL: 300}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err = provider.GetRecords(context.TODO(), "dev.example.com.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "records", 1, len(records))
+	assertEquals(t, "value", "other", records[0].Value)
+
+	_, err = provider.DeleteRecords(context.TODO(), "dev.example.com.", records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err = provider.GetRecords(context.TODO(), "example.com.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "records", 0, len(records))
+}
+
+func Test_InMemoryProvider_KeepsRecordsOfNestedDomainsApart(t *testing.T) {
+	provider := NewInMemoryProvider("example.com", "sub.example.com")
+
+	_, err := provider.AppendRecords(context.TODO(), "sub.example.com", []libdns.Record{{Type: "A", Name: "www", Value: "127.0.0.1", TTL: 300}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := provider.GetRecords(context.TODO(), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "records of parent domain", 0, len(records))
+}
+
+func Test_InMemoryProvider_ReturnsErrorForUnknownZone(t *testing.T) {
+	provider := NewInMemoryProvider("example.com")
+
+	_, err := provider.GetRecords(context.TODO(), "example.net.")
+	var zoneErr *ZoneNotFoundError
+	if !errors.As(err, &zoneErr) {
+		t.Fatalf("Expected *ZoneNotFoundError, got %v", err)
+	}
+}
@@ -0,0 +1,196 @@
+package infomaniak
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+// Default maximum age of journal entries that are applied again by RecoverJournal
+const defaultJournalMaxAge = time.Hour
+
+// JournalEntry operation that was started but not yet completed
+type JournalEntry struct {
+	// ID of the entry, unique within the journal
+	ID string `json:"id"`
+
+	// Time the operation was started
+	Time time.Time `json:"time"`
+
+	// Operation that was started: "set" or "delete"
+	Operation string `json:"operation"`
+
+	// Zone the operation changes
+	Zone string `json:"zone"`
+
+	// Records passed to the operation
+	Records []libdns.Record `json:"records"`
+}
+
+// Journal persists the operations that are in flight, so that operations interrupted by a crash of the process can be
+// completed after a restart with RecoverJournal
+type Journal interface {
+	// Begin records that the operation was started
+	Begin(entry JournalEntry) error
+
+	// Complete removes the entry with the given ID once its operation returned
+	Complete(id string) error
+
+	// Pending returns the entries of all operations that were started but not completed, oldest first
+	Pending() ([]JournalEntry, error)
+}
+
+// FileJournal stores each operation in flight as JSON file in a directory
+type FileJournal struct {
+	// Directory the entries are written to
+	Dir string
+}
+
+// Begin writes the entry to its file
+func (j *FileJournal) Begin(entry JournalEntry) error {
+	rawJson, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(j.Dir, 0700)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomically(j.path(entry.ID), rawJson)
+}
+
+// Complete deletes the file of the entry
+func (j *FileJournal) Complete(id string) error {
+	err := os.Remove(j.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// Pending reads the entries of all files in the directory, no entries are returned if the directory does not exist
+func (j *FileJournal) Pending() ([]JournalEntry, error) {
+	files, err := ioutil.ReadDir(j.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	entries := make([]JournalEntry, 0)
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		rawJson, err := ioutil.ReadFile(filepath.Join(j.Dir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var entry JournalEntry
+		err = json.Unmarshal(rawJson, &entry)
+		if err != nil {
+			return nil, fmt.Errorf("could not decode journal entry %s: %v", file.Name(), err)
+		}
+		entries = append(entries, entry)
+	}
+	sort.SliceStable(entries, func(i, k int) bool {
+		return entries[i].Time.Before(entries[k].Time)
+	})
+	return entries, nil
+}
+
+// path returns the path of the file the entry with the given ID is stored in
+func (j *FileJournal) path(id string) string {
+	return filepath.Join(j.Dir, filepath.Base(id)+".json")
+}
+
+// withJournal records the operation in the journal while it is applied, if a journal is configured.
+// The entry is completed once the operation returns, also if it failed, as the caller is informed then.
+func (p *Provider) withJournal(zone string, operation string, records []libdns.Record, apply func() ([]libdns.Record, error)) ([]libdns.Record, error) {
+	if p.Journal == nil {
+		return apply()
+	}
+
+	id, err := newJournalId()
+	if err != nil {
+		return nil, err
+	}
+	err = p.Journal.Begin(JournalEntry{ID: id, Time: time.Now(), Operation: operation, Zone: zone, Records: records})
+	if err != nil {
+		return nil, fmt.Errorf("could not record %s operation in journal: %w", operation, err)
+	}
+	changedRecs, err := apply()
+	if completeErr := p.Journal.Complete(id); completeErr != nil && err == nil {
+		return changedRecs, fmt.Errorf("could not complete %s operation in journal: %w", operation, completeErr)
+	}
+	return changedRecs, err
+}
+
+// RecoverJournal completes the operations that were started but not completed according to the Journal, e.g. because
+// the process crashed while SetRecords was applied, so that zones are not left half-updated. As SetRecords and
+// DeleteRecords are idempotent, the operations are simply applied again. Operations are only completed, never rolled
+// back, as the journal does not record the previous state of the records. It returns the completed entries.
+//
+// Applying an operation again overwrites changes made to its records since it was started, e.g. a value set by
+// another process would be reverted. Therefore only entries younger than JournalMaxAge are applied, older entries
+// are kept in the journal, so they can be inspected with Journal.Pending and removed with Journal.Complete.
+func (p *Provider) RecoverJournal(ctx context.Context) ([]JournalEntry, error) {
+	if p.Journal == nil {
+		return nil, errors.New("no journal configured")
+	}
+	entries, err := p.Journal.Pending()
+	if err != nil {
+		return nil, err
+	}
+	maxAge := p.JournalMaxAge
+	if maxAge <= 0 {
+		maxAge = defaultJournalMaxAge
+	}
+
+	recovered := make([]JournalEntry, 0, len(entries))
+	for _, entry := range entries {
+		if time.Since(entry.Time) > maxAge {
+			continue
+		}
+		switch entry.Operation {
+		case "set":
+			_, err = p.SetRecords(ctx, entry.Zone, entry.Records)
+		case "delete":
+			_, err = p.DeleteRecords(ctx, entry.Zone, entry.Records)
+		default:
+			err = fmt.Errorf("unknown operation '%s'", entry.Operation)
+		}
+		if err != nil {
+			return recovered, fmt.Errorf("could not recover %s operation %s of zone '%s': %w", entry.Operation, entry.ID, entry.Zone, err)
+		}
+		if err := p.Journal.Complete(entry.ID); err != nil {
+			return recovered, err
+		}
+		recovered = append(recovered, entry)
+	}
+	return recovered, nil
+}
+
+// newJournalId returns a random ID of a journal entry
+func newJournalId() (string, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
+
+// Interface guards
+var (
+	_ Journal = (*FileJournal)(nil)
+)
@@ -0,0 +1,109 @@
+package infomaniak
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+func Test_FileJournal_StoresPendingEntriesUntilCompleted(t *testing.T) {
+	journal := &FileJournal{Dir: t.TempDir()}
+	err := journal.Begin(JournalEntry{ID: "b", Time: time.Now(), Operation: "delete", Zone: "example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = journal.Begin(JournalEntry{ID: "a", Time: time.Now().Add(-time.Minute), Operation: "set", Zone: "example.com", Records: []libdns.Record{{Type: "A", Name: "www", Value: "127.0.0.1"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := journal.Pending()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "number of entries", 2, len(entries))
+	assertEquals(t, "oldest entry", "a", entries[0].ID)
+	assertEquals(t, "value", "127.0.0.1", entries[0].Records[0].Value)
+
+	journal.Complete("a")
+	entries, _ = journal.Pending()
+	assertEqualsInt(t, "number of entries", 1, len(entries))
+}
+
+func Test_SetRecords_CompletesJournalEntry(t *testing.T) {
+	journal := &FileJournal{Dir: t.TempDir()}
+	inFlight := 0
+	client := TestClient{setter: func(ctx context.Context, zone string, record IkRecord) (*IkRecord, error) {
+		entries, _ := journal.Pending()
+		inFlight = len(entries)
+		return &IkRecord{ID: "1"}, nil
+	}}
+	provider := Provider{client: &client, Journal: journal}
+
+	_, err := provider.SetRecords(context.TODO(), "example.com", []libdns.Record{{Type: "A", Name: "www", Value: "127.0.0.1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "entries in flight", 1, inFlight)
+	entries, _ := journal.Pending()
+	assertEqualsInt(t, "entries after operation", 0, len(entries))
+}
+
+func Test_RecoverJournal_AppliesInterruptedOperationsAgain(t *testing.T) {
+	journal := &FileJournal{Dir: t.TempDir()}
+	journal.Begin(JournalEntry{ID: "1", Time: time.Now(), Operation: "set", Zone: "example.com", Records: []libdns.Record{{Type: "A", Name: "www", Value: "127.0.0.1"}}})
+
+	set := make([]IkRecord, 0)
+	client := TestClient{setter: func(ctx context.Context, zone string, record IkRecord) (*IkRecord, error) {
+		set = append(set, record)
+		return &IkRecord{ID: "1"}, nil
+	}}
+	provider := Provider{client: &client, Journal: journal}
+
+	recovered, err := provider.RecoverJournal(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "recovered entries", 1, len(recovered))
+	assertEqualsInt(t, "set records", 1, len(set))
+	assertEquals(t, "name", "www.example.com", set[0].SourceIdn)
+	entries, _ := journal.Pending()
+	assertEqualsInt(t, "pending entries", 0, len(entries))
+}
+
+func Test_RecoverJournal_KeepsEntryIfOperationFails(t *testing.T) {
+	journal := &FileJournal{Dir: t.TempDir()}
+	journal.Begin(JournalEntry{ID: "1", Time: time.Now(), Operation: "set", Zone: "example.com", Records: []libdns.Record{{Type: "A", Name: "www", Value: "127.0.0.1"}}})
+	client := TestClient{setter: func(ctx context.Context, zone string, record IkRecord) (*IkRecord, error) {
+		return nil, errors.New("API not available")
+	}}
+	provider := Provider{client: &client, Journal: journal}
+
+	_, err := provider.RecoverJournal(context.TODO())
+	if err == nil {
+		t.Fatal("Expected error if operation cannot be recovered")
+	}
+	entries, _ := journal.Pending()
+	assertEqualsInt(t, "pending entries", 1, len(entries))
+}
+
+func Test_RecoverJournal_KeepsEntriesOlderThanMaxAge(t *testing.T) {
+	journal := &FileJournal{Dir: t.TempDir()}
+	journal.Begin(JournalEntry{ID: "1", Time: time.Now().Add(-2 * time.Hour), Operation: "set", Zone: "example.com", Records: []libdns.Record{{Type: "A", Name: "www", Value: "127.0.0.1"}}})
+	client := TestClient{setter: func(ctx context.Context, zone string, record IkRecord) (*IkRecord, error) {
+		t.Fatalf("Expected old entry not to be applied again")
+		return nil, nil
+	}}
+	provider := Provider{client: &client, Journal: journal}
+
+	recovered, err := provider.RecoverJournal(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "recovered entries", 0, len(recovered))
+	entries, _ := journal.Pending()
+	assertEqualsInt(t, "pending entries", 1, len(entries))
+}
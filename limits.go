@@ -0,0 +1,23 @@
+package infomaniak
+
+import (
+	"context"
+	"errors"
+)
+
+// RemainingCapacity returns how many records can still be added to the zone before MaxRecordsPerZone is reached,
+// an error is returned if no limit is configured
+func (p *Provider) RemainingCapacity(ctx context.Context, zone string) (int, error) {
+	if p.MaxRecordsPerZone <= 0 {
+		return 0, errors.New("no record limit configured, set MaxRecordsPerZone")
+	}
+	records, err := p.getRecords(ctx, getWithoutTrailingDot(zone))
+	if err != nil {
+		return 0, err
+	}
+	remaining := p.MaxRecordsPerZone - len(records)
+	if remaining < 0 {
+		return 0, nil
+	}
+	return remaining, nil
+}
@@ -0,0 +1,61 @@
+package infomaniak
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/libdns/libdns"
+)
+
+// aZoneWithRecords returns a client whose zone contains the given number of records and that accepts new records
+func aZoneWithRecords(count int) *TestClient {
+	client := aSyntheticZone(count)
+	client.setter = func(ctx context.Context, zone string, record IkRecord) (*IkRecord, error) {
+		record.ID = "new"
+		return &record, nil
+	}
+	return client
+}
+
+func Test_AppendRecords_RejectsChangeExceedingRecordLimit(t *testing.T) {
+	provider := Provider{client: aZoneWithRecords(3), MaxRecordsPerZone: 4}
+
+	_, err := provider.AppendRecords(context.TODO(), "example.com.", []libdns.Record{
+		{Name: "new1", Type: "A", Value: "127.0.0.1"},
+		{Name: "new2", Type: "A", Value: "127.0.0.2"},
+	})
+	var limitErr *RecordLimitError
+	if !errors.As(err, &limitErr) {
+		t.Fatalf("Expected *RecordLimitError, got %v", err)
+	}
+	assertEqualsInt(t, "Count", 5, limitErr.Count)
+}
+
+func Test_AppendRecords_AcceptsChangeWithinRecordLimit(t *testing.T) {
+	provider := Provider{client: aZoneWithRecords(3), MaxRecordsPerZone: 4}
+
+	_, err := provider.AppendRecords(context.TODO(), "example.com.", []libdns.Record{{Name: "new1", Type: "A", Value: "127.0.0.1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_RemainingCapacity_ReturnsNumberOfRecordsThatCanBeAdded(t *testing.T) {
+	provider := Provider{client: aZoneWithRecords(3), MaxRecordsPerZone: 10}
+
+	remaining, err := provider.RemainingCapacity(context.TODO(), "example.com.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "remaining", 7, remaining)
+}
+
+func Test_RemainingCapacity_ReturnsErrorWithoutLimit(t *testing.T) {
+	provider := Provider{client: aZoneWithRecords(3)}
+
+	_, err := provider.RemainingCapacity(context.TODO(), "example.com.")
+	if err == nil {
+		t.Fatalf("Expected error")
+	}
+}
@@ -0,0 +1,115 @@
+package infomaniak
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/libdns/libdns"
+)
+
+// Rules checked by LintZone
+const (
+	// LintDanglingCname a CNAME record points to a name within the zone that has no records
+	LintDanglingCname = "dangling-cname"
+
+	// LintDuplicateRecord a record with the same name, type and value exists multiple times
+	LintDuplicateRecord = "duplicate-record"
+
+	// LintMissingApexAddress the zone apex has MX records but no A or AAAA record
+	LintMissingApexAddress = "missing-apex-address"
+
+	// LintInconsistentTtl the records of a RRset have different TTLs
+	LintInconsistentTtl = "inconsistent-ttl"
+)
+
+// LintWarning a common problem of a zone found by LintZone
+type LintWarning struct {
+	// Rule that found the problem, one of the Lint constants
+	Rule string
+
+	// Name of the records relative to the zone
+	Name string
+
+	// Message describing the problem
+	Message string
+
+	// Records the problem was found in
+	Records []libdns.Record
+}
+
+// LintZone returns warnings for common problems of the zone: dangling CNAME records, duplicate records,
+// a zone apex with MX records but without A or AAAA record and RRsets whose records have different TTLs.
+// Warnings are sorted by name. If some records could not be mapped, the warnings for the remaining records are
+// returned together with the *RecordMappingError, as problems of the unmapped records may be missing.
+func (p *Provider) LintZone(ctx context.Context, zone string) ([]LintWarning, error) {
+	zone = getWithoutTrailingDot(zone)
+	ikRecords, err := p.getDnsRecordsForZone(ctx, zone)
+	var mappingErr *RecordMappingError
+	if err != nil && !errors.As(err, &mappingErr) {
+		return nil, err
+	}
+
+	warnings := lintRecords(zone, mapRecords(ikRecords, zone))
+	if mappingErr != nil {
+		return warnings, mappingErr
+	}
+	return warnings, nil
+}
+
+// lintRecords returns the warnings for the records of the zone, which must not be deduplicated
+func lintRecords(zone string, records []libdns.Record) []LintWarning {
+	model := newZoneModel(records)
+	names := model.names()
+	sort.Strings(names)
+
+	warnings := make([]LintWarning, 0)
+	for _, name := range names {
+		recs := model.resolve(name)
+		rrsets := make(map[RecordType][]libdns.Record)
+		types := make([]RecordType, 0)
+		values := make(map[string][]libdns.Record)
+		for _, rec := range recs {
+			recType := RecordType(normalizeType(rec.Type))
+			if len(rrsets[recType]) == 0 {
+				types = append(types, recType)
+			}
+			rrsets[recType] = append(rrsets[recType], rec)
+			key := string(recType) + " " + normalizeValue(rec.Value)
+			values[key] = append(values[key], rec)
+
+			if recType == TypeCNAME {
+				target := getWithoutTrailingDot(rec.Value)
+				if isInZone(target, zone) && len(model.resolve(toRelativeName(target, zone))) == 0 {
+					warnings = append(warnings, LintWarning{Rule: LintDanglingCname, Name: name, Message: fmt.Sprintf("CNAME points to '%s' which has no records", target), Records: []libdns.Record{rec}})
+				}
+			}
+		}
+
+		for _, recType := range types {
+			for _, rec := range rrsets[recType] {
+				key := string(recType) + " " + normalizeValue(rec.Value)
+				if duplicates := values[key]; len(duplicates) > 1 {
+					warnings = append(warnings, LintWarning{Rule: LintDuplicateRecord, Name: name, Message: fmt.Sprintf("%s record with value '%s' exists %d times", rec.Type, rec.Value, len(duplicates)), Records: duplicates})
+					delete(values, key)
+				}
+			}
+		}
+
+		if isApexName(name) && len(rrsets[TypeMX]) > 0 && len(rrsets[TypeA]) == 0 && len(rrsets[TypeAAAA]) == 0 {
+			warnings = append(warnings, LintWarning{Rule: LintMissingApexAddress, Name: name, Message: "zone apex has MX records but no A or AAAA record", Records: rrsets[TypeMX]})
+		}
+
+		for _, recType := range types {
+			rrset := rrsets[recType]
+			for _, rec := range rrset[1:] {
+				if rec.TTL != rrset[0].TTL {
+					warnings = append(warnings, LintWarning{Rule: LintInconsistentTtl, Name: name, Message: fmt.Sprintf("%s records have different TTLs", recType), Records: rrset})
+					break
+				}
+			}
+		}
+	}
+	return warnings
+}
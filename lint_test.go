@@ -0,0 +1,60 @@
+package infomaniak
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func Test_LintZone_ReturnsWarningsForCommonProblems(t *testing.T) {
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			return []IkRecord{
+				{ID: "1", Type: "MX", SourceIdn: "example.com", Target: "mail.example.com.", TtlInSec: 3600},
+				{ID: "2", Type: "CNAME", SourceIdn: "www.example.com", Target: "web.example.com.", TtlInSec: 3600},
+				{ID: "3", Type: "CNAME", SourceIdn: "shop.example.com", Target: "shops.example.net.", TtlInSec: 3600},
+				{ID: "4", Type: "A", SourceIdn: "mail.example.com", Target: "192.0.2.1", TtlInSec: 300},
+				{ID: "5", Type: "A", SourceIdn: "mail.example.com", Target: "192.0.2.1", TtlInSec: 300},
+				{ID: "6", Type: "A", SourceIdn: "mail.example.com", Target: "192.0.2.2", TtlInSec: 3600},
+			}, nil
+		},
+	}
+	provider := Provider{client: &client}
+
+	warnings, err := provider.LintZone(context.TODO(), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules := make([]string, 0)
+	for _, warning := range warnings {
+		rules = append(rules, warning.Name+" "+warning.Rule)
+	}
+	expected := []string{
+		" " + LintMissingApexAddress,
+		"mail " + LintDuplicateRecord,
+		"mail " + LintInconsistentTtl,
+		"www " + LintDanglingCname,
+	}
+	assertEqualsInt(t, "warnings", len(expected), len(rules))
+	for i := range expected {
+		assertEquals(t, "warning", expected[i], rules[i])
+	}
+}
+
+func Test_LintZone_ReturnsWarningsTogetherWithMappingError(t *testing.T) {
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			records := []IkRecord{{ID: "1", Type: "CNAME", SourceIdn: "www.example.com", Target: "web.example.com.", TtlInSec: 3600}}
+			return records, &RecordMappingError{Zone: zone, Errors: []error{errors.New("invalid TTL")}}
+		},
+	}
+	provider := Provider{client: &client}
+
+	warnings, err := provider.LintZone(context.TODO(), "example.com")
+	var mappingErr *RecordMappingError
+	if !errors.As(err, &mappingErr) {
+		t.Fatalf("Expected *RecordMappingError, got %v", err)
+	}
+	assertEqualsInt(t, "warnings", 1, len(warnings))
+	assertEquals(t, "rule", LintDanglingCname, warnings[0].Rule)
+}
@@ -0,0 +1,37 @@
+package infomaniak
+
+import (
+	"context"
+
+	"github.com/libdns/libdns"
+)
+
+// Locker can be implemented to serialize changes to zones, e.g. across multiple
+// instances that share the same zones by using a distributed lock (Redis, etcd, ...)
+type Locker interface {
+	// Lock blocks until the lock for the given zone is acquired or the context is done
+	Lock(ctx context.Context, zone string) error
+
+	// Unlock releases the lock for the given zone
+	Unlock(ctx context.Context, zone string) error
+}
+
+// withZoneLock runs the given operation while holding the lock of the zone - if no Locker
+// is configured, the operation is run immediately
+func (p *Provider) withZoneLock(ctx context.Context, zone string, operation func() ([]libdns.Record, error)) (result []libdns.Record, err error) {
+	if p.Locker == nil {
+		return operation()
+	}
+
+	err = p.Locker.Lock(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		unlockErr := p.Locker.Unlock(context.Background(), zone)
+		if err == nil && unlockErr != nil {
+			err = unlockErr
+		}
+	}()
+	return operation()
+}
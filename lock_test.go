@@ -0,0 +1,62 @@
+package infomaniak
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/libdns/libdns"
+)
+
+// TestLocker instance of Locker that records the lock calls
+type TestLocker struct {
+	lockErr error
+	calls   []string
+}
+
+// Lock implementation to fulfill Locker interface
+func (l *TestLocker) Lock(ctx context.Context, zone string) error {
+	l.calls = append(l.calls, "lock "+zone)
+	return l.lockErr
+}
+
+// Unlock implementation to fulfill Locker interface
+func (l *TestLocker) Unlock(ctx context.Context, zone string) error {
+	l.calls = append(l.calls, "unlock "+zone)
+	return nil
+}
+
+func Test_SetRecords_AcquiresAndReleasesLockOfZone(t *testing.T) {
+	locker := TestLocker{}
+	client := TestClient{
+		setter: func(ctx context.Context, zone string, record IkRecord) (*IkRecord, error) {
+			if len(locker.calls) != 1 {
+				t.Fatalf("Expected lock to be held while record is set")
+			}
+			return &record, nil
+		},
+	}
+	provider := Provider{client: &client, Locker: &locker}
+	_, err := provider.SetRecords(context.TODO(), "example.com.", []libdns.Record{{Name: "www", Type: "A"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(locker.calls) != 2 || locker.calls[0] != "lock example.com" || locker.calls[1] != "unlock example.com" {
+		t.Fatalf("Expected zone to be locked and unlocked, got %v", locker.calls)
+	}
+}
+
+func Test_DeleteRecords_ReturnsErrorIfLockCannotBeAcquired(t *testing.T) {
+	lockErr := errors.New("lock not available")
+	client := TestClient{
+		deleter: func(ctx context.Context, zone string, id string) error {
+			t.Fatalf("Expected that record is not deleted without lock")
+			return nil
+		},
+	}
+	provider := Provider{client: &client, Locker: &TestLocker{lockErr: lockErr}}
+	_, err := provider.DeleteRecords(context.TODO(), "example.com", []libdns.Record{{ID: "1"}})
+	if err != lockErr {
+		t.Fatalf("Expected lock error, got %v", err)
+	}
+}
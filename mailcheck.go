@@ -0,0 +1,146 @@
+package infomaniak
+
+import (
+	"context"
+	"strings"
+
+	"github.com/libdns/libdns"
+)
+
+// Severity of a finding
+type Severity string
+
+const (
+	// SeverityInfo the configuration works but could be improved
+	SeverityInfo Severity = "info"
+
+	// SeverityWarning the configuration works but deviates from the recommended one
+	SeverityWarning Severity = "warning"
+
+	// SeverityError the configuration is broken
+	SeverityError Severity = "error"
+)
+
+// MailFinding a problem of the mail configuration of a zone found by CheckMailSetup
+type MailFinding struct {
+	// Check that found the problem: "MX", "SPF", "DKIM" or "DMARC"
+	Check string
+
+	// Severity of the problem
+	Severity Severity
+
+	// Message describing the problem
+	Message string
+
+	// Records the problem was found in, empty if records are missing
+	Records []libdns.Record
+}
+
+// CheckMailSetup evaluates the MX, SPF, DKIM and DMARC records of the zone apex against the configuration recommended
+// for infomaniak's mail service, as created by MailTemplate. It returns no findings if the configuration matches
+// and ErrNoRecords if the zone has no records at all, which usually means that the wrong zone was checked.
+func (p *Provider) CheckMailSetup(ctx context.Context, zone string) ([]MailFinding, error) {
+	records, err := p.getRecords(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, ErrNoRecords
+	}
+
+	var mx, spf, dkim, dmarc []libdns.Record
+	for _, rec := range records {
+		recType := RecordType(normalizeType(rec.Type))
+		switch {
+		case recType == TypeMX && isApexName(rec.Name):
+			mx = append(mx, rec)
+		case recType == TypeTXT && isApexName(rec.Name) && txtKind(rec.Value) == "v=spf1":
+			spf = append(spf, rec)
+		case recType == TypeTXT && strings.HasSuffix(strings.ToLower(rec.Name), "._domainkey") && strings.Contains(rec.Value, "p="):
+			dkim = append(dkim, rec)
+		case recType == TypeTXT && strings.EqualFold(rec.Name, "_dmarc") && txtKind(rec.Value) == "v=dmarc1":
+			dmarc = append(dmarc, rec)
+		}
+	}
+
+	findings := make([]MailFinding, 0)
+	findings = append(findings, checkMx(mx)...)
+	findings = append(findings, checkSpf(spf)...)
+	findings = append(findings, checkDkim(dkim)...)
+	findings = append(findings, checkDmarc(dmarc)...)
+	return findings, nil
+}
+
+// checkMx returns the findings for the MX records of the zone apex
+func checkMx(records []libdns.Record) []MailFinding {
+	if len(records) == 0 {
+		return []MailFinding{{Check: "MX", Severity: SeverityError, Message: "no MX record, mails to the domain cannot be delivered"}}
+	}
+	foreign := make([]libdns.Record, 0)
+	for _, rec := range records {
+		if !strings.EqualFold(getWithoutTrailingDot(rec.Value), getWithoutTrailingDot(infomaniakMailExchanger)) {
+			foreign = append(foreign, rec)
+		}
+	}
+	if len(foreign) > 0 {
+		return []MailFinding{{Check: "MX", Severity: SeverityWarning, Message: "MX records do not point to " + infomaniakMailExchanger + ", mails are not delivered to infomaniak", Records: foreign}}
+	}
+	return nil
+}
+
+// checkSpf returns the findings for the SPF records of the zone apex
+func checkSpf(records []libdns.Record) []MailFinding {
+	if len(records) == 0 {
+		return []MailFinding{{Check: "SPF", Severity: SeverityError, Message: "no SPF record, mails sent by infomaniak may be rejected as spam"}}
+	}
+	if len(records) > 1 {
+		return []MailFinding{{Check: "SPF", Severity: SeverityError, Message: "multiple SPF records, receivers treat the SPF check as failed", Records: records}}
+	}
+
+	findings := make([]MailFinding, 0)
+	mechanisms := strings.Fields(strings.ToLower(records[0].Value))
+	if !containsValue(mechanisms, infomaniakSpfInclude) {
+		findings = append(findings, MailFinding{Check: "SPF", Severity: SeverityWarning, Message: "SPF record does not contain " + infomaniakSpfInclude + ", mails sent by infomaniak fail the SPF check", Records: records})
+	}
+	switch all := mechanisms[len(mechanisms)-1]; {
+	case all == "+all" || all == "all":
+		findings = append(findings, MailFinding{Check: "SPF", Severity: SeverityError, Message: "SPF record allows all senders", Records: records})
+	case all == "?all":
+		findings = append(findings, MailFinding{Check: "SPF", Severity: SeverityInfo, Message: "SPF record is neutral towards other senders, -all or ~all is recommended", Records: records})
+	case all != "-all" && all != "~all" && !strings.HasPrefix(all, "redirect="):
+		findings = append(findings, MailFinding{Check: "SPF", Severity: SeverityWarning, Message: "SPF record does not end with an all mechanism", Records: records})
+	}
+	return findings
+}
+
+// checkDkim returns the findings for the DKIM records of the zone
+func checkDkim(records []libdns.Record) []MailFinding {
+	if len(records) == 0 {
+		return []MailFinding{{Check: "DKIM", Severity: SeverityWarning, Message: "no DKIM record, mails are not signed in a verifiable way"}}
+	}
+	findings := make([]MailFinding, 0)
+	for _, rec := range records {
+		for _, tag := range strings.Split(rec.Value, ";") {
+			if strings.TrimSpace(tag) == "p=" {
+				findings = append(findings, MailFinding{Check: "DKIM", Severity: SeverityWarning, Message: "DKIM key of selector '" + strings.TrimSuffix(rec.Name, "._domainkey") + "' is revoked", Records: []libdns.Record{rec}})
+			}
+		}
+	}
+	return findings
+}
+
+// checkDmarc returns the findings for the DMARC records of the zone
+func checkDmarc(records []libdns.Record) []MailFinding {
+	if len(records) == 0 {
+		return []MailFinding{{Check: "DMARC", Severity: SeverityWarning, Message: "no DMARC record, receivers apply their own policy to mails failing SPF and DKIM"}}
+	}
+	if len(records) > 1 {
+		return []MailFinding{{Check: "DMARC", Severity: SeverityError, Message: "multiple DMARC records, receivers ignore the DMARC policy", Records: records}}
+	}
+	for _, tag := range strings.Split(records[0].Value, ";") {
+		if strings.EqualFold(strings.Join(strings.Fields(tag), ""), "p=none") {
+			return []MailFinding{{Check: "DMARC", Severity: SeverityInfo, Message: "DMARC policy is none, mails failing SPF and DKIM are still delivered", Records: records}}
+		}
+	}
+	return nil
+}
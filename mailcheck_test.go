@@ -0,0 +1,65 @@
+package infomaniak
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func Test_CheckMailSetup_ReturnsNoFindingsForRecommendedSetup(t *testing.T) {
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			return []IkRecord{
+				{ID: "1", Type: "MX", SourceIdn: "example.com", Target: "mta-gw.infomaniak.ch.", Priority: 5},
+				{ID: "2", Type: "TXT", SourceIdn: "example.com", Target: "v=spf1 include:spf.infomaniak.ch -all"},
+				{ID: "3", Type: "TXT", SourceIdn: "20240101._domainkey.example.com", Target: "v=DKIM1; k=rsa; p=MIIBIjANBg"},
+				{ID: "4", Type: "TXT", SourceIdn: "_dmarc.example.com", Target: "v=DMARC1; p=reject"},
+			}, nil
+		},
+	}
+	provider := Provider{client: &client}
+
+	findings, err := provider.CheckMailSetup(context.TODO(), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) > 0 {
+		t.Fatalf("Expected no findings, got %v", findings)
+	}
+}
+
+func Test_CheckMailSetup_ReturnsFindingsForMisconfiguredSetup(t *testing.T) {
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			return []IkRecord{
+				{ID: "1", Type: "MX", SourceIdn: "example.com", Target: "mx.example.net."},
+				{ID: "2", Type: "TXT", SourceIdn: "example.com", Target: "v=spf1 +all"},
+				{ID: "3", Type: "TXT", SourceIdn: "_dmarc.example.com", Target: "v=DMARC1; p=none"},
+			}, nil
+		},
+	}
+	provider := Provider{client: &client}
+
+	findings, err := provider.CheckMailSetup(context.TODO(), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	severities := make(map[string][]Severity)
+	for _, finding := range findings {
+		severities[finding.Check] = append(severities[finding.Check], finding.Severity)
+	}
+	assertEqualsInt(t, "MX findings", 1, len(severities["MX"]))
+	assertEqualsInt(t, "SPF findings", 2, len(severities["SPF"]))
+	assertEquals(t, "SPF severity", string(SeverityError), string(severities["SPF"][1]))
+	assertEqualsInt(t, "DKIM findings", 1, len(severities["DKIM"]))
+	assertEquals(t, "DMARC severity", string(SeverityInfo), string(severities["DMARC"][0]))
+}
+
+func Test_CheckMailSetup_ReturnsErrNoRecordsForEmptyZone(t *testing.T) {
+	provider := Provider{client: &TestClient{}}
+
+	_, err := provider.CheckMailSetup(context.TODO(), "example.com")
+	if !errors.Is(err, ErrNoRecords) {
+		t.Fatalf("Expected ErrNoRecords, got %v", err)
+	}
+}
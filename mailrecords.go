@@ -0,0 +1,137 @@
+package infomaniak
+
+import (
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/libdns/libdns"
+)
+
+// Pattern of a valid DKIM selector consisting of one or multiple DNS labels
+var dkimSelectorPattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9-]*[A-Za-z0-9])?(\.[A-Za-z0-9]([A-Za-z0-9-]*[A-Za-z0-9])?)*$`)
+
+// DMARCPolicy describes the DMARC policy of a domain
+type DMARCPolicy struct {
+	// Policy applied to mails failing the checks: "none", "quarantine" or "reject"
+	Policy string
+
+	// Policy applied to subdomains, the domain's policy is applied if not set
+	SubdomainPolicy string
+
+	// Percentage of mails the policy is applied to, 100 is applied if not set
+	Percent uint
+
+	// URIs aggregate reports are sent to, e.g. "mailto:dmarc@example.com"
+	AggregateReportURIs []string
+
+	// URIs failure reports are sent to
+	FailureReportURIs []string
+}
+
+// BuildDKIMRecord returns the TXT record that publishes the public key of the given DKIM selector,
+// the public key may be given as base64 or PEM encoded - long keys are split by the mappers when written
+func BuildDKIMRecord(selector string, keyType string, publicKey string) (libdns.Record, error) {
+	if !dkimSelectorPattern.MatchString(selector) {
+		return libdns.Record{}, fmt.Errorf("invalid DKIM selector '%s'", selector)
+	}
+	if keyType == "" {
+		keyType = "rsa"
+	}
+	if keyType != "rsa" && keyType != "ed25519" {
+		return libdns.Record{}, fmt.Errorf("invalid DKIM key type '%s', expected rsa or ed25519", keyType)
+	}
+
+	key := publicKey
+	key = strings.Replace(key, "-----BEGIN PUBLIC KEY-----", "", 1)
+	key = strings.Replace(key, "-----END PUBLIC KEY-----", "", 1)
+	key = strings.Join(strings.Fields(key), "")
+	if _, err := base64.StdEncoding.DecodeString(key); err != nil || key == "" {
+		return libdns.Record{}, fmt.Errorf("invalid DKIM public key: expected base64 or PEM encoded key")
+	}
+
+	return libdns.Record{
+		Type:  "TXT",
+		Name:  selector + "._domainkey",
+		Value: fmt.Sprintf("v=DKIM1; k=%s; p=%s", keyType, key),
+	}, nil
+}
+
+// BuildDMARCRecord returns the TXT record that publishes the given DMARC policy
+func BuildDMARCRecord(policy DMARCPolicy) (libdns.Record, error) {
+	if !isDmarcPolicy(policy.Policy) {
+		return libdns.Record{}, fmt.Errorf("invalid DMARC policy '%s', expected none, quarantine or reject", policy.Policy)
+	}
+	if policy.SubdomainPolicy != "" && !isDmarcPolicy(policy.SubdomainPolicy) {
+		return libdns.Record{}, fmt.Errorf("invalid DMARC subdomain policy '%s', expected none, quarantine or reject", policy.SubdomainPolicy)
+	}
+	if policy.Percent > 100 {
+		return libdns.Record{}, fmt.Errorf("invalid DMARC percentage %d, expected at most 100", policy.Percent)
+	}
+
+	tags := []string{"v=DMARC1", "p=" + policy.Policy}
+	if policy.SubdomainPolicy != "" {
+		tags = append(tags, "sp="+policy.SubdomainPolicy)
+	}
+	if policy.Percent > 0 && policy.Percent < 100 {
+		tags = append(tags, fmt.Sprintf("pct=%d", policy.Percent))
+	}
+	if len(policy.AggregateReportURIs) > 0 {
+		tags = append(tags, "rua="+strings.Join(policy.AggregateReportURIs, ","))
+	}
+	if len(policy.FailureReportURIs) > 0 {
+		tags = append(tags, "ruf="+strings.Join(policy.FailureReportURIs, ","))
+	}
+
+	return libdns.Record{
+		Type:  "TXT",
+		Name:  "_dmarc",
+		Value: strings.Join(tags, "; "),
+	}, nil
+}
+
+// BuildSPFRecord returns the TXT record that publishes the given SPF terms for the given relative name,
+// e.g. BuildSPFRecord("@", "include:spf.infomaniak.ch", "-all")
+func BuildSPFRecord(name string, terms ...string) (libdns.Record, error) {
+	for _, term := range terms {
+		if !isSpfTerm(term) {
+			return libdns.Record{}, fmt.Errorf("invalid SPF term '%s'", term)
+		}
+	}
+	return libdns.Record{
+		Type:  "TXT",
+		Name:  name,
+		Value: strings.Join(append([]string{"v=spf1"}, terms...), " "),
+	}, nil
+}
+
+// isDmarcPolicy returns if the given policy is a valid DMARC policy
+func isDmarcPolicy(policy string) bool {
+	return policy == "none" || policy == "quarantine" || policy == "reject"
+}
+
+// isSpfTerm returns if the given term is a known SPF mechanism with optional qualifier or a known modifier
+func isSpfTerm(term string) bool {
+	term = strings.ToLower(term)
+	if i := strings.Index(term, "="); i >= 0 {
+		modifier := term[:i]
+		return (modifier == "redirect" || modifier == "exp") && i < len(term)-1
+	}
+
+	term = strings.TrimLeft(term, "+-~?")
+	mechanism, argument := term, ""
+	if i := strings.IndexAny(term, ":/"); i >= 0 {
+		mechanism, argument = term[:i], term[i:]
+	}
+	switch mechanism {
+	case "all":
+		return argument == ""
+	case "a", "mx", "ptr":
+		return true
+	case "include", "exists", "ip4", "ip6":
+		return len(argument) > 1 && argument[0] == ':'
+	default:
+		return false
+	}
+}
@@ -0,0 +1,59 @@
+package infomaniak
+
+import (
+	"testing"
+)
+
+func Test_BuildDKIMRecord_ReturnsRecordWithPublicKey(t *testing.T) {
+	rec, err := BuildDKIMRecord("mail", "", "-----BEGIN PUBLIC KEY-----\nMIGfMA0G\nCSqGSIb3\n-----END PUBLIC KEY-----\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "Type", "TXT", rec.Type)
+	assertEquals(t, "Name", "mail._domainkey", rec.Name)
+	assertEquals(t, "Value", "v=DKIM1; k=rsa; p=MIGfMA0GCSqGSIb3", rec.Value)
+}
+
+func Test_BuildDKIMRecord_ReturnsErrorForInvalidInput(t *testing.T) {
+	if _, err := BuildDKIMRecord("in valid", "rsa", "MIGfMA0G"); err == nil {
+		t.Fatalf("Expected error for invalid selector")
+	}
+	if _, err := BuildDKIMRecord("mail", "dsa", "MIGfMA0G"); err == nil {
+		t.Fatalf("Expected error for invalid key type")
+	}
+	if _, err := BuildDKIMRecord("mail", "rsa", "not base64!"); err == nil {
+		t.Fatalf("Expected error for invalid public key")
+	}
+}
+
+func Test_BuildDMARCRecord_ReturnsRecordWithPolicy(t *testing.T) {
+	rec, err := BuildDMARCRecord(DMARCPolicy{Policy: "quarantine", Percent: 50, AggregateReportURIs: []string{"mailto:dmarc@example.com"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "Name", "_dmarc", rec.Name)
+	assertEquals(t, "Value", "v=DMARC1; p=quarantine; pct=50; rua=mailto:dmarc@example.com", rec.Value)
+}
+
+func Test_BuildDMARCRecord_ReturnsErrorForInvalidPolicy(t *testing.T) {
+	if _, err := BuildDMARCRecord(DMARCPolicy{Policy: "block"}); err == nil {
+		t.Fatalf("Expected error for invalid policy")
+	}
+}
+
+func Test_BuildSPFRecord_ReturnsRecordWithTerms(t *testing.T) {
+	rec, err := BuildSPFRecord("@", "mx", "ip4:192.0.2.0/24", "include:spf.infomaniak.ch", "-all")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "Value", "v=spf1 mx ip4:192.0.2.0/24 include:spf.infomaniak.ch -all", rec.Value)
+}
+
+func Test_BuildSPFRecord_ReturnsErrorForUnknownTerm(t *testing.T) {
+	if _, err := BuildSPFRecord("@", "include", "-all"); err == nil {
+		t.Fatalf("Expected error for include without domain")
+	}
+	if _, err := BuildSPFRecord("@", "allow:example.com"); err == nil {
+		t.Fatalf("Expected error for unknown mechanism")
+	}
+}
@@ -1,6 +1,8 @@
 package infomaniak
 
 import (
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/libdns/libdns"
@@ -12,25 +14,136 @@ const defaultPriority = 10
 // Default TTL that is applied if none is provided - infomaniak requires a TTL
 const defaultTtlSecs = 300
 
+// Legacy record types that are mapped to the type whose semantics they share
+var typeAliases = map[string]RecordType{
+	"SPF": TypeTXT,
+}
+
+// normalizeType returns the upper case record type with legacy aliases resolved
+func normalizeType(recType string) string {
+	recType = strings.ToUpper(strings.TrimSpace(recType))
+	if alias, ok := typeAliases[recType]; ok {
+		return string(alias)
+	}
+	return recType
+}
+
+// Types whose value starts with a priority-like number that infomaniak stores as the record's priority,
+// the SvcPriority of HTTPS and SVCB records and the order of NAPTR records
+var valuePriorityTypes = map[RecordType]bool{
+	TypeHTTPS: true,
+	TypeSVCB:  true,
+	TypeNAPTR: true,
+}
+
 // ToLibDnsRecord maps a infomaniak dns record to a libdns record
 func (ikr *IkRecord) ToLibDnsRecord(zone string) libdns.Record {
+	recType := normalizeType(ikr.Type)
+	priority := ikr.Priority
+	if priority == 0 && RecordType(recType) == TypeNAPTR && ikr.Description != nil {
+		priority = ikr.Description.Order
+	}
+	target := ikr.Target
+	if priority == 0 && ikr.Description == nil && RecordType(recType).NeedsDescription() {
+		if targetPriority, rest, ok := splitTargetPriority(recType, target); ok {
+			priority, target = targetPriority, rest
+		}
+	}
 	return libdns.Record{
 		ID:       ikr.ID,
-		Type:     ikr.Type,
-		Name:     libdns.RelativeName(ikr.SourceIdn, zone),
-		Value:    ikr.Target,
+		Type:     recType,
+		Name:     toRelativeName(ikr.SourceIdn, zone),
+		Value:    unescapeTarget(recType, target),
 		TTL:      time.Duration(ikr.TtlInSec),
-		Priority: ikr.Priority,
+		Priority: priority,
+	}
+}
+
+// Number of fields of MX and SRV targets that include the priority in zone file presentation format
+var targetFieldsWithPriority = map[RecordType]int{
+	TypeMX:  2,
+	TypeSRV: 4,
+}
+
+// splitTargetPriority returns the priority and the remaining target of MX and SRV targets in zone file presentation format,
+// which the API returns for plans without record descriptions instead of a separate priority
+func splitTargetPriority(recType string, target string) (uint, string, bool) {
+	fields := strings.Fields(target)
+	if len(fields) == 0 || len(fields) != targetFieldsWithPriority[RecordType(recType)] {
+		return 0, target, false
 	}
+	for _, field := range fields[:len(fields)-1] {
+		if _, err := strconv.ParseUint(field, 10, 16); err != nil {
+			return 0, target, false
+		}
+	}
+	priority, _ := strconv.ParseUint(fields[0], 10, 16)
+	return uint(priority), strings.Join(fields[1:], " "), true
+}
+
+// getValuePriority returns the priority-like number the value of HTTPS, SVCB and NAPTR records starts with
+func getValuePriority(recType string, value string) (uint, bool) {
+	if !valuePriorityTypes[RecordType(recType)] {
+		return 0, false
+	}
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return 0, false
+	}
+	priority, err := strconv.ParseUint(fields[0], 10, 16)
+	if err != nil {
+		return 0, false
+	}
+	return uint(priority), true
+}
+
+// unescapeTarget returns the value of a target that is returned in zone file presentation format by the API. Only the quotes
+// of TXT targets are syntax and are removed, quotes of other types are part of the value and are kept, e.g. the quoted value
+// of CAA records - the values of rarely used types are only canonicalized
+func unescapeTarget(recType string, target string) string {
+	switch RecordType(recType) {
+	case TypeTXT:
+		return unquoteTxtTarget(target)
+	case TypeCAA:
+		return target
+	default:
+		return canonicalizeRareTypeValue(recType, target)
+	}
+}
+
+// unquoteTxtTarget returns the concatenated content of the character-strings of a TXT target,
+// targets that are not quoted or cannot be parsed are returned unchanged
+func unquoteTxtTarget(target string) string {
+	if !strings.HasPrefix(target, `"`) {
+		return target
+	}
+	value, err := unquoteCharacterStrings(target)
+	if err != nil {
+		return target
+	}
+	return value
+}
+
+// escapeValue returns the value in zone file presentation format as expected by the API
+func escapeValue(recType string, value string) string {
+	if RecordType(recType) != TypeTXT {
+		return canonicalizeRareTypeValue(recType, value)
+	}
+	return quoteCharacterStrings(value)
 }
 
 // ToInfomaniakRecord maps a libdns record to a infomaniak dns record
 func ToInfomaniakRecord(libdnsRec *libdns.Record, zone string) IkRecord {
+	recType := normalizeType(libdnsRec.Type)
+	name, value := libdnsRec.Name, libdnsRec.Value
+	if RecordType(recType) == TypeSRV {
+		name, value = trimApexSuffix(name), srvTargetWithWeight(libdnsRec)
+	}
 	ikRec := IkRecord{
 		ID:        libdnsRec.ID,
-		Type:      libdnsRec.Type,
-		SourceIdn: libdns.AbsoluteName(libdnsRec.Name, zone),
-		Target:    libdnsRec.Value,
+		Type:      recType,
+		SourceIdn: toAbsoluteName(name, zone),
+		Target:    escapeValue(recType, value),
 		TtlInSec:  uint(libdnsRec.TTL),
 		Priority:  libdnsRec.Priority,
 	}
@@ -38,9 +151,14 @@ func ToInfomaniakRecord(libdnsRec *libdns.Record, zone string) IkRecord {
 	if ikRec.TtlInSec <= 0 {
 		ikRec.TtlInSec = defaultTtlSecs
 	}
+	ikRec.TtlInSec = nearestAllowedTtlSecs(ikRec.TtlInSec)
 
 	if ikRec.Priority <= 0 {
-		ikRec.Priority = defaultPriority
+		if priority, ok := getValuePriority(recType, ikRec.Target); ok {
+			ikRec.Priority = priority
+		} else {
+			ikRec.Priority = defaultPriority
+		}
 	}
 
 	return ikRec
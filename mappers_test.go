@@ -1,6 +1,7 @@
 package infomaniak
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/libdns/libdns"
@@ -35,6 +36,28 @@ func Test_ToLibDnsRecord_ReturnsRelativeName(t *testing.T) {
 	assertEquals(t, "Name", subzone, libRec.Name)
 }
 
+func Test_ToLibDnsRecord_ParsesPriorityFromTargetWithoutDescription(t *testing.T) {
+	mx := (&IkRecord{Type: "MX", Target: "20 mail.example.com."}).ToLibDnsRecord("example.com")
+	assertEqualsInt(t, "MX priority", 20, int(mx.Priority))
+	assertEquals(t, "MX value", "mail.example.com.", mx.Value)
+
+	srv := (&IkRecord{Type: "SRV", Target: "10 5 443 sip.example.com."}).ToLibDnsRecord("example.com")
+	assertEqualsInt(t, "SRV priority", 10, int(srv.Priority))
+	assertEquals(t, "SRV value", "5 443 sip.example.com.", srv.Value)
+}
+
+func Test_ToLibDnsRecord_KeepsTargetIfPriorityIsKnown(t *testing.T) {
+	withPriority := (&IkRecord{Type: "MX", Target: "20 mail.example.com.", Priority: 5}).ToLibDnsRecord("example.com")
+	assertEquals(t, "value with priority", "20 mail.example.com.", withPriority.Value)
+
+	withDescription := (&IkRecord{Type: "SRV", Target: "10 5 443 sip.example.com.", Description: &IkRecordDescription{}}).ToLibDnsRecord("example.com")
+	assertEquals(t, "value with description", "10 5 443 sip.example.com.", withDescription.Value)
+
+	regular := (&IkRecord{Type: "SRV", Target: "5 443 sip.example.com."}).ToLibDnsRecord("example.com")
+	assertEquals(t, "value without priority", "5 443 sip.example.com.", regular.Value)
+	assertEqualsInt(t, "priority", 0, int(regular.Priority))
+}
+
 func Test_ToInfomaniakRecord_MapsAllProperties(t *testing.T) {
 	libRec := libdns.Record{
 		ID:       "123456",
@@ -68,3 +91,106 @@ func Test_ToInfomaniakRecord_SetsAbsoluteNameToSource(t *testing.T) {
 	ikRec := ToInfomaniakRecord(&libdns.Record{Name: subzone}, zone)
 	assertEquals(t, "SourceIdn", subzone+"."+zone, ikRec.SourceIdn)
 }
+
+func Benchmark_ToLibDnsRecord(b *testing.B) {
+	ikRec := IkRecord{ID: "123456", Type: "TXT", SourceIdn: "_acme-challenge.sub.example.com", Target: "value", TtlInSec: 300}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ikRec.ToLibDnsRecord("example.com")
+	}
+}
+
+func Benchmark_ToInfomaniakRecord(b *testing.B) {
+	libRec := libdns.Record{ID: "123456", Type: "TXT", Name: "_acme-challenge.sub", Value: "value", TTL: 300}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ToInfomaniakRecord(&libRec, "example.com")
+	}
+}
+
+func Test_ToInfomaniakRecord_EscapesTxtValue(t *testing.T) {
+	ikRec := ToInfomaniakRecord(&libdns.Record{Type: "TXT", Value: `v=spf1 include:"x"; ä`}, "")
+	assertEquals(t, "Target", `"v=spf1 include:\"x\"; \195\164"`, ikRec.Target)
+}
+
+func Test_ToLibDnsRecord_UnescapesTxtTarget(t *testing.T) {
+	ikRec := IkRecord{Type: "TXT", Target: `"v=DKIM1; " "p=abc\"def"`}
+	assertEquals(t, "Value", `v=DKIM1; p=abc"def`, ikRec.ToLibDnsRecord("").Value)
+}
+
+func Test_ToLibDnsRecord_KeepsUnquotedTxtTarget(t *testing.T) {
+	ikRec := IkRecord{Type: "TXT", Target: `plain value`}
+	assertEquals(t, "Value", `plain value`, ikRec.ToLibDnsRecord("").Value)
+}
+
+func Test_TxtValue_RoundTripsThroughMappers(t *testing.T) {
+	for _, value := range []string{"simple", `with "quotes" and \backslash`, "semi;colon", "ünïcödé", strings.Repeat("a", 400)} {
+		ikRec := ToInfomaniakRecord(&libdns.Record{Type: "TXT", Value: value}, "example.com")
+		assertEquals(t, "Value", value, ikRec.ToLibDnsRecord("example.com").Value)
+	}
+}
+
+func Test_ToLibDnsRecord_ReturnsEmptyNameForApexRecord(t *testing.T) {
+	ikRec := IkRecord{SourceIdn: "example.com."}
+	assertEquals(t, "Name", "", ikRec.ToLibDnsRecord("example.com").Name)
+}
+
+func Test_ToLibDnsRecord_NormalizesLowercaseAndAliasedTypes(t *testing.T) {
+	assertEquals(t, "Type", "MX", (&IkRecord{Type: "mx"}).ToLibDnsRecord("").Type)
+
+	libRec := (&IkRecord{Type: "SPF", Target: `"v=spf1 -all"`}).ToLibDnsRecord("")
+	assertEquals(t, "Type", "TXT", libRec.Type)
+	assertEquals(t, "Value", "v=spf1 -all", libRec.Value)
+}
+
+func Test_ToInfomaniakRecord_NormalizesLowercaseAndAliasedTypes(t *testing.T) {
+	ikRec := ToInfomaniakRecord(&libdns.Record{Type: "spf", Value: "v=spf1 -all"}, "")
+	assertEquals(t, "Type", "TXT", ikRec.Type)
+	assertEquals(t, "Target", `"v=spf1 -all"`, ikRec.Target)
+}
+
+func Test_ToInfomaniakRecord_TakesPriorityOfHttpsRecordFromValue(t *testing.T) {
+	ikRec := ToInfomaniakRecord(&libdns.Record{Type: "HTTPS", Value: "1 . alpn=h2"}, "example.com")
+	assertEqualsInt(t, "Priority", 1, int(ikRec.Priority))
+}
+
+func Test_UnescapeTarget_HandlesQuotesPerType(t *testing.T) {
+	for _, testCase := range []struct {
+		recType  string
+		target   string
+		expected string
+	}{
+		{"TXT", `"v=spf1 include:spf.infomaniak.ch -all"`, "v=spf1 include:spf.infomaniak.ch -all"},
+		{"TXT", `"v=DKIM1; p=MIIB" "IjANBg"`, "v=DKIM1; p=MIIBIjANBg"},
+		{"TXT", `"say \"hi\""`, `say "hi"`},
+		{"TXT", `"semi\059colon"`, "semi;colon"},
+		{"TXT", "unquoted value", "unquoted value"},
+		{"TXT", `"unterminated`, `"unterminated`},
+		{"SPF", `"v=spf1 -all"`, "v=spf1 -all"},
+		{"CAA", `0 issue "letsencrypt.org"`, `0 issue "letsencrypt.org"`},
+		{"CAA", `128 iodef "mailto:security@example.com"`, `128 iodef "mailto:security@example.com"`},
+		{"HINFO", `"INTEL"   "LINUX"`, `"INTEL" "LINUX"`},
+		{"NAPTR", `100 10 "S" "SIP+D2U" "" _sip._udp.example.com.`, `100 10 "S" "SIP+D2U" "" _sip._udp.example.com.`},
+		{"A", "127.0.0.1", "127.0.0.1"},
+		{"CNAME", `"quoted.example.com."`, `"quoted.example.com."`},
+		{"MX", "mail.example.com.", "mail.example.com."},
+	} {
+		actual := unescapeTarget(normalizeType(testCase.recType), testCase.target)
+		assertEquals(t, testCase.recType+" value of "+testCase.target, testCase.expected, actual)
+	}
+}
+
+func Test_EscapeValue_RoundTripsWithUnescapeTarget(t *testing.T) {
+	for _, testCase := range []struct {
+		recType string
+		value   string
+	}{
+		{"TXT", `say "hi" \ bye`},
+		{"TXT", strings.Repeat("a", 300)},
+		{"CAA", `0 issue "letsencrypt.org"`},
+		{"A", "127.0.0.1"},
+	} {
+		actual := unescapeTarget(testCase.recType, escapeValue(testCase.recType, testCase.value))
+		assertEquals(t, testCase.recType+" value", testCase.value, actual)
+	}
+}
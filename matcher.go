@@ -0,0 +1,65 @@
+package infomaniak
+
+import (
+	"strings"
+
+	"github.com/libdns/libdns"
+)
+
+// DeleteMatcher decides which existing records are deleted for a record without ID that is passed to DeleteRecords,
+// only existing records with the same name are passed to the matcher
+type DeleteMatcher interface {
+	// Matches returns if the existing record is deleted for the given record to delete
+	Matches(existingRec libdns.Record, recToDelete libdns.Record) bool
+}
+
+// DeleteMatcherFunc allows to use a function as DeleteMatcher
+type DeleteMatcherFunc func(existingRec libdns.Record, recToDelete libdns.Record) bool
+
+// Matches calls the function
+func (f DeleteMatcherFunc) Matches(existingRec libdns.Record, recToDelete libdns.Record) bool {
+	return f(existingRec, recToDelete)
+}
+
+var (
+	// MatchDefault matches records with the same name, type and value, where the value only needs to match if it is set -
+	// records to delete without type match no record, so that a missing type does not delete all records of a name
+	MatchDefault DeleteMatcher = DeleteMatcherFunc(isDeleteRecord)
+
+	// MatchAnyType matches as MatchDefault does, but records to delete without type match the records of all types of the name
+	MatchAnyType DeleteMatcher = DeleteMatcherFunc(isDeleteRecordOfAnyType)
+
+	// MatchExact matches records with exactly the same name, type and value, empty types or values only match empty ones
+	MatchExact DeleteMatcher = DeleteMatcherFunc(func(existingRec libdns.Record, recToDelete libdns.Record) bool {
+		return normalizeName(existingRec.Name) == normalizeName(recToDelete.Name) &&
+			normalizeType(existingRec.Type) == normalizeType(recToDelete.Type) &&
+			existingRec.Value == recToDelete.Value
+	})
+
+	// MatchNameAndType matches records with the same name and type regardless of their value
+	MatchNameAndType DeleteMatcher = DeleteMatcherFunc(func(existingRec libdns.Record, recToDelete libdns.Record) bool {
+		return normalizeName(existingRec.Name) == normalizeName(recToDelete.Name) &&
+			normalizeType(existingRec.Type) == normalizeType(recToDelete.Type)
+	})
+
+	// MatchNormalizedValue matches as MatchDefault does, but compares values case-insensitively
+	// and ignores surrounding whitespace and trailing dots of host names
+	MatchNormalizedValue DeleteMatcher = DeleteMatcherFunc(func(existingRec libdns.Record, recToDelete libdns.Record) bool {
+		return normalizeName(existingRec.Name) == normalizeName(recToDelete.Name) &&
+			recToDelete.Type != "" && normalizeType(existingRec.Type) == normalizeType(recToDelete.Type) &&
+			(recToDelete.Value == "" || normalizeValue(existingRec.Value) == normalizeValue(recToDelete.Value))
+	})
+)
+
+// normalizeValue returns the value in a form in which equivalent values are equal
+func normalizeValue(value string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(value), "."))
+}
+
+// getDeleteMatcher returns the configured matcher for records to delete or the default one
+func (p *Provider) getDeleteMatcher() DeleteMatcher {
+	if p.DeleteMatcher == nil {
+		return MatchDefault
+	}
+	return p.DeleteMatcher
+}
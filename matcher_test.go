@@ -0,0 +1,61 @@
+package infomaniak
+
+import (
+	"context"
+	"testing"
+
+	"github.com/libdns/libdns"
+)
+
+func Test_MatchExact_DoesNotTreatEmptyValueAsWildcard(t *testing.T) {
+	existingRec := libdns.Record{Name: "www", Type: "A", Value: "127.0.0.1"}
+	if MatchExact.Matches(existingRec, libdns.Record{Name: "www", Type: "A"}) {
+		t.Fatalf("Expected record without value not to match")
+	}
+	if !MatchExact.Matches(existingRec, libdns.Record{Name: "WWW", Type: "A", Value: "127.0.0.1"}) {
+		t.Fatalf("Expected record with same name, type and value to match")
+	}
+}
+
+func Test_MatchNameAndType_IgnoresValue(t *testing.T) {
+	existingRec := libdns.Record{Name: "www", Type: "A", Value: "127.0.0.1"}
+	if !MatchNameAndType.Matches(existingRec, libdns.Record{Name: "www", Type: "A", Value: "127.0.0.2"}) {
+		t.Fatalf("Expected record with other value to match")
+	}
+}
+
+func Test_MatchNormalizedValue_ComparesNormalizedValues(t *testing.T) {
+	existingRec := libdns.Record{Name: "www", Type: "CNAME", Value: "Target.Example.com."}
+	if !MatchNormalizedValue.Matches(existingRec, libdns.Record{Name: "www", Type: "CNAME", Value: "target.example.com"}) {
+		t.Fatalf("Expected record with equivalent value to match")
+	}
+}
+
+func Test_DeleteRecords_UsesConfiguredMatcher(t *testing.T) {
+	deletedIds := make([]string, 0)
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			return []IkRecord{{ID: "1", SourceIdn: "www.example.com", Type: "A", Target: "127.0.0.1"}}, nil
+		},
+		deleter: func(ctx context.Context, zone string, id string) error {
+			deletedIds = append(deletedIds, id)
+			return nil
+		},
+	}
+	provider := Provider{client: &client, DeleteMatcher: MatchNameAndType}
+	_, err := provider.DeleteRecords(context.TODO(), "example.com", []libdns.Record{{Name: "www", Type: "A", Value: "127.0.0.2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "deleted records", 1, len(deletedIds))
+}
+
+func Test_MatchDefault_MatchesTypesCaseInsensitively(t *testing.T) {
+	existing := libdns.Record{Name: "www", Type: "TXT", Value: "v"}
+	if !MatchDefault.Matches(existing, libdns.Record{Name: "www", Type: "txt"}) {
+		t.Fatalf("Expected lowercase type to match")
+	}
+	if !MatchExact.Matches(existing, libdns.Record{Name: "www", Type: "SPF", Value: "v"}) {
+		t.Fatalf("Expected aliased type to match")
+	}
+}
@@ -0,0 +1,56 @@
+package infomaniak
+
+import (
+	"context"
+	"errors"
+)
+
+// ClientMiddleware decorates the client through which the provider calls the API, e.g. to add caching, metrics or access control
+type ClientMiddleware func(next IkClient) IkClient
+
+// ErrReadOnly is returned by clients decorated with ReadOnly for every call that would change records
+var ErrReadOnly = errors.New("changes are not allowed, the client is read-only")
+
+// ReadOnly is a ClientMiddleware that lets records be listed but rejects all changes with ErrReadOnly
+func ReadOnly(next IkClient) IkClient {
+	return &readOnlyClient{next: next}
+}
+
+// readOnlyClient decorates a client so that only records can be listed
+type readOnlyClient struct {
+	next IkClient
+}
+
+// GetDnsRecordsForZone lists the records with the decorated client
+func (c *readOnlyClient) GetDnsRecordsForZone(ctx context.Context, zone string) ([]IkRecord, error) {
+	return c.next.GetDnsRecordsForZone(ctx, zone)
+}
+
+// CreateOrUpdateRecord rejects the change
+func (c *readOnlyClient) CreateOrUpdateRecord(ctx context.Context, zone string, record IkRecord) (*IkRecord, error) {
+	return nil, ErrReadOnly
+}
+
+// PatchRecord rejects the change
+func (c *readOnlyClient) PatchRecord(ctx context.Context, zone string, id string, changes IkRecordPatch) error {
+	return ErrReadOnly
+}
+
+// DeleteRecord rejects the change
+func (c *readOnlyClient) DeleteRecord(ctx context.Context, zone string, id string) error {
+	return ErrReadOnly
+}
+
+// applyMiddlewares returns the client decorated with the configured middlewares, the first middleware is the outermost one
+func (p *Provider) applyMiddlewares(client IkClient) IkClient {
+	for i := len(p.ClientMiddlewares) - 1; i >= 0; i-- {
+		client = p.ClientMiddlewares[i](client)
+	}
+	return client
+}
+
+// Interface guards
+var (
+	_ IkClient        = (*readOnlyClient)(nil)
+	_ IkRecordPatcher = (*readOnlyClient)(nil)
+)
@@ -0,0 +1,53 @@
+package infomaniak
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/libdns/libdns"
+)
+
+// namingClient records the order in which decorated clients are called
+type namingClient struct {
+	IkClient
+	name  string
+	calls *[]string
+}
+
+// GetDnsRecordsForZone records the call and delegates it
+func (c *namingClient) GetDnsRecordsForZone(ctx context.Context, zone string) ([]IkRecord, error) {
+	*c.calls = append(*c.calls, c.name)
+	return c.IkClient.GetDnsRecordsForZone(ctx, zone)
+}
+
+func Test_ClientMiddlewares_AreAppliedInOrder(t *testing.T) {
+	calls := make([]string, 0)
+	naming := func(name string) ClientMiddleware {
+		return func(next IkClient) IkClient {
+			return &namingClient{IkClient: next, name: name, calls: &calls}
+		}
+	}
+	provider := Provider{client: &TestClient{}, ClientMiddlewares: []ClientMiddleware{naming("outer"), naming("inner")}}
+
+	_, err := provider.GetRecords(context.TODO(), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "calls", 2, len(calls))
+	assertEquals(t, "first call", "outer", calls[0])
+	assertEquals(t, "second call", "inner", calls[1])
+}
+
+func Test_ReadOnly_RejectsChanges(t *testing.T) {
+	provider := Provider{client: &TestClient{}, ClientMiddlewares: []ClientMiddleware{ReadOnly}}
+
+	_, err := provider.GetRecords(context.TODO(), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = provider.AppendRecords(context.TODO(), "example.com", []libdns.Record{{Type: "TXT", Name: "test", Value: "value"}})
+	if !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("Expected ErrReadOnly, got %v", err)
+	}
+}
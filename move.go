@@ -0,0 +1,108 @@
+package infomaniak
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/libdns/libdns"
+)
+
+// MoveRecords moves the records of fromZone for which matches returns true to toZone, e.g. to reorganize subdomains
+// into their own zone. The records are created in toZone first and are deleted from fromZone afterwards - if a call fails,
+// the changes made so far are rolled back. All moved records have to be part of toZone. It returns the records created in toZone.
+func (p *Provider) MoveRecords(ctx context.Context, fromZone string, toZone string, matches func(rec libdns.Record) bool) ([]libdns.Record, error) {
+	fromZone = getWithoutTrailingDot(fromZone)
+	toZone = getWithoutTrailingDot(toZone)
+	if fromZone == toZone {
+		return nil, fmt.Errorf("cannot move records of zone '%s' to itself", fromZone)
+	}
+
+	// both zones are locked in the same order by all callers, so that moves in opposite directions cannot deadlock
+	firstZone, secondZone := fromZone, toZone
+	if secondZone < firstZone {
+		firstZone, secondZone = secondZone, firstZone
+	}
+	return p.withZoneLock(ctx, firstZone, func() ([]libdns.Record, error) {
+		return p.withZoneLock(ctx, secondZone, func() ([]libdns.Record, error) {
+			return p.moveRecords(ctx, fromZone, toZone, matches)
+		})
+	})
+}
+
+// moveRecords moves the matching records without acquiring the zones' locks
+func (p *Provider) moveRecords(ctx context.Context, fromZone string, toZone string, matches func(rec libdns.Record) bool) ([]libdns.Record, error) {
+	existingRecs, err := p.getRecords(ctx, fromZone)
+	if err != nil {
+		return nil, err
+	}
+
+	recsToMove := make([]libdns.Record, 0)
+	copies := make([]libdns.Record, 0)
+	for _, rec := range existingRecs {
+		if !matches(rec) {
+			continue
+		}
+		fqdn := toAbsoluteName(rec.Name, fromZone)
+		if !isInZone(fqdn, toZone) {
+			return nil, fmt.Errorf("%s record '%s' cannot be moved as it is not part of zone '%s'", rec.Type, fqdn, toZone)
+		}
+		copy := rec
+		copy.ID = ""
+		copy.Name = toRelativeName(fqdn, toZone)
+		recsToMove = append(recsToMove, rec)
+		copies = append(copies, copy)
+	}
+	if len(recsToMove) <= 0 {
+		return copies, nil
+	}
+
+	err = p.checkForConflicts(ctx, toZone, copies)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := p.getClient()
+	if err != nil {
+		return nil, err
+	}
+
+	createdRecs := make([]libdns.Record, 0, len(copies))
+	for _, rec := range copies {
+		createdRec, err := client.CreateOrUpdateRecord(ctx, toZone, ToInfomaniakRecord(&rec, toZone))
+		p.records.invalidate()
+		if err != nil {
+			return nil, p.rollbackMove(client, fromZone, toZone, nil, createdRecs, err)
+		}
+		createdRecs = append(createdRecs, createdRec.ToLibDnsRecord(toZone))
+	}
+
+	deletedRecs := make([]libdns.Record, 0, len(recsToMove))
+	for _, rec := range recsToMove {
+		err := client.DeleteRecord(ctx, fromZone, rec.ID)
+		p.records.invalidate()
+		if err != nil && !isRecordNotFound(err) {
+			return nil, p.rollbackMove(client, fromZone, toZone, deletedRecs, createdRecs, err)
+		}
+		deletedRecs = append(deletedRecs, rec)
+	}
+	return createdRecs, nil
+}
+
+// rollbackMove recreates the records deleted from fromZone and deletes the records created in toZone, it is not
+// cancelled with the operation's context - the error of the move is returned together with errors of the rollback
+func (p *Provider) rollbackMove(client IkClient, fromZone string, toZone string, deletedRecs []libdns.Record, createdRecs []libdns.Record, moveErr error) error {
+	ctx := context.Background()
+	defer p.records.invalidate()
+	for _, rec := range deletedRecs {
+		rec.ID = ""
+		if _, err := client.CreateOrUpdateRecord(ctx, fromZone, ToInfomaniakRecord(&rec, fromZone)); err != nil {
+			return fmt.Errorf("%w, rollback failed to recreate %s record '%s' in zone '%s': %v", moveErr, rec.Type, rec.Name, fromZone, err)
+		}
+	}
+	for _, rec := range createdRecs {
+		if err := client.DeleteRecord(ctx, toZone, rec.ID); err != nil {
+			return fmt.Errorf("%w, rollback failed to delete %s record '%s' in zone '%s': %v", moveErr, rec.Type, rec.Name, toZone, err)
+		}
+	}
+	return moveErr
+}
@@ -0,0 +1,79 @@
+package infomaniak
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/libdns/libdns"
+)
+
+func Test_MoveRecords_CreatesRecordsInDestinationAndDeletesThemFromSource(t *testing.T) {
+	created := make([]string, 0)
+	deleted := make([]string, 0)
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			return []IkRecord{
+				{ID: "1", Type: "A", SourceIdn: "www.shop.example.com", Target: "192.0.2.1"},
+				{ID: "2", Type: "A", SourceIdn: "www.example.com", Target: "192.0.2.2"},
+			}, nil
+		},
+		setter: func(ctx context.Context, zone string, record IkRecord) (*IkRecord, error) {
+			created = append(created, zone+" "+record.SourceIdn)
+			record.ID = "10"
+			return &record, nil
+		},
+		deleter: func(ctx context.Context, zone string, id string) error {
+			deleted = append(deleted, zone+" "+id)
+			return nil
+		},
+	}
+	provider := Provider{client: &client}
+
+	moved, err := provider.MoveRecords(context.TODO(), "example.com", "shop.example.com", func(rec libdns.Record) bool {
+		return strings.HasSuffix(rec.Name, "shop")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "moved records", 1, len(moved))
+	assertEquals(t, "name in destination", "www", moved[0].Name)
+	assertEquals(t, "created record", "shop.example.com www.shop.example.com", created[0])
+	assertEquals(t, "deleted record", "example.com 1", deleted[0])
+}
+
+func Test_MoveRecords_RollsBackIfDeletionFails(t *testing.T) {
+	created := make([]string, 0)
+	deleted := make([]string, 0)
+	deleteErr := errors.New("delete failed")
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			return []IkRecord{
+				{ID: "1", Type: "A", SourceIdn: "a.shop.example.com", Target: "192.0.2.1"},
+				{ID: "2", Type: "A", SourceIdn: "b.shop.example.com", Target: "192.0.2.2"},
+			}, nil
+		},
+		setter: func(ctx context.Context, zone string, record IkRecord) (*IkRecord, error) {
+			created = append(created, zone+" "+record.SourceIdn)
+			record.ID = "new-" + record.SourceIdn
+			return &record, nil
+		},
+		deleter: func(ctx context.Context, zone string, id string) error {
+			if id == "2" {
+				return deleteErr
+			}
+			deleted = append(deleted, zone+" "+id)
+			return nil
+		},
+	}
+	provider := Provider{client: &client}
+
+	_, err := provider.MoveRecords(context.TODO(), "example.com", "shop.example.com", func(rec libdns.Record) bool { return true })
+	if !errors.Is(err, deleteErr) {
+		t.Fatalf("Expected error of deletion, got %v", err)
+	}
+	assertEquals(t, "recreated source record", "example.com a.shop.example.com", created[2])
+	assertEqualsInt(t, "deleted records", 3, len(deleted))
+	assertEquals(t, "deleted copy", "shop.example.com new-a.shop.example.com", deleted[1])
+}
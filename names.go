@@ -0,0 +1,52 @@
+package infomaniak
+
+import (
+	"strings"
+
+	"github.com/libdns/libdns"
+)
+
+// Name of the zone apex as returned by the mappers, equal to the result of libdns.RelativeName
+const apexName = ""
+
+// isApexName returns if the relative name denotes the zone apex, libdns uses "@" or an empty
+// name for the apex while infomaniak uses "."
+func isApexName(name string) bool {
+	return name == "" || name == "@" || name == "."
+}
+
+// toAbsoluteName returns the same result as libdns.AbsoluteName but allocates at most one string
+// instead of concatenating the name step by step, as it is called for every mapped record. Zone
+// suffixes are not cached, as the name and the zone are concatenated in a single allocation anyway
+// and the result is a new string that could not be taken from a cache.
+func toAbsoluteName(name string, zone string) string {
+	if zone == "" {
+		return strings.Trim(name, ".")
+	}
+	if isApexName(name) {
+		return zone
+	}
+	if strings.HasSuffix(name, ".") {
+		return name + zone
+	}
+	return name + "." + zone
+}
+
+// toRelativeName returns the same result as libdns.RelativeName which does not allocate as
+// it only trims the zone, except for the zone apex which is always returned as empty name
+func toRelativeName(fqdn string, zone string) string {
+	name := libdns.RelativeName(fqdn, zone)
+	if isApexName(name) {
+		return apexName
+	}
+	return name
+}
+
+// toInfomaniakSource returns the source of a record relative to its domain as expected by infomaniak, which uses "." for the apex
+func toInfomaniakSource(fqdn string, domain string) string {
+	source := libdns.RelativeName(fqdn, domain)
+	if isApexName(source) {
+		return "."
+	}
+	return source
+}
@@ -0,0 +1,48 @@
+package infomaniak
+
+import (
+	"testing"
+
+	"github.com/libdns/libdns"
+)
+
+func Test_ToAbsoluteName_ReturnsSameResultAsLibdns(t *testing.T) {
+	testCases := []struct{ name, zone string }{
+		{"sub", "example.com"},
+		{"sub.", "example.com"},
+		{"@", "example.com"},
+		{"", "example.com"},
+		{"sub", "example.com."},
+		{".sub.", ""},
+	}
+	for _, testCase := range testCases {
+		assertEquals(t, "AbsoluteName("+testCase.name+", "+testCase.zone+")",
+			libdns.AbsoluteName(testCase.name, testCase.zone), toAbsoluteName(testCase.name, testCase.zone))
+	}
+}
+
+func Test_ToAbsoluteName_AllocatesAtMostOnce(t *testing.T) {
+	allocs := testing.AllocsPerRun(100, func() {
+		toAbsoluteName("_acme-challenge.sub", "example.com")
+	})
+	if allocs > 1 {
+		t.Fatalf("Expected at most 1 allocation, got %f", allocs)
+	}
+}
+
+func Test_ToAbsoluteName_TreatsAllApexNamesEqually(t *testing.T) {
+	for _, name := range []string{"", "@", "."} {
+		assertEquals(t, "AbsoluteName("+name+")", "example.com", toAbsoluteName(name, "example.com"))
+	}
+}
+
+func Test_ToRelativeName_ReturnsEmptyNameForApex(t *testing.T) {
+	assertEquals(t, "RelativeName", "", toRelativeName("example.com", "example.com"))
+	assertEquals(t, "RelativeName", "", toRelativeName(".", "example.com"))
+	assertEquals(t, "RelativeName", "sub", toRelativeName("sub.example.com", "example.com"))
+}
+
+func Test_ToInfomaniakSource_ReturnsDotForApex(t *testing.T) {
+	assertEquals(t, "Source", ".", toInfomaniakSource("example.com", "example.com"))
+	assertEquals(t, "Source", "sub", toInfomaniakSource("sub.example.com", "example.com"))
+}
@@ -0,0 +1,64 @@
+package infomaniak
+
+import (
+	"context"
+
+	"github.com/libdns/libdns"
+)
+
+// Types of records that are used for DNSSEC
+var dnssecTypes = map[RecordType]bool{
+	"DNSKEY":     true,
+	TypeDS:       true,
+	"RRSIG":      true,
+	"NSEC":       true,
+	"NSEC3":      true,
+	"NSEC3PARAM": true,
+	"CDS":        true,
+	"CDNSKEY":    true,
+}
+
+// GetRecordsOptions control which records are returned by GetRecordsWithOptions,
+// the zero value returns the same records as GetRecords
+type GetRecordsOptions struct {
+	// ExcludeDelegations excludes NS records below the zone apex that delegate subzones
+	ExcludeDelegations bool
+
+	// ExcludeApex excludes all records of the zone apex
+	ExcludeApex bool
+
+	// ExcludeDnssec excludes DNSSEC records such as DNSKEY, DS and RRSIG
+	ExcludeDnssec bool
+}
+
+// GetRecordsWithOptions lists the records in the zone that match the given options
+func (p *Provider) GetRecordsWithOptions(ctx context.Context, zone string, options GetRecordsOptions) ([]libdns.Record, error) {
+	records, err := p.GetRecords(ctx, zone)
+	if records == nil {
+		return nil, err
+	}
+
+	result := make([]libdns.Record, 0, len(records))
+	for _, rec := range records {
+		if options.includes(rec) {
+			result = append(result, rec)
+		}
+	}
+	return result, err
+}
+
+// includes returns if the record matches the options
+func (o GetRecordsOptions) includes(rec libdns.Record) bool {
+	apex := isApexName(rec.Name)
+	recType := RecordType(normalizeType(rec.Type))
+	switch {
+	case o.ExcludeApex && apex:
+		return false
+	case o.ExcludeDelegations && !apex && recType == TypeNS:
+		return false
+	case o.ExcludeDnssec && dnssecTypes[recType]:
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,49 @@
+package infomaniak
+
+import (
+	"context"
+	"testing"
+
+	"github.com/libdns/libdns"
+)
+
+// aZoneWithDelegation returns a client for a zone with apex, delegation and DNSSEC records
+func aZoneWithDelegation() *TestClient {
+	return &TestClient{getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+		return []IkRecord{
+			{ID: "1", SourceIdn: "example.com", Type: "NS", Target: "ns11.infomaniak.ch"},
+			{ID: "2", SourceIdn: "sub.example.com", Type: "NS", Target: "ns1.example.org"},
+			{ID: "3", SourceIdn: "sub.example.com", Type: "DS", Target: "12345 13 2 abcdef"},
+			{ID: "4", SourceIdn: "www.example.com", Type: "A", Target: "127.0.0.1"},
+		}, nil
+	}}
+}
+
+func Test_GetRecordsWithOptions_ReturnsAllRecordsByDefault(t *testing.T) {
+	provider := Provider{client: aZoneWithDelegation()}
+	recs, err := provider.GetRecordsWithOptions(context.TODO(), "example.com", GetRecordsOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "records", 4, len(recs))
+}
+
+func Test_GetRecordsWithOptions_ExcludesDelegationsApexAndDnssec(t *testing.T) {
+	provider := Provider{client: aZoneWithDelegation()}
+	recs, err := provider.GetRecordsWithOptions(context.TODO(), "example.com", GetRecordsOptions{ExcludeDelegations: true, ExcludeApex: true, ExcludeDnssec: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "records", 1, len(recs))
+	assertEquals(t, "ID", "4", recs[0].ID)
+}
+
+func Test_GetRecordsOptions_ComparesTypesCaseInsensitively(t *testing.T) {
+	options := GetRecordsOptions{ExcludeDelegations: true, ExcludeDnssec: true}
+	if options.includes(libdns.Record{Name: "sub", Type: "ns", Value: "ns1.example.org"}) {
+		t.Fatalf("Expected lower case NS record to be excluded")
+	}
+	if options.includes(libdns.Record{Name: "sub", Type: " ds", Value: "12345 13 2 abcdef"}) {
+		t.Fatalf("Expected DS record with whitespace to be excluded")
+	}
+}
@@ -0,0 +1,157 @@
+package infomaniak
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/libdns/libdns"
+)
+
+// patchClient updates records partially
+type patchClient struct {
+	TestClient
+	patched   map[string]IkRecordPatch
+	patchErr  error
+	setCalled bool
+}
+
+// PatchRecord records the changes sent for the record with the given ID
+func (c *patchClient) PatchRecord(ctx context.Context, zone string, id string, changes IkRecordPatch) error {
+	if c.patchErr != nil {
+		return c.patchErr
+	}
+	c.patched[id] = changes
+	return nil
+}
+
+func newPatchClient() *patchClient {
+	client := &patchClient{patched: make(map[string]IkRecordPatch)}
+	client.getter = func(ctx context.Context, zone string) ([]IkRecord, error) {
+		return []IkRecord{{ID: "1", Type: "A", SourceIdn: "www.example.com", Target: "1.2.3.4", TtlInSec: 300}}, nil
+	}
+	client.setter = func(ctx context.Context, zone string, record IkRecord) (*IkRecord, error) {
+		client.setCalled = true
+		return &record, nil
+	}
+	return client
+}
+
+func Test_SetRecords_PatchesTtlOnlyChange(t *testing.T) {
+	client := newPatchClient()
+	provider := Provider{client: client}
+
+	records, err := provider.SetRecords(context.TODO(), "example.com", []libdns.Record{{ID: "1", Type: "A", Name: "www", Value: "1.2.3.4", TTL: 3600}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "records", 1, len(records))
+	if client.setCalled {
+		t.Fatalf("Expected record to be patched instead of replaced")
+	}
+	changes := client.patched["1"]
+	if changes.Target != nil || changes.TtlInSec == nil || *changes.TtlInSec != 3600 {
+		t.Fatalf("Expected only TTL to be patched, got %#v", changes)
+	}
+}
+
+func Test_SetRecords_ReplacesRecordIfNameChanges(t *testing.T) {
+	client := newPatchClient()
+	provider := Provider{client: client}
+
+	_, err := provider.SetRecords(context.TODO(), "example.com", []libdns.Record{{ID: "1", Type: "A", Name: "api", Value: "1.2.3.5", TTL: 300}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !client.setCalled || len(client.patched) != 0 {
+		t.Fatalf("Expected record to be replaced instead of patched")
+	}
+}
+
+func Test_SetRecords_FallsBackToReplacingRecordIfPatchIsNotAllowed(t *testing.T) {
+	client := newPatchClient()
+	client.patchErr = newApiError(http.StatusMethodNotAllowed, nil, json.RawMessage(`{"code":"method_not_allowed"}`))
+	provider := Provider{client: client}
+
+	_, err := provider.SetRecords(context.TODO(), "example.com", []libdns.Record{{ID: "1", Type: "A", Name: "www", Value: "1.2.3.5", TTL: 300}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !client.setCalled {
+		t.Fatalf("Expected record to be replaced if partial updates are not allowed")
+	}
+}
+
+// forwardingMiddleware passes partial updates on to the decorated client
+type forwardingMiddleware struct {
+	IkClient
+}
+
+// PatchRecord forwards the partial update
+func (c *forwardingMiddleware) PatchRecord(ctx context.Context, zone string, id string, changes IkRecordPatch) error {
+	return c.IkClient.(IkRecordPatcher).PatchRecord(ctx, zone, id, changes)
+}
+
+func Test_SetRecords_PatchesThroughDegradationAndForwardingMiddleware(t *testing.T) {
+	client := newPatchClient()
+	middleware := func(next IkClient) IkClient {
+		return &forwardingMiddleware{IkClient: next}
+	}
+	provider := Provider{client: client, DegradeAfterFailures: 3, ClientMiddlewares: []ClientMiddleware{middleware}}
+
+	records, err := provider.SetRecords(context.TODO(), "example.com", []libdns.Record{{ID: "1", Type: "A", Name: "www", Value: "1.2.3.5", TTL: 300}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.setCalled || client.patched["1"].Target == nil {
+		t.Fatalf("Expected record to be patched through the decorated client")
+	}
+	assertEquals(t, "value", "1.2.3.5", records[0].Value)
+}
+
+func Test_SetRecords_ReplacesRecordThroughMiddlewareWithoutPartialUpdates(t *testing.T) {
+	client := newPatchClient()
+	middleware := func(next IkClient) IkClient {
+		return &namingClient{IkClient: next, calls: &[]string{}}
+	}
+	provider := Provider{client: client, DegradeAfterFailures: 3, ClientMiddlewares: []ClientMiddleware{middleware}}
+
+	_, err := provider.SetRecords(context.TODO(), "example.com", []libdns.Record{{ID: "1", Type: "A", Name: "www", Value: "1.2.3.5", TTL: 300}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !client.setCalled || len(client.patched) != 0 {
+		t.Fatalf("Expected record to be replaced through the middleware")
+	}
+}
+
+func Test_SetRecords_RejectsPartialUpdateOfReadOnlyClient(t *testing.T) {
+	client := newPatchClient()
+	provider := Provider{client: client, ClientMiddlewares: []ClientMiddleware{ReadOnly}}
+
+	_, err := provider.SetRecords(context.TODO(), "example.com", []libdns.Record{{ID: "1", Type: "A", Name: "www", Value: "1.2.3.5", TTL: 300}})
+	if !errors.Is(err, ErrReadOnly) || len(client.patched) != 0 {
+		t.Fatalf("Expected ErrReadOnly without patch, got %v", err)
+	}
+}
+
+func Test_SetRecords_ComparesEscapedTxtValues(t *testing.T) {
+	client := newPatchClient()
+	client.getter = func(ctx context.Context, zone string) ([]IkRecord, error) {
+		return []IkRecord{{ID: "1", Type: "TXT", SourceIdn: "example.com", Target: "v=spf1 -all", TtlInSec: 300}}, nil
+	}
+	provider := Provider{client: client}
+
+	records, err := provider.SetRecords(context.TODO(), "example.com", []libdns.Record{{ID: "1", Type: "TXT", Name: "@", Value: "v=spf1 -all", TTL: 3600}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes := client.patched["1"]
+	if changes.Target != nil || changes.TtlInSec == nil {
+		t.Fatalf("Expected only TTL to be patched, got %#v", changes)
+	}
+	assertEquals(t, "value", "v=spf1 -all", records[0].Value)
+	assertEqualsInt(t, "TTL", 3600, int(records[0].TTL))
+}
@@ -0,0 +1,47 @@
+package infomaniak
+
+import (
+	"context"
+	"sync"
+)
+
+// Maximum number of zones that are primed concurrently
+const maxConcurrentPrimes = 8
+
+// Prime resolves the infomaniak domains of the given zones and loads their records concurrently,
+// so that the record cache is warm for the zones, e.g. before certificates for many domains are issued.
+// Records are only cached if RecordCacheTtl is set. Zones that could not be primed are reported by a *PrimeError.
+func (p *Provider) Prime(ctx context.Context, zones []string) error {
+	errs := make(map[string]error)
+	var errsMu sync.Mutex
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, maxConcurrentPrimes)
+
+	for _, zone := range zones {
+		zone = getWithoutTrailingDot(zone)
+		wg.Add(1)
+		go func(zone string) {
+			defer wg.Done()
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+				errsMu.Lock()
+				errs[zone] = ctx.Err()
+				errsMu.Unlock()
+				return
+			}
+			if _, err := p.getDnsRecordsForZone(ctx, zone); err != nil {
+				errsMu.Lock()
+				errs[zone] = err
+				errsMu.Unlock()
+			}
+		}(zone)
+	}
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return &PrimeError{Errors: errs}
+	}
+	return nil
+}
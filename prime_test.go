@@ -0,0 +1,55 @@
+package infomaniak
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_Prime_WarmsRecordCacheOfAllZones(t *testing.T) {
+	var calls int
+	var mu sync.Mutex
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			calls++
+			return []IkRecord{{ID: "1", Type: "A", SourceIdn: "www." + zone}}, nil
+		},
+	}
+	provider := Provider{client: &client, RecordCacheTtl: time.Minute}
+
+	err := provider.Prime(context.TODO(), []string{"example.com.", "example.org."})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = provider.GetRecords(context.TODO(), "example.org.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "API calls", 2, calls)
+}
+
+func Test_Prime_ReturnsErrorsOfFailedZones(t *testing.T) {
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			if zone == "example.org" {
+				return nil, errors.New("unknown zone")
+			}
+			return []IkRecord{}, nil
+		},
+	}
+	provider := Provider{client: &client}
+
+	err := provider.Prime(context.TODO(), []string{"example.com", "example.org"})
+	var primeErr *PrimeError
+	if !errors.As(err, &primeErr) {
+		t.Fatalf("Expected *PrimeError, got %v", err)
+	}
+	assertEqualsInt(t, "failed zones", 1, len(primeErr.Errors))
+	if primeErr.Errors["example.org"] == nil {
+		t.Fatalf("Expected error of zone example.org, got %v", primeErr.Errors)
+	}
+}
@@ -0,0 +1,366 @@
+package infomaniak
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Public recursive resolvers queried by a PropagationChecker if none are configured
+var defaultPublicResolvers = []string{"1.1.1.1:53", "8.8.8.8:53"}
+
+// Default interval between two propagation checks
+const defaultPropagationInterval = 5 * time.Second
+
+// Duration for which newly discovered nameservers of a zone are cached, it is doubled every time they are found unchanged
+const minNameserverCacheTtl = 30 * time.Second
+
+// Maximum duration for which the nameservers of a zone are cached if not configured otherwise
+const defaultNameserverCacheTtl = time.Hour
+
+// nameserverCacheEntry nameservers of a zone cached by a PropagationChecker
+type nameserverCacheEntry struct {
+	nameservers []string
+	ttl         time.Duration
+	expiresAt   time.Time
+}
+
+// PropagationChecker verifies that a record is served by the authoritative nameservers of its zone and optionally
+// by public recursive resolvers, e.g. before an ACME CA that resolves through public resolvers is asked to validate a DNS-01 challenge
+type PropagationChecker struct {
+	// CheckPublicResolvers if set, the record additionally has to be returned by a quorum of public resolvers
+	CheckPublicResolvers bool
+
+	// PublicResolvers queried by address, e.g. "1.1.1.1:53" - 1.1.1.1 and 8.8.8.8 are used if not set
+	PublicResolvers []string
+
+	// Quorum number of public resolvers that have to return the record, all public resolvers have to return it if not set
+	Quorum int
+
+	// Interval between two checks while waiting, 5 seconds if not set
+	Interval time.Duration
+
+	// QueryTimeout of a single query, no timeout apart from the context's if not set
+	QueryTimeout time.Duration
+
+	// Provider optional provider through which the NS records at the apex of the zone are read from the API, so that zones hosted
+	// by infomaniak behind vanity nameservers are checked at the right servers - the nameservers are looked up in DNS if not set,
+	// if the API call fails or if the zone has no NS records at its apex
+	Provider *Provider
+
+	// NameserverCacheTtl maximum duration for which the nameservers of a zone are cached, 1 hour if not set. Newly discovered
+	// nameservers are cached for 30 seconds and the duration is doubled every time they are found unchanged, up to the maximum.
+	NameserverCacheTtl time.Duration
+
+	// lookupNameservers returns the authoritative nameservers of a zone, replaceable for tests
+	lookupNameservers func(ctx context.Context, zone string) ([]string, error)
+
+	// lookup queries the values of a record from a server, replaceable for tests
+	lookup func(ctx context.Context, server string, fqdn string, recType string) ([]string, error)
+
+	// cached nameservers by zone
+	nameservers map[string]nameserverCacheEntry
+
+	// mutex to prevent race conditions on the cached nameservers
+	nameserversMu sync.Mutex
+}
+
+// Check returns if the record with the given fully qualified name, type and value is returned by all authoritative nameservers
+// of the zone and, if CheckPublicResolvers is set, by at least a quorum of the public resolvers.
+// Supported types are A, AAAA, CNAME, MX, NS and TXT.
+func (c *PropagationChecker) Check(ctx context.Context, zone string, fqdn string, recType string, value string) (bool, error) {
+	zone = getWithoutTrailingDot(zone)
+	fqdn = getWithoutTrailingDot(fqdn)
+	recType = normalizeType(recType)
+
+	nameservers, err := c.getNameservers(ctx, zone)
+	if err != nil {
+		return false, fmt.Errorf("could not look up nameservers of zone '%s': %v", zone, err)
+	}
+	if len(nameservers) <= 0 {
+		return false, fmt.Errorf("no nameservers found for zone '%s'", zone)
+	}
+	servers := make([]string, 0, len(nameservers))
+	for _, ns := range nameservers {
+		servers = append(servers, net.JoinHostPort(getWithoutTrailingDot(ns), "53"))
+	}
+	ok, err := c.checkQuorum(ctx, servers, len(servers), fqdn, recType, value)
+	if !ok || err != nil || !c.CheckPublicResolvers {
+		return ok, err
+	}
+
+	resolvers := c.getPublicResolvers()
+	quorum := c.Quorum
+	if quorum <= 0 || quorum > len(resolvers) {
+		quorum = len(resolvers)
+	}
+	return c.checkQuorum(ctx, resolvers, quorum, fqdn, recType, value)
+}
+
+// Wait checks the propagation of the record repeatedly until it is propagated or the context is done
+func (c *PropagationChecker) Wait(ctx context.Context, zone string, fqdn string, recType string, value string) error {
+	interval := c.Interval
+	if interval <= 0 {
+		interval = defaultPropagationInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var lastErr error
+	for {
+		ok, err := c.Check(ctx, zone, fqdn, recType, value)
+		if ok {
+			return nil
+		}
+		if err != nil {
+			lastErr = err
+		}
+		select {
+		case <-ctx.Done():
+			if lastErr != nil {
+				return fmt.Errorf("%s record '%s' did not propagate: %v (last error: %v)", recType, fqdn, ctx.Err(), lastErr)
+			}
+			return fmt.Errorf("%s record '%s' did not propagate: %v", recType, fqdn, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkQuorum queries all servers concurrently and returns if at least quorum of them returned the value,
+// an error is only returned if so many queries failed that the quorum cannot be reached
+func (c *PropagationChecker) checkQuorum(ctx context.Context, servers []string, quorum int, fqdn string, recType string, value string) (bool, error) {
+	var found, failed int
+	var lastErr error
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	lookup := c.getLookup()
+	for _, server := range servers {
+		wg.Add(1)
+		go func(server string) {
+			defer wg.Done()
+			queryCtx := ctx
+			if c.QueryTimeout > 0 {
+				var cancel context.CancelFunc
+				queryCtx, cancel = context.WithTimeout(ctx, c.QueryTimeout)
+				defer cancel()
+			}
+			values, err := lookup(queryCtx, server, fqdn, recType)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed++
+				lastErr = fmt.Errorf("query to %s failed: %v", server, err)
+			} else if containsRecordValue(values, recType, value) {
+				found++
+			}
+		}(server)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	if found >= quorum {
+		return true, nil
+	}
+	if len(servers)-failed < quorum {
+		return false, lastErr
+	}
+	return false, nil
+}
+
+// getNameservers returns the cached nameservers of the zone or discovers them if they are not cached or the cache expired
+func (c *PropagationChecker) getNameservers(ctx context.Context, zone string) ([]string, error) {
+	c.nameserversMu.Lock()
+	entry, ok := c.nameservers[zone]
+	c.nameserversMu.Unlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.nameservers, nil
+	}
+
+	nameservers, err := c.discoverNameservers(ctx, zone)
+	if err != nil || len(nameservers) <= 0 {
+		return nameservers, err
+	}
+
+	ttl := minNameserverCacheTtl
+	if ok && sameNameservers(entry.nameservers, nameservers) {
+		ttl = entry.ttl * 2
+	}
+	maxTtl := c.NameserverCacheTtl
+	if maxTtl <= 0 {
+		maxTtl = defaultNameserverCacheTtl
+	}
+	if ttl > maxTtl {
+		ttl = maxTtl
+	}
+
+	c.nameserversMu.Lock()
+	defer c.nameserversMu.Unlock()
+	if c.nameservers == nil {
+		c.nameservers = make(map[string]nameserverCacheEntry)
+	}
+	c.nameservers[zone] = nameserverCacheEntry{nameservers: nameservers, ttl: ttl, expiresAt: time.Now().Add(ttl)}
+	return nameservers, nil
+}
+
+// discoverNameservers returns the nameservers of the NS records at the apex of the zone as known by the API if a provider
+// is set, otherwise or if there are none the authoritative nameservers of the zone are looked up in DNS
+func (c *PropagationChecker) discoverNameservers(ctx context.Context, zone string) ([]string, error) {
+	if c.Provider != nil {
+		recs, err := c.Provider.getRecords(ctx, zone)
+		var mappingErr *RecordMappingError
+		if err == nil || errors.As(err, &mappingErr) {
+			nameservers := make([]string, 0)
+			for _, rec := range recs {
+				if RecordType(normalizeType(rec.Type)) == TypeNS && isApexName(rec.Name) {
+					nameservers = append(nameservers, rec.Value)
+				}
+			}
+			if len(nameservers) > 0 {
+				return nameservers, nil
+			}
+		}
+	}
+	return c.getLookupNameservers()(ctx, zone)
+}
+
+// sameNameservers returns if both lists contain the same host names regardless of their order and trailing dots
+func sameNameservers(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	normalize := func(nameservers []string) []string {
+		normalized := make([]string, 0, len(nameservers))
+		for _, ns := range nameservers {
+			normalized = append(normalized, normalizeName(ns))
+		}
+		sort.Strings(normalized)
+		return normalized
+	}
+	normalizedA, normalizedB := normalize(a), normalize(b)
+	for i := range normalizedA {
+		if normalizedA[i] != normalizedB[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// getPublicResolvers returns the configured public resolvers or the default ones
+func (c *PropagationChecker) getPublicResolvers() []string {
+	if len(c.PublicResolvers) > 0 {
+		return c.PublicResolvers
+	}
+	return defaultPublicResolvers
+}
+
+// getLookupNameservers returns the function used to look up the authoritative nameservers of a zone
+func (c *PropagationChecker) getLookupNameservers() func(ctx context.Context, zone string) ([]string, error) {
+	if c.lookupNameservers != nil {
+		return c.lookupNameservers
+	}
+	return lookupNameservers
+}
+
+// getLookup returns the function used to query servers
+func (c *PropagationChecker) getLookup() func(ctx context.Context, server string, fqdn string, recType string) ([]string, error) {
+	if c.lookup != nil {
+		return c.lookup
+	}
+	return lookupValues
+}
+
+// lookupNameservers returns the host names of the authoritative nameservers of the zone using the system's resolver
+func lookupNameservers(ctx context.Context, zone string) ([]string, error) {
+	nss, err := net.DefaultResolver.LookupNS(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+	hosts := make([]string, 0, len(nss))
+	for _, ns := range nss {
+		hosts = append(hosts, ns.Host)
+	}
+	return hosts, nil
+}
+
+// lookupValues queries the values of the record with the given name and type from the given server
+func lookupValues(ctx context.Context, server string, fqdn string, recType string) ([]string, error) {
+	r := newResolver(server, 0)
+	recordType := RecordType(normalizeType(recType))
+	switch recordType {
+	case TypeA, TypeAAAA:
+		network := "ip4"
+		if recordType == TypeAAAA {
+			network = "ip6"
+		}
+		ips, err := r.LookupIP(ctx, network, fqdn)
+		if err != nil {
+			return nil, err
+		}
+		values := make([]string, 0, len(ips))
+		for _, ip := range ips {
+			values = append(values, ip.String())
+		}
+		return values, nil
+	case TypeCNAME:
+		cname, err := r.LookupCNAME(ctx, fqdn)
+		if err != nil {
+			return nil, err
+		}
+		return []string{cname}, nil
+	case TypeMX:
+		mxs, err := r.LookupMX(ctx, fqdn)
+		if err != nil {
+			return nil, err
+		}
+		values := make([]string, 0, len(mxs))
+		for _, mx := range mxs {
+			values = append(values, mx.Host)
+		}
+		return values, nil
+	case TypeNS:
+		nss, err := r.LookupNS(ctx, fqdn)
+		if err != nil {
+			return nil, err
+		}
+		values := make([]string, 0, len(nss))
+		for _, ns := range nss {
+			values = append(values, ns.Host)
+		}
+		return values, nil
+	case TypeTXT:
+		return r.LookupTXT(ctx, fqdn)
+	default:
+		return nil, fmt.Errorf("propagation checks of %s records are not supported", recType)
+	}
+}
+
+// containsRecordValue returns if one of the values of a record of the given type equals the expected value - TXT values are
+// compared exactly, as e.g. ACME challenge tokens are case-sensitive, other values as by containsValue
+func containsRecordValue(values []string, recType string, expected string) bool {
+	if RecordType(normalizeType(recType)) != TypeTXT {
+		return containsValue(values, expected)
+	}
+	for _, value := range values {
+		if value == expected {
+			return true
+		}
+	}
+	return false
+}
+
+// containsValue returns if one of the values equals the expected value, host names are compared without trailing dot
+func containsValue(values []string, expected string) bool {
+	for _, value := range values {
+		if strings.EqualFold(strings.TrimSuffix(value, "."), strings.TrimSuffix(expected, ".")) {
+			return true
+		}
+	}
+	return false
+}
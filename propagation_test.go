@@ -0,0 +1,160 @@
+package infomaniak
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// newTestPropagationChecker returns a checker whose zone has the nameservers ns1 and ns2 and whose servers return the values of the given map
+func newTestPropagationChecker(valuesByServer map[string][]string) *PropagationChecker {
+	return &PropagationChecker{
+		lookupNameservers: func(ctx context.Context, zone string) ([]string, error) {
+			return []string{"ns1.example.com.", "ns2.example.com."}, nil
+		},
+		lookup: func(ctx context.Context, server string, fqdn string, recType string) ([]string, error) {
+			values, ok := valuesByServer[server]
+			if !ok {
+				return nil, errors.New("no such host")
+			}
+			return values, nil
+		},
+	}
+}
+
+func Test_PropagationChecker_Check_RequiresAllAuthoritativeNameservers(t *testing.T) {
+	checker := newTestPropagationChecker(map[string][]string{
+		"ns1.example.com:53": {"token"},
+		"ns2.example.com:53": {"other"},
+	})
+
+	ok, err := checker.Check(context.TODO(), "example.com.", "_acme-challenge.example.com.", "TXT", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Fatalf("Expected record to not be propagated")
+	}
+}
+
+func Test_PropagationChecker_Check_ComparesTxtValuesExactly(t *testing.T) {
+	checker := newTestPropagationChecker(map[string][]string{
+		"ns1.example.com:53": {"Token"},
+		"ns2.example.com:53": {"Token"},
+	})
+
+	ok, err := checker.Check(context.TODO(), "example.com.", "_acme-challenge.example.com.", "TXT", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Fatalf("Expected TXT value with different case to not be propagated")
+	}
+	ok, err = checker.Check(context.TODO(), "example.com.", "www.example.com.", "CNAME", "token.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatalf("Expected host name to be compared case-insensitively")
+	}
+}
+
+func Test_PropagationChecker_Check_QueriesPublicResolversWithQuorum(t *testing.T) {
+	checker := newTestPropagationChecker(map[string][]string{
+		"ns1.example.com:53": {"token"},
+		"ns2.example.com:53": {"token"},
+		"1.1.1.1:53":         {"token"},
+		"8.8.8.8:53":         {},
+		"9.9.9.9:53":         {"token"},
+	})
+	checker.CheckPublicResolvers = true
+	checker.PublicResolvers = []string{"1.1.1.1:53", "8.8.8.8:53", "9.9.9.9:53"}
+
+	ok, err := checker.Check(context.TODO(), "example.com", "_acme-challenge.example.com", "TXT", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Fatalf("Expected record to not be propagated to all public resolvers")
+	}
+
+	checker.Quorum = 2
+	ok, err = checker.Check(context.TODO(), "example.com", "_acme-challenge.example.com", "TXT", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatalf("Expected record to be propagated to a quorum of public resolvers")
+	}
+}
+
+func Test_PropagationChecker_Check_ReturnsErrorIfQuorumCannotBeReached(t *testing.T) {
+	checker := newTestPropagationChecker(map[string][]string{
+		"ns1.example.com:53": {"token"},
+		"ns2.example.com:53": {"token"},
+	})
+	checker.CheckPublicResolvers = true
+
+	_, err := checker.Check(context.TODO(), "example.com", "_acme-challenge.example.com", "TXT", "token")
+	if err == nil {
+		t.Fatalf("Expected error as no public resolver could be queried")
+	}
+}
+
+func Test_PropagationChecker_Wait_StopsWhenContextIsDone(t *testing.T) {
+	checker := newTestPropagationChecker(map[string][]string{
+		"ns1.example.com:53": {},
+		"ns2.example.com:53": {},
+	})
+	checker.Interval = time.Millisecond
+	ctx, cancel := context.WithTimeout(context.TODO(), 20*time.Millisecond)
+	defer cancel()
+
+	err := checker.Wait(ctx, "example.com", "_acme-challenge.example.com", "TXT", "token")
+	if err == nil {
+		t.Fatalf("Expected error as record never propagated")
+	}
+}
+
+func Test_PropagationChecker_Check_CachesNameserversWithGrowingTtl(t *testing.T) {
+	lookups := 0
+	checker := newTestPropagationChecker(map[string][]string{"ns1.example.com:53": {"token"}, "ns2.example.com:53": {"token"}})
+	checker.lookupNameservers = func(ctx context.Context, zone string) ([]string, error) {
+		lookups++
+		return []string{"ns1.example.com.", "ns2.example.com."}, nil
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := checker.Check(context.TODO(), "example.com", "_acme-challenge.example.com", "TXT", "token"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assertEqualsInt(t, "number of lookups", 1, lookups)
+	assertEquals(t, "ttl", minNameserverCacheTtl.String(), checker.nameservers["example.com"].ttl.String())
+
+	entry := checker.nameservers["example.com"]
+	entry.expiresAt = time.Now().Add(-time.Second)
+	checker.nameservers["example.com"] = entry
+	if _, err := checker.Check(context.TODO(), "example.com", "_acme-challenge.example.com", "TXT", "token"); err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "number of lookups", 2, lookups)
+	assertEquals(t, "ttl", (2 * minNameserverCacheTtl).String(), checker.nameservers["example.com"].ttl.String())
+}
+
+func Test_PropagationChecker_Check_UsesNameserversOfApi(t *testing.T) {
+	client := TestClient{getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+		return []IkRecord{{ID: "1", Type: "NS", SourceIdn: "example.com", Target: "dns1.vanity.example."}}, nil
+	}}
+	checker := newTestPropagationChecker(map[string][]string{"dns1.vanity.example:53": {"token"}})
+	checker.Provider = &Provider{client: &client}
+
+	ok, err := checker.Check(context.TODO(), "example.com", "_acme-challenge.example.com", "TXT", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatalf("Expected record to be found at the nameserver of the API")
+	}
+}
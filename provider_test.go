@@ -2,8 +2,11 @@ package infomaniak
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/libdns/libdns"
 )
@@ -15,8 +18,11 @@ type TestClient struct {
 	deleter func(ctx context.Context, zone string, id string) error
 }
 
-// GetDnsRecordsForZone implementation to fulfill IkClient interface
+// GetDnsRecordsForZone implementation to fulfill IkClient interface - returns no records if no getter is set
 func (c *TestClient) GetDnsRecordsForZone(ctx context.Context, zone string) ([]IkRecord, error) {
+	if c.getter == nil {
+		return []IkRecord{}, nil
+	}
 	return c.getter(ctx, zone)
 }
 
@@ -169,6 +175,21 @@ func Test_AppendRecords_RemovesTrailingDotsFromZone(t *testing.T) {
 	provider.AppendRecords(context.TODO(), zoneWithoutSuffix+".", []libdns.Record{{Name: "@"}})
 }
 
+func Test_NewProviderWithClient_UsesGivenClient(t *testing.T) {
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			return []IkRecord{{ID: "1", Type: "A", SourceIdn: "www.example.com", Target: "192.0.2.1"}}, nil
+		},
+	}
+	provider := NewProviderWithClient(&client)
+	records, err := provider.GetRecords(context.TODO(), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "records", 1, len(records))
+	assertEquals(t, "ID", "1", records[0].ID)
+}
+
 func Test_SetRecords_CreatesNewRecord(t *testing.T) {
 	methodCalled := false
 	client := TestClient{
@@ -221,6 +242,26 @@ func Test_SetRecords_UpdatesExistingRecordById(t *testing.T) {
 	}
 }
 
+func Test_SetRecords_SendsDescriptionOfExistingRecordBack(t *testing.T) {
+	var written IkRecord
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			return []IkRecord{{ID: "1", Type: "TXT", SourceIdn: "test.example.com", Target: "old", DescriptionRaw: map[string]json.RawMessage{"comment": []byte(`"keep"`)}}}, nil
+		},
+		setter: func(ctx context.Context, zone string, record IkRecord) (*IkRecord, error) {
+			written = record
+			return &record, nil
+		},
+	}
+	provider := Provider{client: &client}
+	_, err := provider.SetRecords(context.TODO(), "example.com", []libdns.Record{{Type: "TXT", Name: "test", Value: "new"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "ID", "1", written.ID)
+	assertEquals(t, "comment", `"keep"`, string(written.DescriptionRaw["comment"]))
+}
+
 func Test_SetRecords_UpdatesExistingRecordByNameAndTypeIfNoIdProvided(t *testing.T) {
 	id := "2247"
 	recType := "MX"
@@ -352,3 +393,374 @@ func Test_DeleteRecords_DeletesRecordWithId(t *testing.T) {
 		t.Fatalf("Expected 1 deleted record, got %d", len(deletedRecs))
 	}
 }
+
+func Test_AppendRecords_ReturnsErrorIfCnameWouldCoexistWithOtherRecords(t *testing.T) {
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			return []IkRecord{{ID: "1", SourceIdn: "www.example.com", Type: "A", Target: "127.0.0.1"}}, nil
+		},
+		setter: func(ctx context.Context, zone string, record IkRecord) (*IkRecord, error) {
+			t.Fatalf("Expected that conflicting record is not created")
+			return nil, nil
+		},
+	}
+	provider := Provider{client: &client}
+	_, err := provider.AppendRecords(context.TODO(), "example.com", []libdns.Record{{Name: "www", Type: "CNAME", Value: "example.org"}})
+
+	var conflictErr *CnameConflictError
+	if !errors.As(err, &conflictErr) {
+		t.Fatalf("Expected CnameConflictError, got %#v", err)
+	}
+	assertEquals(t, "Name", "www", conflictErr.Name)
+}
+
+func Test_SetRecords_ReturnsErrorIfRecordWouldCoexistWithCname(t *testing.T) {
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			return []IkRecord{{ID: "1", SourceIdn: "www.example.com", Type: "CNAME", Target: "example.org"}}, nil
+		},
+		setter: func(ctx context.Context, zone string, record IkRecord) (*IkRecord, error) {
+			t.Fatalf("Expected that conflicting record is not set")
+			return nil, nil
+		},
+	}
+	provider := Provider{client: &client}
+	_, err := provider.SetRecords(context.TODO(), "example.com", []libdns.Record{{Name: "www", Type: "TXT", Value: "test"}})
+
+	var conflictErr *CnameConflictError
+	if !errors.As(err, &conflictErr) {
+		t.Fatalf("Expected CnameConflictError, got %#v", err)
+	}
+}
+
+func Test_SetRecords_ReturnsErrorInStrictModeIfConstraintIsViolated(t *testing.T) {
+	client := TestClient{
+		setter: func(ctx context.Context, zone string, record IkRecord) (*IkRecord, error) {
+			t.Fatalf("Expected that invalid record is not set")
+			return nil, nil
+		},
+	}
+	provider := Provider{client: &client, StrictMode: true}
+	_, err := provider.SetRecords(context.TODO(), "example.com", []libdns.Record{{Type: "CAA", Value: "0 unknown test"}})
+
+	var violationErr *ConstraintViolationError
+	if !errors.As(err, &violationErr) {
+		t.Fatalf("Expected ConstraintViolationError, got %#v", err)
+	}
+}
+
+func Test_GetRecords_RemovesRecordsListedWithSameId(t *testing.T) {
+	client := TestClient{getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+		return []IkRecord{
+			{ID: "1", Type: "A", SourceIdn: "www.example.com", Target: "127.0.0.1"},
+			{ID: "1", Type: "A", SourceIdn: "www.example.com", Target: "127.0.0.1"},
+			{ID: "2", Type: "A", SourceIdn: "www.example.com", Target: "127.0.0.1"},
+			{ID: "3", Type: "A", SourceIdn: "www.example.com", Target: "127.0.0.2"},
+		}, nil
+	}}
+	provider := Provider{client: &client}
+	result, err := provider.GetRecords(context.TODO(), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 3 {
+		t.Fatalf("Expected %d records, got %d", 3, len(result))
+	}
+	assertEquals(t, "id of first record", "1", result[0].ID)
+	assertEquals(t, "id of second record", "2", result[1].ID)
+}
+
+func Test_GetRecords_KeepsDuplicateRecordsIfDeduplicationIsDisabled(t *testing.T) {
+	client := TestClient{getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+		return []IkRecord{{ID: "1", Type: "A"}, {ID: "1", Type: "A"}}, nil
+	}}
+	provider := Provider{client: &client, DisableDeduplication: true}
+	result, err := provider.GetRecords(context.TODO(), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 2 {
+		t.Fatalf("Expected %d records, got %d", 2, len(result))
+	}
+}
+
+func Test_SetRecords_LoadsRecordsOnlyOnceIfCacheIsEnabled(t *testing.T) {
+	calls := 0
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			calls++
+			return []IkRecord{}, nil
+		},
+		setter: func(ctx context.Context, zone string, record IkRecord) (*IkRecord, error) {
+			return &record, nil
+		},
+	}
+	provider := Provider{client: &client, RecordCacheTtl: time.Minute}
+	_, err := provider.SetRecords(context.TODO(), "example.com", []libdns.Record{{Name: "www", Type: "A"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "calls", 1, calls)
+}
+
+func Test_GetRecords_DoesNotCacheRecordsListedWithoutDescriptions(t *testing.T) {
+	calls := 0
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			calls++
+			return []IkRecord{}, nil
+		},
+	}
+	provider := Provider{client: &client, RecordCacheTtl: time.Minute}
+	if _, err := provider.GetRecords(WithoutRecordDescriptions(context.TODO()), "example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := provider.GetRecords(context.TODO(), "example.com"); err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "calls", 2, calls)
+}
+
+// aSyntheticZone returns a client for a zone with the given number of records
+func aSyntheticZone(numberOfRecords int) *TestClient {
+	records := make([]IkRecord, 0, numberOfRecords)
+	for i := 0; i < numberOfRecords; i++ {
+		records = append(records, IkRecord{
+			ID:        strconv.Itoa(i),
+			Type:      "TXT",
+			SourceIdn: "name" + strconv.Itoa(i) + ".example.com",
+			Target:    "value" + strconv.Itoa(i),
+			TtlInSec:  300,
+		})
+	}
+	return &TestClient{
+		getter:  func(ctx context.Context, zone string) ([]IkRecord, error) { return records, nil },
+		deleter: func(ctx context.Context, zone string, id string) error { return nil },
+	}
+}
+
+func Benchmark_GetRecordsByCoordinates_10kRecords(b *testing.B) {
+	provider := Provider{client: aSyntheticZone(10000)}
+	recsToSet := []libdns.Record{{Type: "TXT", Name: "name5000", Value: "new"}, {Type: "TXT", Name: "_acme-challenge", Value: "token"}}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := provider.getRecordsByCoordinates(context.TODO(), "example.com", recsToSet)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func Benchmark_DeleteRecords_100RecordsOf10kRecords(b *testing.B) {
+	provider := Provider{client: aSyntheticZone(10000)}
+	recsToDelete := make([]libdns.Record, 0, 100)
+	for i := 0; i < 100; i++ {
+		recsToDelete = append(recsToDelete, libdns.Record{Type: "TXT", Name: "name" + strconv.Itoa(i*100)})
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := provider.DeleteRecords(context.TODO(), "example.com", recsToDelete)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func Test_DeleteRecords_TreatsAlreadyDeletedRecordsAsSuccess(t *testing.T) {
+	client := TestClient{
+		deleter: func(ctx context.Context, zone string, id string) error {
+			if id == "1" {
+				return &ApiError{StatusCode: 404}
+			}
+			return nil
+		},
+	}
+	provider := Provider{client: &client}
+	deletedRecs, err := provider.DeleteRecords(context.TODO(), "example.com", []libdns.Record{{ID: "1"}, {ID: "2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "deleted records", 1, len(deletedRecs))
+	assertEquals(t, "deleted ID", "2", deletedRecs[0].ID)
+
+	provider = Provider{client: &client, FailOnMissingRecords: true}
+	_, err = provider.DeleteRecords(context.TODO(), "example.com", []libdns.Record{{ID: "1"}})
+	if err == nil {
+		t.Fatalf("Expected error for missing record")
+	}
+}
+
+func Test_DeleteRecords_OnlyDeletesRecordsWithMatchingValueIfValueIsGiven(t *testing.T) {
+	deletedIds := make([]string, 0)
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			return []IkRecord{
+				{ID: "1", SourceIdn: "_acme-challenge.example.com", Type: "TXT", Target: "token1"},
+				{ID: "2", SourceIdn: "_acme-challenge.example.com", Type: "TXT", Target: "token2"},
+			}, nil
+		},
+		deleter: func(ctx context.Context, zone string, id string) error {
+			deletedIds = append(deletedIds, id)
+			return nil
+		},
+	}
+	provider := Provider{client: &client}
+	_, err := provider.DeleteRecords(context.TODO(), "example.com", []libdns.Record{{Name: "_acme-challenge", Type: "TXT", Value: "token2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deletedIds) != 1 || deletedIds[0] != "2" {
+		t.Fatalf("Expected only record with ID 2 to be deleted, got %v", deletedIds)
+	}
+}
+
+func Test_DeleteRecords_DeletesNoRecordIfNoTypeIsGiven(t *testing.T) {
+	deletedIds := make([]string, 0)
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			return []IkRecord{
+				{ID: "1", SourceIdn: "example.com", Type: "NS"},
+				{ID: "2", SourceIdn: "example.com", Type: "MX"},
+			}, nil
+		},
+		deleter: func(ctx context.Context, zone string, id string) error {
+			deletedIds = append(deletedIds, id)
+			return nil
+		},
+	}
+	provider := Provider{client: &client}
+	deletedRecs, err := provider.DeleteRecords(context.TODO(), "example.com", []libdns.Record{{Name: ""}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "deleted records", 0, len(deletedIds))
+	assertEqualsInt(t, "returned records", 0, len(deletedRecs))
+}
+
+func Test_DeleteRecords_DeletesAllTypesOfNameIfNoTypeIsGivenWithMatchAnyType(t *testing.T) {
+	deletedIds := make([]string, 0)
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			return []IkRecord{
+				{ID: "1", SourceIdn: "www.example.com", Type: "A"},
+				{ID: "2", SourceIdn: "www.example.com", Type: "AAAA"},
+				{ID: "3", SourceIdn: "mail.example.com", Type: "A"},
+			}, nil
+		},
+		deleter: func(ctx context.Context, zone string, id string) error {
+			deletedIds = append(deletedIds, id)
+			return nil
+		},
+	}
+	provider := Provider{client: &client, DeleteMatcher: MatchAnyType}
+	_, err := provider.DeleteRecords(context.TODO(), "example.com", []libdns.Record{{Name: "WWW"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "deleted records", 2, len(deletedIds))
+}
+
+func Benchmark_GetRecordsToDelete_1kRecordsOf10kRecords(b *testing.B) {
+	provider := Provider{client: aSyntheticZone(10000)}
+	recsToDelete := make([]libdns.Record, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		recsToDelete = append(recsToDelete, libdns.Record{Type: "TXT", Name: "name" + strconv.Itoa(i*10)})
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _, err := provider.getRecordsToDelete(context.TODO(), "example.com", recsToDelete)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func Test_SetRecords_StopsAndReturnsPartialResultIfContextIsCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+
+	calls := 0
+	client := TestClient{
+		setter: func(ctx context.Context, zone string, record IkRecord) (*IkRecord, error) {
+			calls++
+			cancel()
+			return &record, nil
+		},
+	}
+	provider := Provider{client: &client}
+	setRecs, err := provider.SetRecords(ctx, "example.com", []libdns.Record{{ID: "1"}, {ID: "2"}})
+	if err != context.Canceled {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	assertEqualsInt(t, "calls", 1, calls)
+	assertEqualsInt(t, "set records", 1, len(setRecs))
+}
+
+func Test_DeleteRecords_DoesNotDeleteIfContextIsCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+
+	client := TestClient{
+		deleter: func(ctx context.Context, zone string, id string) error {
+			t.Fatalf("Expected that record is not deleted after cancellation")
+			return nil
+		},
+	}
+	provider := Provider{client: &client}
+	deletedRecs, err := provider.DeleteRecords(ctx, "example.com", []libdns.Record{{ID: "1"}})
+	if err != context.Canceled {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	assertEqualsInt(t, "deleted records", 0, len(deletedRecs))
+}
+
+func Test_GetRecords_ReturnsMappedRecordsTogetherWithMappingError(t *testing.T) {
+	mappingErr := &RecordMappingError{Zone: "example.com", Errors: []error{errors.New("invalid TTL")}}
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			return []IkRecord{{ID: "1", Type: "A", SourceIdn: "www.example.com"}}, mappingErr
+		},
+	}
+	provider := Provider{client: &client}
+
+	records, err := provider.GetRecords(context.TODO(), "example.com.")
+	if !errors.Is(err, mappingErr) {
+		t.Fatalf("Expected mapping error, got %v", err)
+	}
+	assertEqualsInt(t, "records", 1, len(records))
+}
+
+func Test_GetRecords_ReturnsNoRecordsOnMappingErrorInStrictMapping(t *testing.T) {
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			return []IkRecord{{ID: "1", Type: "A", SourceIdn: "www.example.com"}}, &RecordMappingError{Zone: zone}
+		},
+	}
+	provider := Provider{client: &client, StrictMapping: true}
+
+	records, err := provider.GetRecords(context.TODO(), "example.com.")
+	if err == nil || records != nil {
+		t.Fatalf("Expected only an error, got %v and %v", records, err)
+	}
+}
+
+func Test_AppendRecords_FailsOnMappingError(t *testing.T) {
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			return []IkRecord{}, &RecordMappingError{Zone: zone}
+		},
+		setter: func(ctx context.Context, zone string, record IkRecord) (*IkRecord, error) {
+			t.Fatalf("Expected no record to be written based on an incomplete zone")
+			return nil, nil
+		},
+	}
+	provider := Provider{client: &client}
+
+	_, err := provider.AppendRecords(context.TODO(), "example.com.", []libdns.Record{{Name: "www", Type: "A", Value: "127.0.0.1"}})
+	if err == nil {
+		t.Fatalf("Expected error")
+	}
+}
@@ -0,0 +1,397 @@
+package infomaniak
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+// Default number of attempts of a queued operation
+const defaultQueueAttempts = 3
+
+// Default delay before a failed queued operation is retried, it is doubled with every attempt
+const defaultQueueRetryDelay = time.Second
+
+// OperationKind kind of a queued operation
+type OperationKind string
+
+const (
+	// OperationAppend appends records as AppendRecords does
+	OperationAppend OperationKind = "append"
+
+	// OperationSet sets records as SetRecords does
+	OperationSet OperationKind = "set"
+
+	// OperationDelete deletes records as DeleteRecords does
+	OperationDelete OperationKind = "delete"
+)
+
+// QueuedOperation mutating operation that is processed by the worker of a Queue
+type QueuedOperation struct {
+	Kind    OperationKind   `json:"kind"`
+	Zone    string          `json:"zone"`
+	Records []libdns.Record `json:"records"`
+}
+
+// QueueStore persists the pending operations of a Queue, so they are processed after a restart
+type QueueStore interface {
+	// Save replaces the stored operations with the given ones
+	Save(operations []QueuedOperation) error
+
+	// Load returns the stored operations
+	Load() ([]QueuedOperation, error)
+}
+
+// FileQueueStore stores the pending operations of a Queue as JSON file
+type FileQueueStore struct {
+	// Path of the file the operations are written to
+	Path string
+}
+
+// Save writes the operations to the file
+func (s *FileQueueStore) Save(operations []QueuedOperation) error {
+	rawJson, err := json.Marshal(operations)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(s.Path), 0700)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomically(s.Path, rawJson)
+}
+
+// Load reads the operations from the file, no operations are returned if the file does not exist
+func (s *FileQueueStore) Load() ([]QueuedOperation, error) {
+	rawJson, err := ioutil.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var operations []QueuedOperation
+	err = json.Unmarshal(rawJson, &operations)
+	return operations, err
+}
+
+// Future result of a queued operation that is available once the operation was processed
+type Future struct {
+	done    chan struct{}
+	records []libdns.Record
+	err     error
+}
+
+// Done returns a channel that is closed once the operation was processed
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait blocks until the operation was processed or the context is done and returns the operation's result
+func (f *Future) Wait(ctx context.Context) ([]libdns.Record, error) {
+	select {
+	case <-f.done:
+		return f.records, f.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// resolve sets the result of the operation and marks it as processed
+func (f *Future) resolve(records []libdns.Record, err error) {
+	f.records = records
+	f.err = err
+	close(f.done)
+}
+
+// queueItem operation waiting in a queue together with its future
+type queueItem struct {
+	operation QueuedOperation
+	future    *Future
+}
+
+// Queue processes mutating operations one after another by a background worker that retries failed operations and limits the
+// rate of operations, e.g. for fire-and-forget dynamic DNS updaters. Operations can be enqueued before the worker is started.
+type Queue struct {
+	// Provider the operations are applied with
+	Provider *Provider
+
+	// Attempts of each operation before it fails, 3 if not set
+	Attempts int
+
+	// RetryDelay before a failed operation is retried, doubled with every attempt - 1 second if not set
+	RetryDelay time.Duration
+
+	// MinInterval between the start of two operations, operations are not rate limited if not set
+	MinInterval time.Duration
+
+	// Store persists pending operations if set, so that operations pending at a restart are processed by the next run
+	Store QueueStore
+
+	// pending operations in the order they were enqueued
+	items []queueItem
+
+	// set once the persisted operations were loaded
+	loaded bool
+
+	// signals the worker that operations were enqueued
+	wakeup chan struct{}
+
+	// set while Run processes the operations, so that no operation is processed twice
+	running bool
+
+	// mutex to prevent race conditions
+	mu sync.Mutex
+}
+
+// AppendRecords enqueues an operation that appends the records as Provider.AppendRecords does
+func (q *Queue) AppendRecords(zone string, records []libdns.Record) (*Future, error) {
+	return q.Enqueue(QueuedOperation{Kind: OperationAppend, Zone: zone, Records: records})
+}
+
+// SetRecords enqueues an operation that sets the records as Provider.SetRecords does
+func (q *Queue) SetRecords(zone string, records []libdns.Record) (*Future, error) {
+	return q.Enqueue(QueuedOperation{Kind: OperationSet, Zone: zone, Records: records})
+}
+
+// DeleteRecords enqueues an operation that deletes the records as Provider.DeleteRecords does
+func (q *Queue) DeleteRecords(zone string, records []libdns.Record) (*Future, error) {
+	return q.Enqueue(QueuedOperation{Kind: OperationDelete, Zone: zone, Records: records})
+}
+
+// Enqueue adds the operation to the queue and returns its future, an error is returned if the operation is
+// invalid or the pending operations could not be persisted
+func (q *Queue) Enqueue(operation QueuedOperation) (*Future, error) {
+	switch operation.Kind {
+	case OperationAppend, OperationSet, OperationDelete:
+	default:
+		return nil, fmt.Errorf("unknown operation kind '%s'", operation.Kind)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if err := q.load(); err != nil {
+		return nil, err
+	}
+	future := &Future{done: make(chan struct{})}
+	q.items = append(q.items, queueItem{operation: operation, future: future})
+	if err := q.persist(); err != nil {
+		q.items = q.items[:len(q.items)-1]
+		return nil, err
+	}
+	q.signal()
+	return future, nil
+}
+
+// Len returns the number of pending operations
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items)
+}
+
+// Run processes the persisted and enqueued operations until the context is done. Operations that are
+// still pending when the context is done stay persisted and are processed by the next run. Only one Run
+// may process the operations of a queue at a time, further calls fail while it is running.
+func (q *Queue) Run(ctx context.Context) error {
+	q.mu.Lock()
+	if q.running {
+		q.mu.Unlock()
+		return errors.New("queue is already running")
+	}
+	err := q.load()
+	if err == nil {
+		q.running = true
+	}
+	q.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		q.mu.Lock()
+		q.running = false
+		q.mu.Unlock()
+	}()
+
+	var lastStart time.Time
+	for {
+		item, ok := q.next()
+		if !ok {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-q.getWakeup():
+				continue
+			}
+		}
+
+		if wait := q.MinInterval - time.Since(lastStart); q.MinInterval > 0 && !lastStart.IsZero() && wait > 0 {
+			if err := sleep(ctx, wait); err != nil {
+				return err
+			}
+		}
+		lastStart = time.Now()
+
+		records, err := q.process(ctx, item.operation)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		q.mu.Lock()
+		q.remove(item)
+		persistErr := q.persist()
+		q.mu.Unlock()
+		item.future.resolve(records, err)
+		if persistErr != nil {
+			return persistErr
+		}
+	}
+}
+
+// process applies the operation and retries it if it failed with an error that may be temporary
+func (q *Queue) process(ctx context.Context, operation QueuedOperation) ([]libdns.Record, error) {
+	attempts := q.Attempts
+	if attempts <= 0 {
+		attempts = defaultQueueAttempts
+	}
+	delay := q.RetryDelay
+	if delay <= 0 {
+		delay = defaultQueueRetryDelay
+	}
+
+	var records []libdns.Record
+	var err error
+	for attempt := 1; ; attempt++ {
+		records, err = q.apply(ctx, operation)
+		if err == nil || attempt >= attempts || !isRetryable(operation, err) {
+			return records, err
+		}
+		if sleepErr := sleep(ctx, delay); sleepErr != nil {
+			return nil, sleepErr
+		}
+		delay *= 2
+	}
+}
+
+// apply applies the operation with the queue's provider
+func (q *Queue) apply(ctx context.Context, operation QueuedOperation) ([]libdns.Record, error) {
+	switch operation.Kind {
+	case OperationAppend:
+		return q.Provider.AppendRecords(ctx, operation.Zone, operation.Records)
+	case OperationSet:
+		return q.Provider.SetRecords(ctx, operation.Zone, operation.Records)
+	default:
+		return q.Provider.DeleteRecords(ctx, operation.Zone, operation.Records)
+	}
+}
+
+// next returns the oldest pending operation without removing it
+func (q *Queue) next() (queueItem, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.items) <= 0 {
+		return queueItem{}, false
+	}
+	return q.items[0], true
+}
+
+// remove removes the item from the pending operations, the caller must hold the queue's mutex
+func (q *Queue) remove(item queueItem) {
+	for i := range q.items {
+		if q.items[i].future == item.future {
+			q.items = append(q.items[:i], q.items[i+1:]...)
+			return
+		}
+	}
+}
+
+// load adds the persisted operations to the queue once, before any operation is enqueued or processed,
+// the caller must hold the queue's mutex
+func (q *Queue) load() error {
+	if q.Store == nil || q.loaded {
+		return nil
+	}
+	operations, err := q.Store.Load()
+	if err != nil {
+		return fmt.Errorf("could not load queued operations: %v", err)
+	}
+	q.loaded = true
+	for _, operation := range operations {
+		q.items = append(q.items, queueItem{operation: operation, future: &Future{done: make(chan struct{})}})
+	}
+	return nil
+}
+
+// persist saves the pending operations if a store is configured, the caller must hold the queue's mutex
+func (q *Queue) persist() error {
+	if q.Store == nil {
+		return nil
+	}
+	operations := make([]QueuedOperation, 0, len(q.items))
+	for _, item := range q.items {
+		operations = append(operations, item.operation)
+	}
+	if err := q.Store.Save(operations); err != nil {
+		return fmt.Errorf("could not persist queued operations: %v", err)
+	}
+	return nil
+}
+
+// signal wakes up the worker if it waits for operations, the caller must hold the queue's mutex
+func (q *Queue) signal() {
+	if q.wakeup == nil {
+		q.wakeup = make(chan struct{}, 1)
+	}
+	select {
+	case q.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// getWakeup returns the channel the worker waits on for new operations
+func (q *Queue) getWakeup() <-chan struct{} {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.wakeup == nil {
+		q.wakeup = make(chan struct{}, 1)
+	}
+	return q.wakeup
+}
+
+// isRetryable returns if an operation that failed with the given error may succeed if it is retried, only transient
+// failures of the API are retried: rate limited calls, server errors and network errors. As the records of an append
+// may have been created although its response got lost, appends are only retried if they were rate limited.
+func isRetryable(operation QueuedOperation, err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if operation.Kind == OperationAppend {
+		var apiErr *ApiError
+		return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusTooManyRequests
+	}
+	return isServiceFailure(err)
+}
+
+// sleep waits for the given duration or until the context is done
+func sleep(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Interface guards
+var (
+	_ QueueStore = (*FileQueueStore)(nil)
+)
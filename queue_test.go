@@ -0,0 +1,118 @@
+package infomaniak
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+func Test_Queue_RetriesFailedOperations(t *testing.T) {
+	attempts := 0
+	client := TestClient{
+		setter: func(ctx context.Context, zone string, record IkRecord) (*IkRecord, error) {
+			attempts++
+			if attempts < 2 {
+				return nil, &ApiError{StatusCode: http.StatusTooManyRequests}
+			}
+			record.ID = "1"
+			return &record, nil
+		},
+	}
+	queue := Queue{Provider: &Provider{client: &client}, RetryDelay: time.Millisecond}
+	future, err := queue.AppendRecords("example.com", []libdns.Record{{Type: "A", Name: "www", Value: "192.0.2.1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	go queue.Run(ctx)
+
+	records, err := future.Wait(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "attempts", 2, attempts)
+	assertEquals(t, "ID", "1", records[0].ID)
+	assertEqualsInt(t, "pending operations", 0, queue.Len())
+}
+
+func Test_Queue_ProcessesPersistedOperationsAfterRestart(t *testing.T) {
+	store := &FileQueueStore{Path: filepath.Join(t.TempDir(), "queue.json")}
+	queue := Queue{Store: store}
+	_, err := queue.DeleteRecords("example.com", []libdns.Record{{ID: "1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deleted := make(chan string, 1)
+	client := TestClient{
+		deleter: func(ctx context.Context, zone string, id string) error {
+			deleted <- id
+			return nil
+		},
+	}
+	restartedQueue := Queue{Provider: &Provider{client: &client}, Store: store}
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	go restartedQueue.Run(ctx)
+
+	assertEquals(t, "deleted ID", "1", <-deleted)
+}
+
+func Test_FileQueueStore_SavesOperationsConcurrently(t *testing.T) {
+	store := &FileQueueStore{Path: filepath.Join(t.TempDir(), "queue.json")}
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := store.Save([]QueuedOperation{{Kind: OperationDelete, Zone: "example.com", Records: []libdns.Record{{ID: "1"}}}})
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	operations, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "operations", 1, len(operations))
+}
+
+func Test_Queue_RetriesOnlyTransientErrors(t *testing.T) {
+	set := QueuedOperation{Kind: OperationSet}
+	appendOp := QueuedOperation{Kind: OperationAppend}
+	if !isRetryable(set, &ApiError{StatusCode: http.StatusServiceUnavailable}) {
+		t.Fatalf("Expected server error of set to be retried")
+	}
+	if isRetryable(set, &ZoneGoneError{Zone: "example.com"}) || isRetryable(set, errors.New("unknown")) {
+		t.Fatalf("Expected permanent errors not to be retried")
+	}
+	if isRetryable(appendOp, &ApiError{StatusCode: http.StatusServiceUnavailable}) {
+		t.Fatalf("Expected server error of append not to be retried, as the records may have been created")
+	}
+	if !isRetryable(appendOp, &ApiError{StatusCode: http.StatusTooManyRequests}) {
+		t.Fatalf("Expected rate limited append to be retried")
+	}
+}
+
+func Test_Queue_RejectsConcurrentRun(t *testing.T) {
+	queue := Queue{Provider: &Provider{client: &TestClient{}}}
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	go queue.Run(ctx)
+	time.Sleep(10 * time.Millisecond)
+
+	if err := queue.Run(ctx); err == nil || errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected second run to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,186 @@
+package infomaniak
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Pattern of the value of a LOC record as defined by RFC 1876, e.g. "52 22 23.000 N 4 53 32.000 E -2.00m 0.00m 10000m 10m"
+var locPattern = regexp.MustCompile(`^\d{1,2}( \d{1,2}( \d{1,2}(\.\d{1,3})?)?)? [NS] \d{1,3}( \d{1,2}( \d{1,2}(\.\d{1,3})?)?)? [EW] -?\d+(\.\d{1,2})?m?( \d+(\.\d{1,2})?m?( \d+(\.\d{1,2})?m?( \d+(\.\d{1,2})?m?)?)?)?$`)
+
+// Validators of the values of record types that are rarely used but supported by infomaniak
+var rareTypeValidators = map[RecordType]func(value string) error{
+	TypeLOC:   validateLocValue,
+	TypeRP:    validateRpValue,
+	TypeHINFO: validateHinfoValue,
+	TypeSSHFP: validateSshfpValue,
+	TypeTLSA:  validateTlsaValue,
+	TypeNAPTR: validateNaptrValue,
+}
+
+// validateRareTypeValue validates the value of a record of a rarely used type - values of other types are not validated
+func validateRareTypeValue(recType string, value string) error {
+	validator, ok := rareTypeValidators[RecordType(normalizeType(recType))]
+	if !ok {
+		return nil
+	}
+	return validator(value)
+}
+
+// validateLocValue validates the value of a LOC record
+func validateLocValue(value string) error {
+	if !locPattern.MatchString(strings.Join(strings.Fields(value), " ")) {
+		return fmt.Errorf("malformed value '%s', expected: '<lat> [N|S] <long> [E|W] <alt>m [<size>m [<hp>m [<vp>m]]]'", value)
+	}
+	return nil
+}
+
+// validateRpValue validates the value of a RP record consisting of a mailbox and a TXT record name
+func validateRpValue(value string) error {
+	fields := strings.Fields(value)
+	if len(fields) != 2 {
+		return fmt.Errorf("malformed value '%s', expected: '<mailbox> <txt-name>'", value)
+	}
+	for _, field := range fields {
+		if field != "." && !isHostName(field) {
+			return fmt.Errorf("'%s' is not a valid domain name", field)
+		}
+	}
+	return nil
+}
+
+// validateHinfoValue validates the value of a HINFO record consisting of the two character-strings CPU and OS
+func validateHinfoValue(value string) error {
+	if countCharacterStrings(value) != 2 {
+		return fmt.Errorf("malformed value '%s', expected: '\"<cpu>\" \"<os>\"'", value)
+	}
+	return nil
+}
+
+// validateSshfpValue validates the value of a SSHFP record
+func validateSshfpValue(value string) error {
+	fields := strings.Fields(value)
+	if len(fields) != 3 || !isUint8(fields[0]) || !isUint8(fields[1]) || !isHex(fields[2]) {
+		return fmt.Errorf("malformed value '%s', expected: '<algorithm> <fp-type> <fingerprint>'", value)
+	}
+	return nil
+}
+
+// validateTlsaValue validates the value of a TLSA record
+func validateTlsaValue(value string) error {
+	fields := strings.Fields(value)
+	if len(fields) != 4 || !isUint8(fields[0]) || !isUint8(fields[1]) || !isUint8(fields[2]) || !isHex(fields[3]) {
+		return fmt.Errorf("malformed value '%s', expected: '<usage> <selector> <matching-type> <data>'", value)
+	}
+	return nil
+}
+
+// validateNaptrValue validates the value of a NAPTR record
+func validateNaptrValue(value string) error {
+	fields := strings.Fields(value)
+	if len(fields) < 6 || !isUint16(fields[0]) || !isUint16(fields[1]) {
+		return fmt.Errorf("malformed value '%s', expected: '<order> <preference> \"<flags>\" \"<service>\" \"<regexp>\" <replacement>'", value)
+	}
+	replacement := fields[len(fields)-1]
+	if replacement != "." && !isHostName(replacement) {
+		return fmt.Errorf("'%s' is not a valid replacement", replacement)
+	}
+	return nil
+}
+
+// countCharacterStrings returns the number of character-strings in the value or -1 if it is malformed
+func countCharacterStrings(value string) int {
+	count := 0
+	for _, part := range splitCharacterStrings(value) {
+		if _, err := unquoteCharacterStrings(part); err != nil {
+			return -1
+		}
+		count++
+	}
+	return count
+}
+
+// splitCharacterStrings splits a value into its character-strings without unescaping them
+func splitCharacterStrings(value string) []string {
+	parts := make([]string, 0)
+	i := 0
+	for i < len(value) {
+		for i < len(value) && (value[i] == ' ' || value[i] == '\t') {
+			i++
+		}
+		if i >= len(value) {
+			break
+		}
+		start := i
+		quoted := value[i] == '"'
+		if quoted {
+			i++
+		}
+		for i < len(value) {
+			if value[i] == '\\' {
+				i += 2
+				continue
+			}
+			if quoted && value[i] == '"' {
+				i++
+				break
+			}
+			if !quoted && (value[i] == ' ' || value[i] == '\t') {
+				break
+			}
+			i++
+		}
+		if i > len(value) {
+			i = len(value)
+		}
+		parts = append(parts, value[start:i])
+	}
+	return parts
+}
+
+// isUint8 returns if the value is a number between 0 and 255
+func isUint8(value string) bool {
+	_, err := strconv.ParseUint(value, 10, 8)
+	return err == nil
+}
+
+// isUint16 returns if the value is a number between 0 and 65535
+func isUint16(value string) bool {
+	_, err := strconv.ParseUint(value, 10, 16)
+	return err == nil
+}
+
+// isHex returns if the value is a non-empty hexadecimal string
+func isHex(value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, c := range value {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// canonicalizeRareTypeValue returns the value of a record of a rarely used type in canonical presentation
+// format, so that values returned by the API compare equal to values provided by callers
+func canonicalizeRareTypeValue(recType string, value string) string {
+	if _, ok := rareTypeValidators[RecordType(recType)]; !ok {
+		return value
+	}
+	switch RecordType(recType) {
+	case TypeHINFO, TypeNAPTR:
+		return strings.Join(splitCharacterStrings(value), " ")
+	case TypeSSHFP, TypeTLSA:
+		fields := strings.Fields(value)
+		if len(fields) > 0 && isHex(fields[len(fields)-1]) {
+			fields[len(fields)-1] = strings.ToLower(fields[len(fields)-1])
+		}
+		return strings.Join(fields, " ")
+	default:
+		return strings.Join(strings.Fields(value), " ")
+	}
+}
@@ -0,0 +1,57 @@
+package infomaniak
+
+import (
+	"testing"
+
+	"github.com/libdns/libdns"
+)
+
+func Test_ValidateRareTypeValue_AcceptsValidValues(t *testing.T) {
+	validValues := map[string]string{
+		"LOC":   "52 22 23.000 N 4 53 32.000 E -2.00m 0.00m 10000m 10m",
+		"RP":    "admin.example.com. contact.example.com.",
+		"HINFO": `"x86_64" "Linux"`,
+		"SSHFP": "4 2 123456789abcdef67890123456789abcdef67890123456789abcdef123456789",
+		"TLSA":  "3 1 1 0123456789abcdef",
+		"NAPTR": `100 10 "U" "E2U+sip" "!^.*$!sip:info@example.com!" .`,
+		"A":     "not validated",
+	}
+	for recType, value := range validValues {
+		if err := validateRareTypeValue(recType, value); err != nil {
+			t.Fatalf("Expected %s value to be valid, got %v", recType, err)
+		}
+	}
+}
+
+func Test_ValidateRareTypeValue_RejectsMalformedValues(t *testing.T) {
+	malformedValues := map[string]string{
+		"LOC":   "somewhere",
+		"RP":    "admin.example.com.",
+		"HINFO": `"x86_64"`,
+		"SSHFP": "4 2 not-hex",
+		"TLSA":  "3 1 256 0123",
+		"NAPTR": "100 10 U",
+	}
+	for recType, value := range malformedValues {
+		if err := validateRareTypeValue(recType, value); err == nil {
+			t.Fatalf("Expected %s value '%s' to be rejected", recType, value)
+		}
+	}
+}
+
+func Test_ValidateStrict_DetectsMalformedRareTypeValue(t *testing.T) {
+	model := newZoneModel([]libdns.Record{{Name: "host", Type: "HINFO", Value: "single"}})
+	assertEqualsInt(t, "violations", 1, len(model.validateStrict("example.com")))
+}
+
+func Test_CanonicalizeRareTypeValue_NormalizesWhitespaceAndHex(t *testing.T) {
+	assertEquals(t, "LOC", "52 22 23 N 4 53 32 E -2m", canonicalizeRareTypeValue("LOC", " 52  22 23 N 4 53 32 E\t-2m "))
+	assertEquals(t, "HINFO", `"x86 64" "Linux"`, canonicalizeRareTypeValue("HINFO", `"x86 64"   "Linux"`))
+	assertEquals(t, "TLSA", "3 1 1 abcdef", canonicalizeRareTypeValue("TLSA", "3 1 1  ABCDEF"))
+	assertEquals(t, "A", " 127.0.0.1", canonicalizeRareTypeValue("A", " 127.0.0.1"))
+}
+
+func Test_ToLibDnsRecord_CanonicalizesRareTypeValue(t *testing.T) {
+	ikRec := IkRecord{Type: "SSHFP", SourceIdn: "host.example.com", Target: "4  2 ABCDEF"}
+	assertEquals(t, "Value", "4 2 abcdef", ikRec.ToLibDnsRecord("example.com").Value)
+}
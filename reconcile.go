@@ -0,0 +1,120 @@
+package infomaniak
+
+import (
+	"context"
+	"strings"
+
+	"github.com/libdns/libdns"
+)
+
+// Reconcile replaces the RRsets of the given records, so that each RRset of the zone contains exactly the given values afterwards.
+// Missing records are created before surplus records are deleted, records that already exist are left unchanged.
+// As unrelated values share TXT RRsets, only existing TXT records of the same kind are replaced, e.g. a SPF record only replaces
+// other SPF records of the same name. It returns the records of the RRsets.
+func (p *Provider) Reconcile(ctx context.Context, zone string, records []libdns.Record) ([]libdns.Record, error) {
+	zone = getWithoutTrailingDot(zone)
+	return p.withZoneLock(ctx, zone, func() ([]libdns.Record, error) {
+		return p.reconcile(ctx, zone, records)
+	})
+}
+
+// reconcile replaces the RRsets of the given records without acquiring the zone's lock, so that each RRset contains exactly the
+// given values afterwards - missing records are created first and surplus records of the RRsets are deleted afterwards
+func (p *Provider) reconcile(ctx context.Context, zone string, records []libdns.Record) ([]libdns.Record, error) {
+	records, err := p.resolveAutoTtls(ctx, zone, records)
+	if err != nil {
+		return nil, err
+	}
+	existingRecs, err := p.getRecords(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+	model := newZoneModel(existingRecs)
+
+	wanted := make(map[string]bool, len(records))
+	wantedTxtKinds := make(map[string]bool)
+	for _, rec := range records {
+		wanted[getCoordinates(rec)+"-"+normalizeValue(rec.Value)] = true
+		if RecordType(normalizeType(rec.Type)) == TypeTXT {
+			wantedTxtKinds[getCoordinates(rec)+"-"+txtKind(rec.Value)] = true
+		}
+	}
+
+	kept := make(map[string]bool)
+	keptRecs := make([]libdns.Record, 0)
+	recsToDelete := make([]libdns.Record, 0)
+	visited := make(map[string]bool)
+	for _, rec := range records {
+		if visited[getCoordinates(rec)] {
+			continue
+		}
+		visited[getCoordinates(rec)] = true
+		for _, existingRec := range model.resolve(rec.Name) {
+			if normalizeType(existingRec.Type) != normalizeType(rec.Type) {
+				continue
+			}
+			if RecordType(normalizeType(rec.Type)) == TypeTXT && !wantedTxtKinds[getCoordinates(existingRec)+"-"+txtKind(existingRec.Value)] {
+				continue
+			}
+			key := getCoordinates(existingRec) + "-" + normalizeValue(existingRec.Value)
+			if wanted[key] && !kept[key] {
+				kept[key] = true
+				keptRecs = append(keptRecs, existingRec)
+			} else {
+				recsToDelete = append(recsToDelete, existingRec)
+			}
+		}
+	}
+
+	recsToCreate := make([]libdns.Record, 0)
+	for _, rec := range records {
+		if !kept[getCoordinates(rec)+"-"+normalizeValue(rec.Value)] {
+			recsToCreate = append(recsToCreate, rec)
+		}
+	}
+	err = p.checkForConflicts(ctx, zone, recsToCreate)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := p.getClient()
+	if err != nil {
+		return nil, err
+	}
+
+	result := append(make([]libdns.Record, 0, len(records)), keptRecs...)
+	for _, rec := range recsToCreate {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		createdRec, err := client.CreateOrUpdateRecord(ctx, zone, ToInfomaniakRecord(&rec, zone))
+		p.records.invalidate()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, createdRec.ToLibDnsRecord(zone))
+	}
+	for _, rec := range recsToDelete {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		err := client.DeleteRecord(ctx, zone, rec.ID)
+		p.records.invalidate()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// txtKind returns the lower case version tag of a TXT value such as "v=spf1" or "v=dkim1", or an empty string for other values
+func txtKind(value string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if !strings.HasPrefix(value, "v=") {
+		return ""
+	}
+	if i := strings.IndexAny(value, "; "); i >= 0 {
+		return value[:i]
+	}
+	return value
+}
@@ -0,0 +1,56 @@
+package infomaniak
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+// Record a libdns record together with the metadata infomaniak keeps for it. As it embeds
+// libdns.Record, it can be used wherever a libdns record is expected by passing its Record field.
+type Record struct {
+	libdns.Record
+
+	// UpdatedAt point in time the record was last changed, zero if not returned by the API
+	UpdatedAt time.Time
+
+	// DyndnsID ID of the dynamic DNS configuration that manages this record, empty if the record is not managed by dynamic DNS
+	DyndnsID string
+
+	// Description attributes of the record, nil if the record was listed without description
+	Description *IkRecordDescription
+
+	// DescriptionRaw all attributes of the record's description as returned by the API
+	DescriptionRaw map[string]json.RawMessage
+}
+
+// ToRecord maps a infomaniak dns record to a libdns record that carries infomaniak's metadata
+func (ikr *IkRecord) ToRecord(zone string) Record {
+	return Record{
+		Record:         ikr.ToLibDnsRecord(zone),
+		UpdatedAt:      ikr.UpdatedAt,
+		DyndnsID:       ikr.DyndnsID,
+		Description:    ikr.Description,
+		DescriptionRaw: ikr.DescriptionRaw,
+	}
+}
+
+// GetRecordsWithMetadata lists all the records in the zone as GetRecords does, but together with the metadata
+// infomaniak keeps for them. Unlike GetRecords, records are not deduplicated and the last known records of a ZoneStore are never returned.
+func (p *Provider) GetRecordsWithMetadata(ctx context.Context, zone string) ([]Record, error) {
+	zone = getWithoutTrailingDot(zone)
+	ikRecords, err := p.getDnsRecordsForZone(ctx, zone)
+	var mappingErr *RecordMappingError
+	if err != nil && (p.StrictMapping || !errors.As(err, &mappingErr)) {
+		return nil, err
+	}
+
+	records := make([]Record, 0, len(ikRecords))
+	for _, rec := range ikRecords {
+		records = append(records, rec.ToRecord(zone))
+	}
+	return records, err
+}
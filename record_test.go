@@ -0,0 +1,32 @@
+package infomaniak
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func Test_GetRecordsWithMetadata_ReturnsMetadataOfRecords(t *testing.T) {
+	var ikRec IkRecord
+	err := json.Unmarshal([]byte(`{"id":"1","type":"A","source_idn":"home.example.com","target":"192.0.2.1","ttl":300,"updated_at":1700000000,"dyndns_id":42,"description":{"comment":"router"}}`), &ikRec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			return []IkRecord{ikRec}, nil
+		},
+	}
+	provider := Provider{client: &client}
+
+	records, err := provider.GetRecordsWithMetadata(context.TODO(), "example.com.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "records", 1, len(records))
+	assertEquals(t, "name", "home", records[0].Name)
+	assertEquals(t, "value", "192.0.2.1", records[0].Value)
+	assertEquals(t, "dyndns ID", "42", records[0].DyndnsID)
+	assertEqualsInt(t, "UpdatedAt", 1700000000, int(records[0].UpdatedAt.Unix()))
+	assertEquals(t, "comment", `"router"`, string(records[0].DescriptionRaw["comment"]))
+}
@@ -0,0 +1,167 @@
+package infomaniak
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+// RecordSet builds a list of records for a zone and validates each record as it is added, e.g.
+//
+//	records, err := NewRecordSet("example.com").A("www", "192.0.2.1", 300).TXT("_acme-challenge", "token", 60).Records()
+//
+// TTLs are given in seconds, a TTL of 0 applies the default TTL
+type RecordSet struct {
+	zone    string
+	records []libdns.Record
+	errs    []string
+}
+
+// NewRecordSet returns an empty record set for the given zone
+func NewRecordSet(zone string) *RecordSet {
+	return &RecordSet{zone: getWithoutTrailingDot(zone)}
+}
+
+// A adds an A record pointing to the given IPv4 address
+func (s *RecordSet) A(name string, ip string, ttlSecs uint) *RecordSet {
+	parsed := net.ParseIP(ip)
+	if parsed == nil || parsed.To4() == nil {
+		return s.fail("A", name, fmt.Sprintf("'%s' is not an IPv4 address", ip))
+	}
+	return s.add("A", name, parsed.String(), ttlSecs, 0)
+}
+
+// AAAA adds an AAAA record pointing to the given IPv6 address
+func (s *RecordSet) AAAA(name string, ip string, ttlSecs uint) *RecordSet {
+	parsed := net.ParseIP(ip)
+	if parsed == nil || parsed.To4() != nil {
+		return s.fail("AAAA", name, fmt.Sprintf("'%s' is not an IPv6 address", ip))
+	}
+	return s.add("AAAA", name, parsed.String(), ttlSecs, 0)
+}
+
+// CNAME adds a CNAME record pointing to the given host name
+func (s *RecordSet) CNAME(name string, target string, ttlSecs uint) *RecordSet {
+	if isApexName(s.relativeName(name)) {
+		return s.fail("CNAME", name, "a CNAME record cannot be created at the zone apex")
+	}
+	if !isHostName(target) {
+		return s.fail("CNAME", name, fmt.Sprintf("'%s' is not a valid host name", target))
+	}
+	return s.add("CNAME", name, target, ttlSecs, 0)
+}
+
+// TXT adds a TXT record with the given value, which is escaped when it is written
+func (s *RecordSet) TXT(name string, value string, ttlSecs uint) *RecordSet {
+	return s.add("TXT", name, value, ttlSecs, 0)
+}
+
+// MX adds a MX record pointing to the given mail server
+func (s *RecordSet) MX(name string, priority uint, host string, ttlSecs uint) *RecordSet {
+	if !isHostName(host) {
+		return s.fail("MX", name, fmt.Sprintf("'%s' is not a valid host name", host))
+	}
+	return s.add("MX", name, host, ttlSecs, priority)
+}
+
+// NS adds a NS record pointing to the given name server
+func (s *RecordSet) NS(name string, host string, ttlSecs uint) *RecordSet {
+	if !isHostName(host) {
+		return s.fail("NS", name, fmt.Sprintf("'%s' is not a valid host name", host))
+	}
+	return s.add("NS", name, host, ttlSecs, 0)
+}
+
+// PTR adds a PTR record pointing to the given host name, e.g. PTR("1.2", "www.example.com", 3600) in zone 0.192.in-addr.arpa
+func (s *RecordSet) PTR(name string, host string, ttlSecs uint) *RecordSet {
+	if !isHostName(host) {
+		return s.fail("PTR", name, fmt.Sprintf("'%s' is not a valid host name", host))
+	}
+	return s.add("PTR", name, host, ttlSecs, 0)
+}
+
+// CAA adds a CAA record with the given flags, tag and value, e.g. CAA("@", 0, "issue", "letsencrypt.org", 3600)
+func (s *RecordSet) CAA(name string, flags uint8, tag string, value string, ttlSecs uint) *RecordSet {
+	caaValue := fmt.Sprintf("%d %s %s", flags, tag, quoteCharacterStrings(value))
+	if err := validateCaaValue(caaValue); err != nil {
+		return s.fail("CAA", name, err.Error())
+	}
+	return s.add("CAA", name, caaValue, ttlSecs, 0)
+}
+
+// SRV adds a SRV record for the given service and protocol, e.g. SRV("sip", "tcp", "@", 10, 5060, "sip.example.com", 3600)
+func (s *RecordSet) SRV(service string, proto string, name string, priority uint, port uint16, target string, ttlSecs uint) *RecordSet {
+	srvName := srvName(service, proto, s.relativeName(name))
+	if !isHostName(target) {
+		return s.fail("SRV", srvName, fmt.Sprintf("'%s' is not a valid host name", target))
+	}
+	return s.add("SRV", srvName, fmt.Sprintf("%d %s", port, target), ttlSecs, priority)
+}
+
+// Records returns all added records or an error describing all records that were invalid
+func (s *RecordSet) Records() ([]libdns.Record, error) {
+	if len(s.errs) > 0 {
+		return nil, fmt.Errorf("invalid records: %s", strings.Join(s.errs, "; "))
+	}
+	return append([]libdns.Record(nil), s.records...), nil
+}
+
+// add adds a record with a name relative to the zone
+func (s *RecordSet) add(recType string, name string, value string, ttlSecs uint, priority uint) *RecordSet {
+	relativeName := s.relativeName(name)
+	if strings.HasSuffix(relativeName, ".") {
+		return s.fail(recType, name, fmt.Sprintf("name is not part of zone '%s'", s.zone))
+	}
+	s.records = append(s.records, libdns.Record{
+		Type:     recType,
+		Name:     relativeName,
+		Value:    value,
+		TTL:      time.Duration(ttlSecs),
+		Priority: priority,
+	})
+	return s
+}
+
+// fail records an error for an invalid record
+func (s *RecordSet) fail(recType string, name string, reason string) *RecordSet {
+	s.errs = append(s.errs, fmt.Sprintf("%s record '%s': %s", recType, name, reason))
+	return s
+}
+
+// relativeName returns the name relative to the zone, names ending with a dot are treated as fully qualified
+// and are returned unchanged if they are not part of the zone
+func (s *RecordSet) relativeName(name string) string {
+	if strings.HasSuffix(name, ".") && name != "." {
+		fqdn := getWithoutTrailingDot(name)
+		if !isInZone(fqdn, s.zone) {
+			return name
+		}
+		return toRelativeName(fqdn, s.zone)
+	}
+	if isApexName(name) {
+		return apexName
+	}
+	return name
+}
+
+// isHostName returns if the given value is a syntactically valid host name
+func isHostName(value string) bool {
+	value = getWithoutTrailingDot(value)
+	if value == "" || len(value) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(value, ".") {
+		if label == "" || len(label) > 63 || strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return false
+		}
+		for _, c := range label {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+				return false
+			}
+		}
+	}
+	return true
+}
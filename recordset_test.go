@@ -0,0 +1,53 @@
+package infomaniak
+
+import (
+	"testing"
+)
+
+func Test_RecordSet_BuildsValidRecords(t *testing.T) {
+	recs, err := NewRecordSet("example.com.").
+		A("www", "192.0.2.1", 300).
+		AAAA("www", "2001:db8::1", 300).
+		CNAME("blog", "example.org.", 3600).
+		TXT("_acme-challenge", "token", 60).
+		MX("@", 10, "mail.example.com", 3600).
+		SRV("sip", "tcp", "@", 10, 5060, "sip.example.com", 3600).
+		CAA("@", 0, "issue", "letsencrypt.org", 3600).
+		Records()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "records", 7, len(recs))
+	assertEquals(t, "Name", "_acme-challenge", recs[3].Name)
+	assertEqualsInt(t, "TTL", 60, int(recs[3].TTL))
+	assertEquals(t, "Name", "", recs[4].Name)
+	assertEqualsInt(t, "Priority", 10, int(recs[4].Priority))
+	assertEquals(t, "Name", "_sip._tcp", recs[5].Name)
+	assertEquals(t, "Value", "5060 sip.example.com", recs[5].Value)
+	assertEquals(t, "Value", `0 issue "letsencrypt.org"`, recs[6].Value)
+}
+
+func Test_RecordSet_ConvertsAbsoluteNamesOfZone(t *testing.T) {
+	recs, err := NewRecordSet("example.com").A("www.example.com.", "192.0.2.1", 300).Records()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "Name", "www", recs[0].Name)
+}
+
+func Test_RecordSet_ReturnsErrorForInvalidRecords(t *testing.T) {
+	invalidSets := map[string]*RecordSet{
+		"IPv6 as A":           NewRecordSet("example.com").A("www", "2001:db8::1", 300),
+		"IPv4 as AAAA":        NewRecordSet("example.com").AAAA("www", "192.0.2.1", 300),
+		"CNAME at apex":       NewRecordSet("example.com").CNAME("@", "example.org", 300),
+		"invalid MX host":     NewRecordSet("example.com").MX("@", 10, "mail server", 300),
+		"name of other zone":  NewRecordSet("example.com").A("www.example.org.", "192.0.2.1", 300),
+		"unknown CAA tag":     NewRecordSet("example.com").CAA("@", 0, "issues", "letsencrypt.org", 300),
+		"one invalid of many": NewRecordSet("example.com").A("www", "192.0.2.1", 300).A("mail", "invalid", 300),
+	}
+	for description, set := range invalidSets {
+		if _, err := set.Records(); err == nil {
+			t.Fatalf("Expected error for %s", description)
+		}
+	}
+}
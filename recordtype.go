@@ -0,0 +1,65 @@
+package infomaniak
+
+// RecordType type of a DNS record, as used in the Type of libdns records
+type RecordType string
+
+// Record types that can be managed with infomaniak, new types have to be added to supportedTypes as well
+const (
+	TypeA      RecordType = "A"
+	TypeAAAA   RecordType = "AAAA"
+	TypeALIAS  RecordType = "ALIAS"
+	TypeCAA    RecordType = "CAA"
+	TypeCNAME  RecordType = "CNAME"
+	TypeDNAME  RecordType = "DNAME"
+	TypeDS     RecordType = "DS"
+	TypeHINFO  RecordType = "HINFO"
+	TypeLOC    RecordType = "LOC"
+	TypeMX     RecordType = "MX"
+	TypeNAPTR  RecordType = "NAPTR"
+	TypeNS     RecordType = "NS"
+	TypePTR    RecordType = "PTR"
+	TypeRP     RecordType = "RP"
+	TypeSMIMEA RecordType = "SMIMEA"
+	TypeSRV    RecordType = "SRV"
+	TypeSSHFP  RecordType = "SSHFP"
+	TypeTLSA   RecordType = "TLSA"
+	TypeTXT    RecordType = "TXT"
+)
+
+// Record types that are known but cannot be managed with infomaniak
+const (
+	TypeSOA   RecordType = "SOA"
+	TypeHTTPS RecordType = "HTTPS"
+	TypeSVCB  RecordType = "SVCB"
+	TypeURI   RecordType = "URI"
+)
+
+// Record types that can be managed with infomaniak
+var supportedTypes = []RecordType{TypeA, TypeAAAA, TypeALIAS, TypeCAA, TypeCNAME, TypeDNAME, TypeDS, TypeHINFO, TypeLOC, TypeMX, TypeNAPTR, TypeNS, TypePTR, TypeRP, TypeSMIMEA, TypeSRV, TypeSSHFP, TypeTLSA, TypeTXT}
+
+// Types whose priority-like attributes are returned in the record's description
+var descriptionTypes = map[RecordType]bool{
+	TypeMX:    true,
+	TypeSRV:   true,
+	TypeURI:   true,
+	TypeHTTPS: true,
+	TypeSVCB:  true,
+	TypeNAPTR: true,
+	TypeCAA:   true,
+}
+
+// Supported returns if records of the type can be managed with infomaniak
+func (t RecordType) Supported() bool {
+	for _, supportedType := range supportedTypes {
+		if supportedType == t {
+			return true
+		}
+	}
+	return false
+}
+
+// NeedsDescription returns if attributes of records of the type, such as the priority, weight, port or flags,
+// are returned in the record's description - they may be incomplete if records are listed without description
+func (t RecordType) NeedsDescription() bool {
+	return descriptionTypes[t]
+}
@@ -0,0 +1,23 @@
+package infomaniak
+
+import (
+	"testing"
+)
+
+func Test_RecordType_Supported(t *testing.T) {
+	if !TypeTXT.Supported() || !TypeSRV.Supported() {
+		t.Fatal("Expected TXT and SRV records to be supported")
+	}
+	if TypeSOA.Supported() || RecordType("UNKNOWN").Supported() {
+		t.Fatal("Expected SOA and unknown records not to be supported")
+	}
+}
+
+func Test_RecordType_NeedsDescription(t *testing.T) {
+	if !TypeMX.NeedsDescription() || !TypeCAA.NeedsDescription() {
+		t.Fatal("Expected MX and CAA records to need their description")
+	}
+	if TypeA.NeedsDescription() || TypeTXT.NeedsDescription() {
+		t.Fatal("Expected A and TXT records not to need their description")
+	}
+}
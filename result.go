@@ -0,0 +1,100 @@
+package infomaniak
+
+import (
+	"context"
+
+	"github.com/libdns/libdns"
+)
+
+// Outcome of an operation on a single record
+type Outcome string
+
+const (
+	// OutcomeCreated the record did not exist and was created
+	OutcomeCreated Outcome = "created"
+
+	// OutcomeUpdated the already existing record was updated
+	OutcomeUpdated Outcome = "updated"
+
+	// OutcomeDeleted the record was deleted
+	OutcomeDeleted Outcome = "deleted"
+
+	// OutcomeSkipped no change was necessary or possible for the record
+	OutcomeSkipped Outcome = "skipped"
+)
+
+// RecordResult outcome of an operation on a single record
+type RecordResult struct {
+	// Record as returned by the API, or as given if it was skipped
+	Record libdns.Record
+
+	// Outcome of the operation on the record
+	Outcome Outcome
+}
+
+// ApplyResult summary of the changes made by an operation
+type ApplyResult struct {
+	Created int
+	Updated int
+	Deleted int
+	Skipped int
+
+	// Outcome of each record in the order they were processed
+	Records []RecordResult
+}
+
+// add records the outcome of an operation on a single record - nothing is recorded for nil results
+func (r *ApplyResult) add(record libdns.Record, outcome Outcome) {
+	if r == nil {
+		return
+	}
+	switch outcome {
+	case OutcomeCreated:
+		r.Created++
+	case OutcomeUpdated:
+		r.Updated++
+	case OutcomeDeleted:
+		r.Deleted++
+	case OutcomeSkipped:
+		r.Skipped++
+	}
+	r.Records = append(r.Records, RecordResult{Record: record, Outcome: outcome})
+}
+
+// AppendRecordsWithResult adds records to the zone as AppendRecords does and returns a summary of the changes
+func (p *Provider) AppendRecordsWithResult(ctx context.Context, zone string, records []libdns.Record) (*ApplyResult, error) {
+	zone = getWithoutTrailingDot(zone)
+	result := &ApplyResult{}
+	_, err := p.withZoneLock(ctx, zone, func() ([]libdns.Record, error) {
+		return p.appendRecords(ctx, zone, records, result)
+	})
+	return result, err
+}
+
+// SetRecordsWithResult sets the records in the zone as SetRecords does and returns a summary of the changes
+func (p *Provider) SetRecordsWithResult(ctx context.Context, zone string, records []libdns.Record) (*ApplyResult, error) {
+	zone = getWithoutTrailingDot(zone)
+	result := &ApplyResult{}
+	_, err := p.withZoneLock(ctx, zone, func() ([]libdns.Record, error) {
+		return p.withJournal(zone, "set", records, func() ([]libdns.Record, error) {
+			return p.withChangeLog(ctx, zone, "set", func() ([]libdns.Record, error) {
+				return p.setRecords(ctx, zone, records, result)
+			})
+		})
+	})
+	return result, err
+}
+
+// DeleteRecordsWithResult deletes the records from the zone as DeleteRecords does and returns a summary of the changes
+func (p *Provider) DeleteRecordsWithResult(ctx context.Context, zone string, records []libdns.Record) (*ApplyResult, error) {
+	zone = getWithoutTrailingDot(zone)
+	result := &ApplyResult{}
+	_, err := p.withZoneLock(ctx, zone, func() ([]libdns.Record, error) {
+		return p.withJournal(zone, "delete", records, func() ([]libdns.Record, error) {
+			return p.withChangeLog(ctx, zone, "delete", func() ([]libdns.Record, error) {
+				return p.deleteRecords(ctx, zone, records, result)
+			})
+		})
+	})
+	return result, err
+}
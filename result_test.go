@@ -0,0 +1,74 @@
+package infomaniak
+
+import (
+	"context"
+	"testing"
+
+	"github.com/libdns/libdns"
+)
+
+func Test_SetRecordsWithResult_CountsCreatedAndUpdatedRecords(t *testing.T) {
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			return []IkRecord{{ID: "1", SourceIdn: "www.example.com", Type: "A", Target: "127.0.0.1"}}, nil
+		},
+		setter: func(ctx context.Context, zone string, record IkRecord) (*IkRecord, error) {
+			if record.ID == "" {
+				record.ID = "2"
+			}
+			return &record, nil
+		},
+	}
+	provider := Provider{client: &client}
+	result, err := provider.SetRecordsWithResult(context.TODO(), "example.com", []libdns.Record{
+		{Name: "www", Type: "A", Value: "127.0.0.2"},
+		{Name: "mail", Type: "A", Value: "127.0.0.3"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "Updated", 1, result.Updated)
+	assertEqualsInt(t, "Created", 1, result.Created)
+	assertEqualsInt(t, "Records", 2, len(result.Records))
+}
+
+func Test_AppendRecordsWithResult_CountsSkippedRecords(t *testing.T) {
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			return []IkRecord{{ID: "1", SourceIdn: "www.example.com", Type: "A", Target: "127.0.0.1"}}, nil
+		},
+		setter: func(ctx context.Context, zone string, record IkRecord) (*IkRecord, error) {
+			record.ID = "2"
+			return &record, nil
+		},
+	}
+	provider := Provider{client: &client}
+	result, err := provider.AppendRecordsWithResult(context.TODO(), "example.com", []libdns.Record{
+		{Name: "www", Type: "A", Value: "127.0.0.2"},
+		{Name: "mail", Type: "A", Value: "127.0.0.3"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "Created", 1, result.Created)
+	assertEqualsInt(t, "Skipped", 1, result.Skipped)
+}
+
+func Test_DeleteRecordsWithResult_CountsDeletedAndSkippedRecords(t *testing.T) {
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			return []IkRecord{{ID: "1", SourceIdn: "www.example.com", Type: "A", Target: "127.0.0.1"}}, nil
+		},
+		deleter: func(ctx context.Context, zone string, id string) error { return nil },
+	}
+	provider := Provider{client: &client}
+	result, err := provider.DeleteRecordsWithResult(context.TODO(), "example.com", []libdns.Record{
+		{Name: "www", Type: "A"},
+		{Name: "mail", Type: "A"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "Deleted", 1, result.Deleted)
+	assertEqualsInt(t, "Skipped", 1, result.Skipped)
+}
@@ -0,0 +1,114 @@
+package infomaniak
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/libdns/libdns"
+)
+
+// ReverseName returns the fully qualified name of the PTR record of the IP address with a trailing dot,
+// e.g. 1.2.0.192.in-addr.arpa. for 192.0.2.1 - an empty string is returned if the IP address is invalid
+func ReverseName(ip net.IP) string {
+	if ip4 := ip.To4(); ip4 != nil {
+		return fmt.Sprintf("%d.%d.%d.%d.in-addr.arpa.", ip4[3], ip4[2], ip4[1], ip4[0])
+	}
+	ip16 := ip.To16()
+	if ip16 == nil {
+		return ""
+	}
+	var sb strings.Builder
+	for i := len(ip16) - 1; i >= 0; i-- {
+		sb.WriteString(strconv.FormatUint(uint64(ip16[i]&0x0f), 16))
+		sb.WriteByte('.')
+		sb.WriteString(strconv.FormatUint(uint64(ip16[i]>>4), 16))
+		sb.WriteByte('.')
+	}
+	sb.WriteString("ip6.arpa.")
+	return sb.String()
+}
+
+// SetReverseRecord points the IP address to the host name by replacing the PTR record of the IP address in the given
+// reverse zone, e.g. 2.0.192.in-addr.arpa, which has to be delegated to the account like any other domain. The reverse DNS
+// of infomaniak cloud IPs is managed by the cloud's API and cannot be set with it. TTLs are given in seconds, a TTL of 0
+// applies the default TTL. It returns the PTR record of the IP address.
+func (p *Provider) SetReverseRecord(ctx context.Context, zone string, ip net.IP, host string, ttlSecs uint) ([]libdns.Record, error) {
+	zone = getWithoutTrailingDot(zone)
+	name := ReverseName(ip)
+	if name == "" {
+		return nil, fmt.Errorf("invalid IP address '%s'", ip)
+	}
+	if !isInZone(getWithoutTrailingDot(name), zone) {
+		return nil, fmt.Errorf("reverse name of '%s' is not part of zone '%s'", ip, zone)
+	}
+	records, err := NewRecordSet(zone).PTR(name, host, ttlSecs).Records()
+	if err != nil {
+		return nil, err
+	}
+	return p.withZoneLock(ctx, zone, func() ([]libdns.Record, error) {
+		return p.reconcile(ctx, zone, records)
+	})
+}
+
+// GetReverseRecords returns the host names the IP addresses of the given reverse zone point to by their IP address,
+// PTR records whose name does not denote a single IP address are skipped
+func (p *Provider) GetReverseRecords(ctx context.Context, zone string) (map[string][]string, error) {
+	zone = getWithoutTrailingDot(zone)
+	records, err := p.getRecords(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+	hosts := make(map[string][]string)
+	for _, rec := range records {
+		if RecordType(normalizeType(rec.Type)) != TypePTR {
+			continue
+		}
+		ip := parseReverseName(toAbsoluteName(rec.Name, zone))
+		if ip == nil {
+			continue
+		}
+		hosts[ip.String()] = append(hosts[ip.String()], getWithoutTrailingDot(rec.Value))
+	}
+	return hosts, nil
+}
+
+// parseReverseName returns the IP address of a fully qualified reverse name, nil if the name does not denote a single IP address
+func parseReverseName(name string) net.IP {
+	name = strings.ToLower(getWithoutTrailingDot(name))
+	if labels, ok := trimReverseSuffix(name, ".in-addr.arpa", 4); ok {
+		ip := make(net.IP, 0, 4)
+		for i := len(labels) - 1; i >= 0; i-- {
+			octet, err := strconv.ParseUint(labels[i], 10, 8)
+			if err != nil {
+				return nil
+			}
+			ip = append(ip, byte(octet))
+		}
+		return net.IPv4(ip[0], ip[1], ip[2], ip[3])
+	}
+	if labels, ok := trimReverseSuffix(name, ".ip6.arpa", 32); ok {
+		ip := make(net.IP, net.IPv6len)
+		for i, label := range labels {
+			nibble, err := strconv.ParseUint(label, 16, 4)
+			if err != nil || len(label) != 1 {
+				return nil
+			}
+			pos := len(labels) - 1 - i
+			ip[pos/2] |= byte(nibble) << (4 * uint(1-pos%2))
+		}
+		return ip
+	}
+	return nil
+}
+
+// trimReverseSuffix returns the labels of the name in front of the suffix if the name ends with the suffix and has the given number of labels
+func trimReverseSuffix(name string, suffix string, labelCount int) ([]string, bool) {
+	if !strings.HasSuffix(name, suffix) {
+		return nil, false
+	}
+	labels := strings.Split(strings.TrimSuffix(name, suffix), ".")
+	return labels, len(labels) == labelCount
+}
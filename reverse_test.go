@@ -0,0 +1,76 @@
+package infomaniak
+
+import (
+	"context"
+	"net"
+	"testing"
+)
+
+func Test_ReverseName_ReturnsNameOfIpv4AndIpv6Addresses(t *testing.T) {
+	assertEquals(t, "IPv4", "1.2.0.192.in-addr.arpa.", ReverseName(net.ParseIP("192.0.2.1")))
+	assertEquals(t, "IPv6", "1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa.", ReverseName(net.ParseIP("2001:db8::1")))
+	assertEquals(t, "invalid", "", ReverseName(nil))
+}
+
+func Test_ParseReverseName_ReturnsIpOfReverseName(t *testing.T) {
+	for _, ip := range []string{"192.0.2.1", "2001:db8::1"} {
+		parsed := parseReverseName(ReverseName(net.ParseIP(ip)))
+		if parsed == nil || parsed.String() != ip {
+			t.Fatalf("Expected %s, got %v", ip, parsed)
+		}
+	}
+	if parseReverseName("2.0.192.in-addr.arpa") != nil {
+		t.Fatalf("Expected no IP for name of network")
+	}
+}
+
+func Test_SetReverseRecord_ReplacesPtrRecordOfIp(t *testing.T) {
+	deletedIds := make([]string, 0)
+	created := make([]IkRecord, 0)
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			return []IkRecord{{ID: "1", Type: "PTR", SourceIdn: "1.2.0.192.in-addr.arpa", Target: "old.example.com"}}, nil
+		},
+		setter: func(ctx context.Context, zone string, record IkRecord) (*IkRecord, error) {
+			created = append(created, record)
+			return &record, nil
+		},
+		deleter: func(ctx context.Context, zone string, id string) error {
+			deletedIds = append(deletedIds, id)
+			return nil
+		},
+	}
+	provider := Provider{client: &client}
+
+	_, err := provider.SetReverseRecord(context.TODO(), "2.0.192.in-addr.arpa", net.ParseIP("192.0.2.1"), "www.example.com", 3600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "created records", 1, len(created))
+	assertEquals(t, "target", "www.example.com", created[0].Target)
+	assertEqualsInt(t, "deleted records", 1, len(deletedIds))
+
+	_, err = provider.SetReverseRecord(context.TODO(), "2.0.192.in-addr.arpa", net.ParseIP("198.51.100.1"), "www.example.com", 3600)
+	if err == nil {
+		t.Fatalf("Expected error for IP outside of zone")
+	}
+}
+
+func Test_GetReverseRecords_ReturnsHostsByIp(t *testing.T) {
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			return []IkRecord{
+				{ID: "1", Type: "PTR", SourceIdn: "1.2.0.192.in-addr.arpa", Target: "www.example.com."},
+				{ID: "2", Type: "NS", SourceIdn: "2.0.192.in-addr.arpa", Target: "ns1.example.com."},
+			}, nil
+		},
+	}
+	provider := Provider{client: &client}
+
+	hosts, err := provider.GetReverseRecords(context.TODO(), "2.0.192.in-addr.arpa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "IPs", 1, len(hosts))
+	assertEquals(t, "host", "www.example.com", hosts["192.0.2.1"][0])
+}
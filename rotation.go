@@ -0,0 +1,161 @@
+package infomaniak
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+// Default interval between two rotations
+const defaultRotationInterval = 5 * time.Minute
+
+// WeightedRotation maintains the A or AAAA records of a name for a weighted set of members, e.g. for a crude DNS based load distribution.
+// On every rotation a number of members is chosen randomly in proportion to their weights and the RRset is replaced with their addresses,
+// so that members with higher weights are published more often. Members with a weight of 0 are removed from the RRset.
+type WeightedRotation struct {
+	// Provider used to replace the records
+	Provider *Provider
+
+	// Zone of the records
+	Zone string
+
+	// Name of the records relative to the zone
+	Name string
+
+	// Type of the records: "A" or "AAAA"
+	Type string
+
+	// Members by their IP address and weight, the weights can be changed between rotations with SetWeight
+	Members map[string]uint
+
+	// Slots number of members that are published at once, all members with a weight are published if not set -
+	// the weights then only decide which members are published, so members with different positive weights are rejected
+	Slots int
+
+	// TtlInSec TTL of the records in seconds, the default TTL is applied if not set
+	TtlInSec uint
+
+	// source of randomness, replaceable for tests
+	rand *rand.Rand
+
+	// mutex to prevent concurrent rotations
+	mu sync.Mutex
+}
+
+// SetWeight changes the weight of a member, a weight of 0 removes the member from the RRset with the next rotation
+func (r *WeightedRotation) SetWeight(ip string, weight uint) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Members == nil {
+		r.Members = make(map[string]uint)
+	}
+	r.Members[ip] = weight
+}
+
+// Rotate chooses the members to publish and replaces the RRset with their addresses, it returns the records of the RRset
+func (r *WeightedRotation) Rotate(ctx context.Context) ([]libdns.Record, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	recType := RecordType(normalizeType(r.Type))
+	if recType != TypeA && recType != TypeAAAA {
+		return nil, fmt.Errorf("unsupported record type '%s', expected A or AAAA", r.Type)
+	}
+	for ip := range r.Members {
+		parsed := net.ParseIP(ip)
+		if parsed == nil || (recType == TypeA) != (parsed.To4() != nil) {
+			return nil, fmt.Errorf("member '%s' is not a valid address for %s records", ip, r.Type)
+		}
+	}
+
+	if r.Slots <= 0 && hasDifferentWeights(r.Members) {
+		return nil, fmt.Errorf("members of the rotation of '%s' have different weights, which only take effect if Slots is set", r.Name)
+	}
+
+	chosen := r.choose()
+	if len(chosen) <= 0 {
+		return nil, fmt.Errorf("no member of the rotation of '%s' has a weight", r.Name)
+	}
+	records := make([]libdns.Record, 0, len(chosen))
+	for _, ip := range chosen {
+		records = append(records, libdns.Record{Type: string(recType), Name: r.Name, Value: ip, TTL: time.Duration(r.TtlInSec)})
+	}
+	return r.Provider.Reconcile(ctx, r.Zone, records)
+}
+
+// Run rotates the members immediately and then in the given interval, 5 minutes if it is not positive, until the context
+// is done. Errors of single rotations are passed to onError if it is not nil.
+func (r *WeightedRotation) Run(ctx context.Context, interval time.Duration, onError func(err error)) error {
+	if interval <= 0 {
+		interval = defaultRotationInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := r.Rotate(ctx); err != nil && onError != nil && ctx.Err() == nil {
+			onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// choose returns the addresses of the members to publish, chosen randomly without replacement in proportion to their weights
+func (r *WeightedRotation) choose() []string {
+	candidates := make([]string, 0, len(r.Members))
+	var totalWeight uint
+	for ip, weight := range r.Members {
+		if weight > 0 {
+			candidates = append(candidates, ip)
+			totalWeight += weight
+		}
+	}
+	// sorted so that the choice only depends on the source of randomness
+	sort.Strings(candidates)
+	if r.Slots <= 0 || r.Slots >= len(candidates) {
+		return candidates
+	}
+	if r.rand == nil {
+		r.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+
+	chosen := make([]string, 0, r.Slots)
+	for len(chosen) < r.Slots {
+		pick := uint(r.rand.Int63n(int64(totalWeight)))
+		for i, ip := range candidates {
+			weight := r.Members[ip]
+			if pick < weight {
+				chosen = append(chosen, ip)
+				totalWeight -= weight
+				candidates = append(candidates[:i], candidates[i+1:]...)
+				break
+			}
+			pick -= weight
+		}
+	}
+	return chosen
+}
+
+// hasDifferentWeights returns if the members have different positive weights
+func hasDifferentWeights(members map[string]uint) bool {
+	var first uint
+	for _, weight := range members {
+		if weight == 0 {
+			continue
+		}
+		if first == 0 {
+			first = weight
+		} else if weight != first {
+			return true
+		}
+	}
+	return false
+}
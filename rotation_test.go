@@ -0,0 +1,82 @@
+package infomaniak
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+)
+
+func Test_WeightedRotation_PrefersMembersWithHigherWeights(t *testing.T) {
+	rotation := WeightedRotation{
+		Members: map[string]uint{"192.0.2.1": 98, "192.0.2.2": 1, "192.0.2.3": 1},
+		Slots:   1,
+		rand:    rand.New(rand.NewSource(1)),
+	}
+	counts := make(map[string]int)
+	for i := 0; i < 100; i++ {
+		counts[rotation.choose()[0]]++
+	}
+	if counts["192.0.2.1"] < 80 {
+		t.Fatalf("Expected member with highest weight to be chosen most often, got %v", counts)
+	}
+}
+
+func Test_WeightedRotation_Rotate_RemovesMembersWithoutWeight(t *testing.T) {
+	deletedIds := make([]string, 0)
+	created := make([]IkRecord, 0)
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			return []IkRecord{
+				{ID: "1", Type: "A", SourceIdn: "www.example.com", Target: "192.0.2.1"},
+				{ID: "2", Type: "A", SourceIdn: "www.example.com", Target: "192.0.2.2"},
+			}, nil
+		},
+		setter: func(ctx context.Context, zone string, record IkRecord) (*IkRecord, error) {
+			created = append(created, record)
+			return &record, nil
+		},
+		deleter: func(ctx context.Context, zone string, id string) error {
+			deletedIds = append(deletedIds, id)
+			return nil
+		},
+	}
+	rotation := WeightedRotation{Provider: &Provider{client: &client}, Zone: "example.com", Name: "www", Type: "A"}
+	rotation.SetWeight("192.0.2.1", 1)
+	rotation.SetWeight("192.0.2.2", 0)
+	rotation.SetWeight("192.0.2.3", 1)
+
+	records, err := rotation.Rotate(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "records", 2, len(records))
+	assertEqualsInt(t, "created records", 1, len(created))
+	assertEquals(t, "created value", "192.0.2.3", created[0].Target)
+	assertEqualsInt(t, "deleted records", 1, len(deletedIds))
+	assertEquals(t, "deleted ID", "2", deletedIds[0])
+}
+
+func Test_WeightedRotation_Rotate_RejectsDifferentWeightsWithoutSlots(t *testing.T) {
+	rotation := WeightedRotation{Provider: &Provider{client: &TestClient{}}, Zone: "example.com", Name: "www", Type: "A"}
+	rotation.SetWeight("192.0.2.1", 1)
+	rotation.SetWeight("192.0.2.2", 3)
+
+	_, err := rotation.Rotate(context.TODO())
+	if err == nil {
+		t.Fatalf("Expected error for different weights without slots")
+	}
+}
+
+func Test_WeightedRotation_Run_RotatesWithDefaultIntervalIfIntervalIsNotPositive(t *testing.T) {
+	rotation := WeightedRotation{Provider: &Provider{client: &TestClient{}}, Zone: "example.com", Name: "www", Type: "TXT"}
+	ctx, cancel := context.WithCancel(context.TODO())
+	rotated := false
+
+	err := rotation.Run(ctx, 0, func(err error) {
+		rotated = true
+		cancel()
+	})
+	if err != context.Canceled || !rotated {
+		t.Fatalf("Expected rotation until context is cancelled, got %v (rotated=%t)", err, rotated)
+	}
+}
@@ -0,0 +1,81 @@
+package infomaniak
+
+import (
+	"context"
+
+	"github.com/libdns/libdns"
+)
+
+// Router routes operations to the provider responsible for the zone, e.g. if the domains of a project are spread across several
+// infomaniak accounts with their own tokens, so that they can be managed through a single libdns-compatible object
+type Router struct {
+	// Providers by zone suffix, a zone is routed to the provider with the longest suffix the zone equals or is a subzone of -
+	// an empty suffix matches all zones
+	Routes map[string]*Provider `json:"routes,omitempty"`
+}
+
+// GetRecords lists all the records in the zone through the provider responsible for the zone
+func (r *Router) GetRecords(ctx context.Context, zone string) ([]libdns.Record, error) {
+	provider, err := r.route(zone)
+	if err != nil {
+		return nil, err
+	}
+	return provider.GetRecords(ctx, zone)
+}
+
+// AppendRecords adds records to the zone through the provider responsible for the zone
+func (r *Router) AppendRecords(ctx context.Context, zone string, records []libdns.Record) ([]libdns.Record, error) {
+	provider, err := r.route(zone)
+	if err != nil {
+		return nil, err
+	}
+	return provider.AppendRecords(ctx, zone, records)
+}
+
+// SetRecords sets the records in the zone through the provider responsible for the zone
+func (r *Router) SetRecords(ctx context.Context, zone string, records []libdns.Record) ([]libdns.Record, error) {
+	provider, err := r.route(zone)
+	if err != nil {
+		return nil, err
+	}
+	return provider.SetRecords(ctx, zone, records)
+}
+
+// DeleteRecords deletes the records from the zone through the provider responsible for the zone
+func (r *Router) DeleteRecords(ctx context.Context, zone string, records []libdns.Record) ([]libdns.Record, error) {
+	provider, err := r.route(zone)
+	if err != nil {
+		return nil, err
+	}
+	return provider.DeleteRecords(ctx, zone, records)
+}
+
+// route returns the provider with the longest suffix matching the zone at label boundaries,
+// a *ZoneNotFoundError is returned if no suffix matches
+func (r *Router) route(zone string) (*Provider, error) {
+	zone = getWithoutTrailingDot(zone)
+	var found *Provider
+	foundSuffix := ""
+	for suffix, provider := range r.Routes {
+		suffix = getWithoutTrailingDot(suffix)
+		if suffix != "" && !isInZone(zone, suffix) {
+			continue
+		}
+		if found == nil || len(suffix) > len(foundSuffix) {
+			found = provider
+			foundSuffix = suffix
+		}
+	}
+	if found == nil {
+		return nil, &ZoneNotFoundError{Zone: zone}
+	}
+	return found, nil
+}
+
+// Interface guards
+var (
+	_ libdns.RecordGetter   = (*Router)(nil)
+	_ libdns.RecordAppender = (*Router)(nil)
+	_ libdns.RecordSetter   = (*Router)(nil)
+	_ libdns.RecordDeleter  = (*Router)(nil)
+)
@@ -0,0 +1,51 @@
+package infomaniak
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// newRoutedTestProvider returns a provider whose zones all contain a single record with the given ID
+func newRoutedTestProvider(id string) *Provider {
+	client := TestClient{getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+		return []IkRecord{{ID: id, Type: "A", SourceIdn: "www." + zone, Target: "127.0.0.1"}}, nil
+	}}
+	return &Provider{client: &client}
+}
+
+func Test_Router_RoutesByLongestSuffix(t *testing.T) {
+	router := Router{Routes: map[string]*Provider{
+		"example.com":      newRoutedTestProvider("account1"),
+		"shop.example.com": newRoutedTestProvider("account2"),
+		"example.org.":     newRoutedTestProvider("account3"),
+	}}
+
+	for zone, expectedId := range map[string]string{"example.com": "account1", "eu.shop.example.com.": "account2", "example.org": "account3"} {
+		recs, err := router.GetRecords(context.TODO(), zone)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEquals(t, "ID of records of "+zone, expectedId, recs[0].ID)
+	}
+}
+
+func Test_Router_ReturnsErrorIfNoRouteMatches(t *testing.T) {
+	router := Router{Routes: map[string]*Provider{"example.com": newRoutedTestProvider("account1")}}
+
+	_, err := router.GetRecords(context.TODO(), "notexample.com")
+	var notFoundErr *ZoneNotFoundError
+	if !errors.As(err, &notFoundErr) {
+		t.Fatalf("Expected ZoneNotFoundError, got %v", err)
+	}
+}
+
+func Test_Router_UsesEmptySuffixAsDefault(t *testing.T) {
+	router := Router{Routes: map[string]*Provider{"": newRoutedTestProvider("default"), "example.com": newRoutedTestProvider("account1")}}
+
+	recs, err := router.GetRecords(context.TODO(), "example.net")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "ID", "default", recs[0].ID)
+}
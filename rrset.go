@@ -0,0 +1,40 @@
+package infomaniak
+
+import (
+	"context"
+
+	"github.com/libdns/libdns"
+)
+
+// GetRRSet returns the records of the zone with the given name, relative to the zone, and type, e.g. the TXT records of an ACME challenge.
+// As the infomaniak API cannot filter records by name and type, all records of the zone are loaded, or taken from the record cache,
+// and filtered locally. An empty slice is returned if the RRset does not exist.
+func (p *Provider) GetRRSet(ctx context.Context, zone string, name string, recType string) ([]libdns.Record, error) {
+	zone = getWithoutTrailingDot(zone)
+	records, err := p.getRecords(ctx, zone)
+	if records == nil {
+		return nil, err
+	}
+	return filterRRSet(records, name, normalizeType(recType)), err
+}
+
+// mapRecords maps infomaniak dns records to libdns records
+func mapRecords(ikRecords []IkRecord, zone string) []libdns.Record {
+	records := make([]libdns.Record, 0, len(ikRecords))
+	for _, rec := range ikRecords {
+		records = append(records, rec.ToLibDnsRecord(zone))
+	}
+	return records
+}
+
+// filterRRSet returns the records with the given name and type
+func filterRRSet(records []libdns.Record, name string, recType string) []libdns.Record {
+	coordinates := getCoordinates(libdns.Record{Name: name, Type: recType})
+	rrset := make([]libdns.Record, 0)
+	for _, rec := range records {
+		if getCoordinates(rec) == coordinates {
+			rrset = append(rrset, rec)
+		}
+	}
+	return rrset
+}
@@ -0,0 +1,34 @@
+package infomaniak
+
+import (
+	"context"
+	"testing"
+)
+
+func Test_GetRRSet_FiltersRecordsLocally(t *testing.T) {
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			return []IkRecord{
+				{ID: "1", Type: "TXT", SourceIdn: "_acme-challenge.example.com", Target: "token"},
+				{ID: "2", Type: "TXT", SourceIdn: "example.com", Target: "v=spf1 -all"},
+				{ID: "3", Type: "CNAME", SourceIdn: "_acme-challenge.www.example.com", Target: "example.com."},
+			}, nil
+		},
+	}
+	provider := Provider{client: &client}
+
+	records, err := provider.GetRRSet(context.TODO(), "example.com.", "_acme-challenge", "txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "records", 1, len(records))
+	assertEquals(t, "ID", "1", records[0].ID)
+
+	records, err = provider.GetRRSet(context.TODO(), "example.com", "www", "A")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if records == nil || len(records) != 0 {
+		t.Fatalf("Expected empty slice, got %#v", records)
+	}
+}
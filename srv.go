@@ -0,0 +1,94 @@
+package infomaniak
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/libdns/libdns"
+)
+
+// SRVRecord returns the record of the SRV struct with a name in the form "_service._proto.name", which is written
+// as "_service._proto" at the zone apex - unlike libdns.SRV.ToRecord, the name is valid if the SRV's name is empty or "@"
+func SRVRecord(srv libdns.SRV) libdns.Record {
+	rec := srv.ToRecord()
+	rec.Name = srvName(srv.Service, srv.Proto, srv.Name)
+	return rec
+}
+
+// ToSRV parses the SRV record into a SRV struct, the value may be in the form "port target" as written by libdns
+// or "weight port target" as returned by infomaniak - the name of SRV records at the zone apex is "@"
+func ToSRV(rec libdns.Record) (libdns.SRV, error) {
+	if RecordType(normalizeType(rec.Type)) != TypeSRV {
+		return libdns.SRV{}, fmt.Errorf("record type not SRV: %s", rec.Type)
+	}
+	labels := strings.SplitN(trimApexSuffix(rec.Name), ".", 3)
+	if len(labels) < 2 || !strings.HasPrefix(labels[0], "_") || !strings.HasPrefix(labels[1], "_") {
+		return libdns.SRV{}, fmt.Errorf("malformed SRV name '%s', expected: '_service._proto[.name]'", rec.Name)
+	}
+	name := "@"
+	if len(labels) == 3 {
+		name = labels[2]
+	}
+
+	weight, port, target, err := parseSrvValue(rec.Value)
+	if err != nil {
+		return libdns.SRV{}, err
+	}
+	if rec.Weight > 0 {
+		weight = rec.Weight
+	}
+	return libdns.SRV{
+		Service:  strings.TrimPrefix(labels[0], "_"),
+		Proto:    strings.TrimPrefix(labels[1], "_"),
+		Name:     name,
+		Priority: rec.Priority,
+		Weight:   weight,
+		Port:     port,
+		Target:   target,
+	}, nil
+}
+
+// srvName returns the name of a SRV record relative to the zone, without name at the zone apex
+func srvName(service string, proto string, name string) string {
+	srvName := fmt.Sprintf("_%s._%s", strings.TrimPrefix(service, "_"), strings.TrimPrefix(proto, "_"))
+	if !isApexName(name) {
+		srvName += "." + name
+	}
+	return srvName
+}
+
+// trimApexSuffix removes the apex name "@" and trailing dots that names of SRV records built with libdns.SRV.ToRecord end with
+func trimApexSuffix(name string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(name, "@"), ".")
+}
+
+// parseSrvValue parses the value of a SRV record in the form "[weight] port target"
+func parseSrvValue(value string) (uint, uint, string, error) {
+	fields := strings.Fields(value)
+	if len(fields) < 2 || len(fields) > 3 {
+		return 0, 0, "", fmt.Errorf("malformed SRV value '%s', expected: '[weight] port target'", value)
+	}
+	numbers := make([]uint, 0, 2)
+	for _, field := range fields[:len(fields)-1] {
+		number, err := strconv.ParseUint(field, 10, 16)
+		if err != nil {
+			return 0, 0, "", fmt.Errorf("malformed SRV value '%s': %v", value, err)
+		}
+		numbers = append(numbers, uint(number))
+	}
+	if len(numbers) == 1 {
+		return 0, numbers[0], fields[len(fields)-1], nil
+	}
+	return numbers[0], numbers[1], fields[len(fields)-1], nil
+}
+
+// srvTargetWithWeight returns the value of the SRV record in the form "weight port target" if the record carries
+// its weight in the Weight field, as records built from libdns.SRV do, otherwise the value is returned unchanged
+func srvTargetWithWeight(rec *libdns.Record) string {
+	fields := strings.Fields(rec.Value)
+	if rec.Weight == 0 || len(fields) != 2 {
+		return rec.Value
+	}
+	return fmt.Sprintf("%d %s", rec.Weight, strings.Join(fields, " "))
+}
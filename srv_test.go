@@ -0,0 +1,45 @@
+package infomaniak
+
+import (
+	"testing"
+
+	"github.com/libdns/libdns"
+)
+
+func Test_SRVRecord_RoundTripsThroughInfomaniakRecord(t *testing.T) {
+	for _, srv := range []libdns.SRV{
+		{Service: "sip", Proto: "tcp", Name: "voip", Priority: 10, Weight: 5, Port: 5060, Target: "sip.example.com."},
+		{Service: "imaps", Proto: "tcp", Name: "@", Priority: 5, Weight: 1, Port: 993, Target: "mail.infomaniak.com."},
+		{Service: "xmpp", Proto: "udp", Name: "chat", Priority: 20, Weight: 0, Port: 5222, Target: "xmpp.example.com."},
+	} {
+		ikRec := ToInfomaniakRecord(&[]libdns.Record{SRVRecord(srv)}[0], "example.com")
+		actual, err := ToSRV(ikRec.ToLibDnsRecord("example.com"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual != srv {
+			t.Fatalf("Expected %+v, got %+v", srv, actual)
+		}
+	}
+}
+
+func Test_ToInfomaniakRecord_BuildsSrvSourceFromLibdnsSrv(t *testing.T) {
+	srv := libdns.SRV{Service: "imaps", Proto: "tcp", Name: "@", Weight: 1, Port: 993, Target: "mail.infomaniak.com."}
+	rec := srv.ToRecord()
+
+	ikRec := ToInfomaniakRecord(&rec, "example.com")
+	assertEquals(t, "source", "_imaps._tcp.example.com", ikRec.SourceIdn)
+	assertEquals(t, "target", "1 993 mail.infomaniak.com.", ikRec.Target)
+}
+
+func Test_ToSRV_ReturnsErrorForMalformedRecords(t *testing.T) {
+	for _, rec := range []libdns.Record{
+		{Type: "A", Name: "_sip._tcp", Value: "5060 sip.example.com."},
+		{Type: "SRV", Name: "sip", Value: "5060 sip.example.com."},
+		{Type: "SRV", Name: "_sip._tcp", Value: "sip.example.com."},
+	} {
+		if _, err := ToSRV(rec); err == nil {
+			t.Fatalf("Expected error for record %+v", rec)
+		}
+	}
+}
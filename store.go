@@ -0,0 +1,122 @@
+package infomaniak
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+// ZoneStore persists the last known records of zones so they can be served if the infomaniak API is not available
+type ZoneStore interface {
+	// Store saves the records of the given zone
+	Store(zone string, records []libdns.Record) error
+
+	// Load returns the last stored records of the given zone and when they were stored,
+	// ok is false if no records were stored for the zone yet
+	Load(zone string) (records []libdns.Record, storedAt time.Time, ok bool, err error)
+}
+
+// FileZoneStore stores the records of each zone as JSON file in a directory
+type FileZoneStore struct {
+	// Directory the zone files are written to
+	Dir string
+}
+
+// storedZone file content of a zone stored by FileZoneStore
+type storedZone struct {
+	StoredAt time.Time       `json:"stored_at"`
+	Records  []libdns.Record `json:"records"`
+}
+
+// Store writes the records of the given zone to the zone's file
+func (s *FileZoneStore) Store(zone string, records []libdns.Record) error {
+	rawJson, err := json.Marshal(storedZone{StoredAt: time.Now(), Records: records})
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(s.Dir, 0700)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomically(s.path(zone), rawJson)
+}
+
+// writeFileAtomically writes the data to a new temporary file next to the file of the path and renames it to the path
+// afterwards, so that readers never see partially written data and concurrent writers do not share a temporary file
+func writeFileAtomically(path string, data []byte) error {
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = tmpFile.Write(data)
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpFile.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmpFile.Name())
+	}
+	return err
+}
+
+// Load reads the records of the given zone from the zone's file
+func (s *FileZoneStore) Load(zone string) ([]libdns.Record, time.Time, bool, error) {
+	rawJson, err := ioutil.ReadFile(s.path(zone))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, time.Time{}, false, nil
+	} else if err != nil {
+		return nil, time.Time{}, false, err
+	}
+	var stored storedZone
+	err = json.Unmarshal(rawJson, &stored)
+	if err != nil {
+		return nil, time.Time{}, false, err
+	}
+	return stored.Records, stored.StoredAt, true, nil
+}
+
+// path returns the path of the file the given zone is stored in
+func (s *FileZoneStore) path(zone string) string {
+	return filepath.Join(s.Dir, filepath.Base(zone)+".json")
+}
+
+// GetRecordsWithStaleness lists all the records in the zone as GetRecords does and additionally
+// returns if the records were served from the ZoneStore because the API was not available. The stored records are only
+// served for network errors, server errors and rate limits of the API and while the provider is degraded - other errors,
+// e.g. of a revoked token or a deleted zone, are returned
+func (p *Provider) GetRecordsWithStaleness(ctx context.Context, zone string) ([]libdns.Record, bool, error) {
+	zone = getWithoutTrailingDot(zone)
+	records, err := p.getRecords(ctx, zone)
+	if p.ZoneStore == nil {
+		return records, false, err
+	}
+
+	if err == nil {
+		// the store is only a fallback, failing to update it must not fail the read
+		p.ZoneStore.Store(zone, records)
+		return records, false, nil
+	}
+	var degradedErr *DegradedError
+	if records != nil || ctx.Err() != nil || (!isServiceFailure(err) && !errors.As(err, &degradedErr)) {
+		return records, false, err
+	}
+
+	storedRecs, _, ok, loadErr := p.ZoneStore.Load(zone)
+	if loadErr != nil || !ok {
+		return nil, false, err
+	}
+	return storedRecs, true, nil
+}
+
+// Interface guards
+var (
+	_ ZoneStore = (*FileZoneStore)(nil)
+)
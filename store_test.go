@@ -0,0 +1,119 @@
+package infomaniak
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/libdns/libdns"
+)
+
+func Test_FileZoneStore_LoadsStoredRecords(t *testing.T) {
+	store := FileZoneStore{Dir: t.TempDir()}
+	err := store.Store("example.com", []libdns.Record{{ID: "1", Name: "www", Type: "A", Value: "127.0.0.1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recs, _, ok, err := store.Load("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok || len(recs) != 1 {
+		t.Fatalf("Expected 1 stored record, got %d", len(recs))
+	}
+	assertEquals(t, "ID", "1", recs[0].ID)
+}
+
+func Test_FileZoneStore_StoresZoneConcurrently(t *testing.T) {
+	store := FileZoneStore{Dir: t.TempDir()}
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := store.Store("example.com", []libdns.Record{{ID: "1", Name: "www", Type: "A", Value: "127.0.0.1"}})
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	files, err := os.ReadDir(store.Dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "files", 1, len(files))
+	recs, _, ok, err := store.Load("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok || len(recs) != 1 {
+		t.Fatalf("Expected 1 stored record, got %d", len(recs))
+	}
+}
+
+func Test_FileZoneStore_ReturnsNotOkForUnknownZone(t *testing.T) {
+	store := FileZoneStore{Dir: t.TempDir()}
+	_, _, ok, err := store.Load("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Fatalf("Expected no records for unknown zone")
+	}
+}
+
+func Test_GetRecordsWithStaleness_ReturnsStoredRecordsIfApiFails(t *testing.T) {
+	failing := false
+	client := TestClient{getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+		if failing {
+			return nil, newApiError(http.StatusServiceUnavailable, nil, nil)
+		}
+		return []IkRecord{{ID: "1", SourceIdn: "www.example.com", Type: "A"}}, nil
+	}}
+	provider := Provider{client: &client, ZoneStore: &FileZoneStore{Dir: t.TempDir()}}
+
+	_, stale, err := provider.GetRecordsWithStaleness(context.TODO(), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stale {
+		t.Fatalf("Expected records loaded from API to not be stale")
+	}
+
+	failing = true
+	recs, stale, err := provider.GetRecordsWithStaleness(context.TODO(), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stale || len(recs) != 1 {
+		t.Fatalf("Expected 1 stale record, got %d (stale=%t)", len(recs), stale)
+	}
+}
+
+func Test_GetRecordsWithStaleness_ReturnsErrorsNotCausedByUnavailableApi(t *testing.T) {
+	var failure error
+	client := TestClient{getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+		if failure != nil {
+			return nil, failure
+		}
+		return []IkRecord{{ID: "1", SourceIdn: "www.example.com", Type: "A"}}, nil
+	}}
+	provider := Provider{client: &client, ZoneStore: &FileZoneStore{Dir: t.TempDir()}}
+	_, _, err := provider.GetRecordsWithStaleness(context.TODO(), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, failure = range []error{newApiError(http.StatusUnauthorized, nil, nil), &ZoneNotFoundError{Zone: "example.com"}, &ZoneGoneError{Zone: "example.com"}} {
+		recs, stale, err := provider.GetRecordsWithStaleness(context.TODO(), "example.com")
+		if !errors.Is(err, failure) || stale || recs != nil {
+			t.Fatalf("Expected %v without stored records, got %v (stale=%t)", failure, err, stale)
+		}
+	}
+}
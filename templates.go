@@ -0,0 +1,63 @@
+package infomaniak
+
+import (
+	"github.com/libdns/libdns"
+)
+
+// Host names of the infomaniak services referenced by the record templates
+const (
+	infomaniakMailExchanger  = "mta-gw.infomaniak.ch."
+	infomaniakSpfInclude     = "include:spf.infomaniak.ch"
+	infomaniakWebmailHost    = "mail.infomaniak.com."
+	infomaniakAutoconfigHost = "autoconfig.infomaniak.com."
+	infomaniakAutodiscover   = "autodiscover.infomaniak.com."
+)
+
+// TTL in seconds of the records created by the templates
+const templateTtlSecs = 3600
+
+// MailTemplate returns the records of the zone apex that route mails to infomaniak's mail service: the MX record, the SPF record
+// and, if a DKIM selector is given, the DKIM record with the given public key. The records can be applied with Reconcile.
+func MailTemplate(dkimSelector string, dkimPublicKey string) ([]libdns.Record, error) {
+	records, err := NewRecordSet("").MX("@", 5, infomaniakMailExchanger, templateTtlSecs).Records()
+	if err != nil {
+		return nil, err
+	}
+
+	spf, err := BuildSPFRecord("", infomaniakSpfInclude, "-all")
+	if err != nil {
+		return nil, err
+	}
+	records = append(records, withTemplateTtl(spf))
+
+	if dkimSelector != "" {
+		dkim, err := BuildDKIMRecord(dkimSelector, "rsa", dkimPublicKey)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, withTemplateTtl(dkim))
+	}
+	return records, nil
+}
+
+// WebmailTemplate returns the CNAME record that makes infomaniak's webmail reachable at the "webmail" name of the zone.
+// The records can be applied with Reconcile.
+func WebmailTemplate() ([]libdns.Record, error) {
+	return NewRecordSet("").CNAME("webmail", infomaniakWebmailHost, templateTtlSecs).Records()
+}
+
+// AutodiscoverTemplate returns the records that let mail clients discover the settings of infomaniak's mail service,
+// the autoconfig CNAME record used by Thunderbird and the autodiscover SRV record used by Outlook.
+// The records can be applied with Reconcile.
+func AutodiscoverTemplate() ([]libdns.Record, error) {
+	return NewRecordSet("").
+		CNAME("autoconfig", infomaniakAutoconfigHost, templateTtlSecs).
+		SRV("autodiscover", "tcp", "@", 0, 443, infomaniakAutodiscover, templateTtlSecs).
+		Records()
+}
+
+// withTemplateTtl returns the record with the TTL of the templates
+func withTemplateTtl(record libdns.Record) libdns.Record {
+	record.TTL = templateTtlSecs
+	return record
+}
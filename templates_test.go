@@ -0,0 +1,59 @@
+package infomaniak
+
+import (
+	"context"
+	"testing"
+)
+
+func Test_MailTemplate_ReturnsMxSpfAndDkimRecords(t *testing.T) {
+	recs, err := MailTemplate("default", "MIGfMA0GCSqGSIb3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "records", 3, len(recs))
+	assertEquals(t, "Type", "MX", recs[0].Type)
+	assertEquals(t, "Value", "v=spf1 include:spf.infomaniak.ch -all", recs[1].Value)
+	assertEquals(t, "Name", "default._domainkey", recs[2].Name)
+
+	recs, err = MailTemplate("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "records without DKIM", 2, len(recs))
+}
+
+func Test_Reconcile_ReplacesOnlyTxtRecordsOfSameKind(t *testing.T) {
+	created := make([]IkRecord, 0)
+	deletedIds := make([]string, 0)
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			return []IkRecord{
+				{ID: "1", Type: "TXT", SourceIdn: "example.com", Target: "v=spf1 include:old.example.org -all"},
+				{ID: "2", Type: "TXT", SourceIdn: "example.com", Target: "site-verification=abc"},
+				{ID: "3", Type: "MX", SourceIdn: "example.com", Target: "mx.old.example.org", Priority: 10},
+			}, nil
+		},
+		setter: func(ctx context.Context, zone string, record IkRecord) (*IkRecord, error) {
+			created = append(created, record)
+			return &record, nil
+		},
+		deleter: func(ctx context.Context, zone string, id string) error {
+			deletedIds = append(deletedIds, id)
+			return nil
+		},
+	}
+	provider := Provider{client: &client}
+
+	recs, err := MailTemplate("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = provider.Reconcile(context.TODO(), "example.com.", recs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "created records", 2, len(created))
+	assertEqualsInt(t, "deleted records", 2, len(deletedIds))
+	assertEquals(t, "first deleted ID", "3", deletedIds[0])
+	assertEquals(t, "second deleted ID", "1", deletedIds[1])
+}
@@ -0,0 +1,82 @@
+package infomaniak
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// TokenSource provides the API token used for each API call, e.g. to rotate tokens without recreating the provider
+type TokenSource interface {
+	// Token returns the API token to use for the next API call
+	Token(ctx context.Context) (string, error)
+}
+
+// RotatingTokenSource returns the current token and renews it shortly before it expires.
+// As infomaniak does not document an endpoint to renew tokens, the renewal itself is done by
+// the caller supplied Renew function, e.g. by requesting a new token from a secret manager.
+type RotatingTokenSource struct {
+	// CurrentToken is the token that is returned until it is renewed
+	CurrentToken string
+
+	// ExpiresAt is the point in time the current token expires - it is never renewed if not set
+	ExpiresAt time.Time
+
+	// RenewBefore is the duration before expiry at which the token is renewed, defaults to one hour
+	RenewBefore time.Duration
+
+	// Renew returns a new token and its expiry for the current token
+	Renew func(ctx context.Context, currentToken string) (string, time.Time, error)
+
+	// Persist is called with every renewed token so it can be stored, e.g. in the configuration, if not nil
+	Persist func(token string, expiresAt time.Time) error
+
+	// mutex to prevent concurrent renewals
+	mu sync.Mutex
+}
+
+// Token returns the current token and renews it first if it is about to expire - if the renewal fails,
+// the current token is returned as long as it did not expire yet
+func (s *RotatingTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	renewBefore := s.RenewBefore
+	if renewBefore <= 0 {
+		renewBefore = time.Hour
+	}
+	if s.ExpiresAt.IsZero() || time.Now().Add(renewBefore).Before(s.ExpiresAt) {
+		return s.CurrentToken, nil
+	}
+	if s.Renew == nil {
+		return s.validCurrentToken(errors.New("token is about to expire and no renew function is configured"))
+	}
+
+	token, expiresAt, err := s.Renew(ctx, s.CurrentToken)
+	if err != nil {
+		return s.validCurrentToken(err)
+	}
+	if s.Persist != nil {
+		err = s.Persist(token, expiresAt)
+		if err != nil {
+			return "", err
+		}
+	}
+	s.CurrentToken = token
+	s.ExpiresAt = expiresAt
+	return token, nil
+}
+
+// validCurrentToken returns the current token if it did not expire yet, otherwise the given error
+func (s *RotatingTokenSource) validCurrentToken(err error) (string, error) {
+	if time.Now().Before(s.ExpiresAt) {
+		return s.CurrentToken, nil
+	}
+	return "", err
+}
+
+// Interface guards
+var (
+	_ TokenSource = (*RotatingTokenSource)(nil)
+)
@@ -0,0 +1,74 @@
+package infomaniak
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func Test_RotatingTokenSource_ReturnsCurrentTokenIfNotAboutToExpire(t *testing.T) {
+	source := RotatingTokenSource{
+		CurrentToken: "current",
+		ExpiresAt:    time.Now().Add(24 * time.Hour),
+		Renew: func(ctx context.Context, currentToken string) (string, time.Time, error) {
+			t.Fatalf("Expected token not to be renewed")
+			return "", time.Time{}, nil
+		},
+	}
+	token, err := source.Token(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "Token", "current", token)
+}
+
+func Test_RotatingTokenSource_RenewsAndPersistsTokenBeforeExpiry(t *testing.T) {
+	persisted := ""
+	source := RotatingTokenSource{
+		CurrentToken: "current",
+		ExpiresAt:    time.Now().Add(time.Minute),
+		Renew: func(ctx context.Context, currentToken string) (string, time.Time, error) {
+			return "renewed", time.Now().Add(24 * time.Hour), nil
+		},
+		Persist: func(token string, expiresAt time.Time) error {
+			persisted = token
+			return nil
+		},
+	}
+	token, err := source.Token(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "Token", "renewed", token)
+	assertEquals(t, "Persisted", "renewed", persisted)
+}
+
+func Test_RotatingTokenSource_ReturnsCurrentTokenIfRenewalFailsBeforeExpiry(t *testing.T) {
+	source := RotatingTokenSource{
+		CurrentToken: "current",
+		ExpiresAt:    time.Now().Add(time.Minute),
+		Renew: func(ctx context.Context, currentToken string) (string, time.Time, error) {
+			return "", time.Time{}, errors.New("renewal failed")
+		},
+	}
+	token, err := source.Token(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "Token", "current", token)
+}
+
+func Test_RotatingTokenSource_ReturnsErrorIfRenewalFailsAfterExpiry(t *testing.T) {
+	source := RotatingTokenSource{
+		CurrentToken: "current",
+		ExpiresAt:    time.Now().Add(-time.Minute),
+		Renew: func(ctx context.Context, currentToken string) (string, time.Time, error) {
+			return "", time.Time{}, errors.New("renewal failed")
+		},
+	}
+	_, err := source.Token(context.TODO())
+	if err == nil {
+		t.Fatalf("Expected error for expired token that could not be renewed")
+	}
+}
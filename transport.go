@@ -0,0 +1,159 @@
+package infomaniak
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Tuning of the transport used for API calls, bursts of record operations reuse the connections to the API
+const (
+	transportMaxIdleConnsPerHost   = 16
+	transportIdleConnTimeout       = 90 * time.Second
+	transportTlsHandshakeTimeout   = 10 * time.Second
+	transportResponseHeaderTimeout = 30 * time.Second
+	transportTlsSessionCacheSize   = 32
+)
+
+// newHttpClient returns the http client used to call the API, its transport keeps connections alive,
+// attempts HTTP/2 and caches TLS sessions, so that consecutive API calls are not slowed down by handshakes
+func (p *Provider) newHttpClient() (*http.Client, error) {
+	network, err := getNetwork(p.IPVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &net.Dialer{
+		Timeout:       p.DialTimeout,
+		KeepAlive:     30 * time.Second,
+		FallbackDelay: p.FallbackDelay,
+	}
+	if p.DialTimeout <= 0 {
+		dialer.Timeout = 30 * time.Second
+	}
+	if p.Resolver != "" {
+		dialer.Resolver = newResolver(p.Resolver, p.DialTimeout)
+	}
+
+	transport := newTunedTransport()
+	transport.DialContext = func(ctx context.Context, _ string, addr string) (net.Conn, error) {
+		return dialer.DialContext(ctx, network, addr)
+	}
+	if p.HTTPProxy != "" {
+		proxyUrl, err := parseProxyUrl(p.HTTPProxy)
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = http.ProxyURL(proxyUrl)
+	}
+	if p.hasTlsOptions() {
+		transport.TLSClientConfig, err = p.newTlsConfig()
+		if err != nil {
+			return nil, err
+		}
+		if transport.TLSClientConfig.ClientSessionCache == nil {
+			transport.TLSClientConfig.ClientSessionCache = tls.NewLRUClientSessionCache(transportTlsSessionCacheSize)
+		}
+	}
+	return &http.Client{Transport: transport}, nil
+}
+
+// newTunedTransport returns a transport based on the default transport that is tuned for repeated calls to the API
+func newTunedTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ForceAttemptHTTP2 = true
+	transport.MaxIdleConnsPerHost = transportMaxIdleConnsPerHost
+	transport.IdleConnTimeout = transportIdleConnTimeout
+	transport.TLSHandshakeTimeout = transportTlsHandshakeTimeout
+	transport.ResponseHeaderTimeout = transportResponseHeaderTimeout
+	transport.TLSClientConfig = &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ClientSessionCache: tls.NewLRUClientSessionCache(transportTlsSessionCacheSize),
+	}
+	return transport
+}
+
+// hasTlsOptions returns if any option that requires a custom TLS configuration is set
+func (p *Provider) hasTlsOptions() bool {
+	return p.TLSConfig != nil || p.CACertFile != "" || p.ClientCertFile != "" || p.ClientKeyFile != ""
+}
+
+// newTlsConfig returns the TLS configuration based on the configured TLS options
+func (p *Provider) newTlsConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if p.TLSConfig != nil {
+		tlsConfig = p.TLSConfig.Clone()
+	}
+
+	if p.CACertFile != "" {
+		pem, err := ioutil.ReadFile(p.CACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read CA certificates: %v", err)
+		}
+		rootCAs, err := x509.SystemCertPool()
+		if err != nil || rootCAs == nil {
+			rootCAs = x509.NewCertPool()
+		}
+		if !rootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no valid CA certificates found in %s", p.CACertFile)
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+
+	if p.ClientCertFile != "" || p.ClientKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(p.ClientCertFile, p.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not load client certificate: %v", err)
+		}
+		tlsConfig.Certificates = append(tlsConfig.Certificates, cert)
+	}
+	return tlsConfig, nil
+}
+
+// parseProxyUrl parses the URL of a http, https or socks5 proxy
+func parseProxyUrl(rawUrl string) (*url.URL, error) {
+	proxyUrl, err := url.Parse(rawUrl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %v", err)
+	}
+	switch proxyUrl.Scheme {
+	case "http", "https", "socks5":
+		return proxyUrl, nil
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme '%s', expected http, https or socks5", proxyUrl.Scheme)
+	}
+}
+
+// getNetwork returns the network to dial for the given IP version
+func getNetwork(ipVersion string) (string, error) {
+	switch ipVersion {
+	case "":
+		return "tcp", nil
+	case "4":
+		return "tcp4", nil
+	case "6":
+		return "tcp6", nil
+	default:
+		return "", fmt.Errorf("invalid IP version '%s', expected '4' or '6'", ipVersion)
+	}
+}
+
+// newResolver returns a resolver that sends all DNS queries to the given server address
+func newResolver(server string, timeout time.Duration) *net.Resolver {
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "53")
+	}
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network string, _ string) (net.Conn, error) {
+			dialer := net.Dialer{Timeout: timeout}
+			return dialer.DialContext(ctx, network, server)
+		},
+	}
+}
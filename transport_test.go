@@ -0,0 +1,88 @@
+package infomaniak
+
+import (
+	"crypto/tls"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func Test_NewHttpClient_ReturnsTunedClientIfNoOptionsSet(t *testing.T) {
+	provider := Provider{}
+	client, err := provider.newHttpClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport := client.Transport.(*http.Transport)
+	if !transport.ForceAttemptHTTP2 || transport.TLSClientConfig.ClientSessionCache == nil {
+		t.Fatalf("Expected tuned transport to be used")
+	}
+	assertEqualsInt(t, "MaxIdleConnsPerHost", transportMaxIdleConnsPerHost, transport.MaxIdleConnsPerHost)
+}
+
+func Test_NewHttpClient_ReturnsCustomClientIfDialOptionsSet(t *testing.T) {
+	provider := Provider{DialTimeout: 5 * time.Second, IPVersion: "6", Resolver: "1.1.1.1"}
+	client, err := provider.newHttpClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client == http.DefaultClient {
+		t.Fatalf("Expected custom http client to be used")
+	}
+}
+
+func Test_NewHttpClient_ReturnsErrorForInvalidIpVersion(t *testing.T) {
+	provider := Provider{IPVersion: "5"}
+	_, err := provider.newHttpClient()
+	if err == nil {
+		t.Fatalf("Expected error for invalid IP version")
+	}
+}
+
+func Test_NewHttpClient_UsesConfiguredProxy(t *testing.T) {
+	provider := Provider{HTTPProxy: "socks5://proxy.example.com:1080"}
+	client, err := provider.newHttpClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, apiBaseUrl, nil)
+	proxyUrl, err := client.Transport.(*http.Transport).Proxy(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "Proxy", "socks5://proxy.example.com:1080", proxyUrl.String())
+}
+
+func Test_NewHttpClient_ReturnsErrorForUnsupportedProxyScheme(t *testing.T) {
+	provider := Provider{HTTPProxy: "ftp://proxy.example.com"}
+	_, err := provider.newHttpClient()
+	if err == nil {
+		t.Fatalf("Expected error for unsupported proxy scheme")
+	}
+}
+
+func Test_NewHttpClient_UsesConfiguredTlsConfig(t *testing.T) {
+	provider := Provider{TLSConfig: &tls.Config{ServerName: "api.example.com"}}
+	client, err := provider.newHttpClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "ServerName", "api.example.com", client.Transport.(*http.Transport).TLSClientConfig.ServerName)
+}
+
+func Test_NewHttpClient_ReturnsErrorIfCaFileContainsNoCertificates(t *testing.T) {
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	err := ioutil.WriteFile(caFile, []byte("no certificate"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	provider := Provider{CACertFile: caFile}
+	_, err = provider.newHttpClient()
+	if err == nil {
+		t.Fatalf("Expected error for CA file without certificates")
+	}
+}
@@ -0,0 +1,111 @@
+package infomaniak
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+// TTLs offered by infomaniak, other TTLs are rejected by the API. Like the TTL of records, they are numbers of seconds
+// stored in a time.Duration, so that they can be used as libdns.Record.TTL directly.
+const (
+	TTLOneMinute      time.Duration = 60
+	TTLFiveMinutes    time.Duration = 5 * 60
+	TTLFifteenMinutes time.Duration = 15 * 60
+	TTLOneHour        time.Duration = 60 * 60
+	TTLSixHours       time.Duration = 6 * 60 * 60
+	TTLTwelveHours    time.Duration = 12 * 60 * 60
+	TTLOneDay         time.Duration = 24 * 60 * 60
+)
+
+// TTLPresets all TTLs offered by infomaniak in ascending order
+var TTLPresets = []time.Duration{TTLOneMinute, TTLFiveMinutes, TTLFifteenMinutes, TTLOneHour, TTLSixHours, TTLTwelveHours, TTLOneDay}
+
+// TTLAuto is the TTL of records whose TTL is chosen by the provider according to its AutoTTL policy
+const TTLAuto time.Duration = 0
+
+// AutoTTLPolicy decides how the TTL of records with TTLAuto is chosen
+type AutoTTLPolicy string
+
+const (
+	// AutoTTLDefault applies the provider's DefaultTTL
+	AutoTTLDefault AutoTTLPolicy = "default"
+
+	// AutoTTLInherit applies the TTL of the existing records with the same name and type, or the DefaultTTL if there are none
+	AutoTTLInherit AutoTTLPolicy = "inherit"
+
+	// AutoTTLError rejects records with TTLAuto
+	AutoTTLError AutoTTLPolicy = "error"
+)
+
+// NearestAllowedTTL returns the TTL offered by infomaniak that is nearest to the given TTL in seconds, the shorter TTL is returned
+// if the TTL lies exactly in between two TTLs. TTLs are rounded the same way before records are written.
+func NearestAllowedTTL(ttl time.Duration) time.Duration {
+	nearest := TTLPresets[0]
+	for _, preset := range TTLPresets[1:] {
+		if absDuration(preset-ttl) < absDuration(nearest-ttl) {
+			nearest = preset
+		}
+	}
+	return nearest
+}
+
+// nearestAllowedTtlSecs returns the TTL in seconds offered by infomaniak that is nearest to the given TTL in seconds
+func nearestAllowedTtlSecs(ttlSecs uint) uint {
+	return uint(NearestAllowedTTL(time.Duration(ttlSecs)))
+}
+
+// absDuration returns the absolute value of the duration
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// getDefaultTtlSecs returns the configured default TTL in seconds, 300 seconds if not set
+func (p *Provider) getDefaultTtlSecs() uint {
+	if p.DefaultTTL <= 0 {
+		return defaultTtlSecs
+	}
+	return uint(p.DefaultTTL)
+}
+
+// resolveAutoTtls returns the records with TTLAuto replaced according to the AutoTTL policy, existing records are
+// only loaded if a TTL is inherited - all other TTLs are kept and rounded to the nearest allowed TTL when written
+func (p *Provider) resolveAutoTtls(ctx context.Context, zone string, records []libdns.Record) ([]libdns.Record, error) {
+	var existingTtls map[string]time.Duration
+	result := make([]libdns.Record, 0, len(records))
+	for _, rec := range records {
+		if rec.TTL != TTLAuto {
+			result = append(result, rec)
+			continue
+		}
+		switch p.AutoTTL {
+		case AutoTTLError:
+			return nil, fmt.Errorf("%s record '%s' has no TTL, which is rejected by the AutoTTL policy", rec.Type, rec.Name)
+		case AutoTTLInherit:
+			if existingTtls == nil {
+				existingRecs, err := p.getRecords(ctx, zone)
+				if err != nil {
+					return nil, err
+				}
+				existingTtls = make(map[string]time.Duration, len(existingRecs))
+				for _, existingRec := range existingRecs {
+					existingTtls[getCoordinates(existingRec)] = existingRec.TTL
+				}
+			}
+			if ttl, ok := existingTtls[getCoordinates(rec)]; ok && ttl != TTLAuto {
+				rec.TTL = ttl
+			} else {
+				rec.TTL = time.Duration(p.getDefaultTtlSecs())
+			}
+		default:
+			rec.TTL = time.Duration(p.getDefaultTtlSecs())
+		}
+		result = append(result, rec)
+	}
+	return result, nil
+}
@@ -0,0 +1,71 @@
+package infomaniak
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+func Test_NearestAllowedTTL_RoundsToNearestPreset(t *testing.T) {
+	cases := map[time.Duration]time.Duration{
+		0:            TTLOneMinute,
+		90:           TTLOneMinute,
+		4 * 60:       TTLFiveMinutes,
+		50 * 60:      TTLOneHour,
+		48 * 60 * 60: TTLOneDay,
+	}
+	for ttl, expected := range cases {
+		assertEqualsInt(t, fmt.Sprintf("TTL for %d", ttl), int(expected), int(NearestAllowedTTL(ttl)))
+	}
+}
+
+func Test_ToInfomaniakRecord_RoundsTtlToPreset(t *testing.T) {
+	ikRec := ToInfomaniakRecord(&libdns.Record{Type: "A", Name: "www", Value: "192.0.2.1", TTL: 3500}, "example.com")
+	assertEqualsInt(t, "TTL", 3600, int(ikRec.TtlInSec))
+}
+
+func Test_ToInfomaniakRecord_KeepsTtlsOfPresets(t *testing.T) {
+	for _, ttl := range []time.Duration{300, 3600, TTLFiveMinutes, TTLOneHour} {
+		ikRec := ToInfomaniakRecord(&libdns.Record{Type: "A", Name: "www", Value: "192.0.2.1", TTL: ttl}, "example.com")
+		assertEqualsInt(t, fmt.Sprintf("TTL for %d", ttl), int(ttl), int(ikRec.TtlInSec))
+	}
+}
+
+func Test_SetRecords_AppliesAutoTtlPolicy(t *testing.T) {
+	written := make([]IkRecord, 0)
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			return []IkRecord{{ID: "1", Type: "A", SourceIdn: "www.example.com", Target: "192.0.2.1", TtlInSec: 21600}}, nil
+		},
+		setter: func(ctx context.Context, zone string, record IkRecord) (*IkRecord, error) {
+			written = append(written, record)
+			return &record, nil
+		},
+	}
+	records := []libdns.Record{{Type: "A", Name: "www", Value: "192.0.2.2", TTL: TTLAuto}, {Type: "A", Name: "mail", Value: "192.0.2.3", TTL: TTLAuto}}
+
+	provider := Provider{client: &client, DefaultTTL: TTLOneHour}
+	_, err := provider.SetRecords(context.TODO(), "example.com", records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "TTL with default policy", 3600, int(written[0].TtlInSec))
+
+	written = written[:0]
+	provider = Provider{client: &client, AutoTTL: AutoTTLInherit}
+	_, err = provider.SetRecords(context.TODO(), "example.com", records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsInt(t, "inherited TTL", 21600, int(written[0].TtlInSec))
+	assertEqualsInt(t, "TTL without existing records", 300, int(written[1].TtlInSec))
+
+	provider = Provider{client: &client, AutoTTL: AutoTTLError}
+	_, err = provider.SetRecords(context.TODO(), "example.com", records)
+	if err == nil {
+		t.Fatalf("Expected error for record without TTL")
+	}
+}
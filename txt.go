@@ -0,0 +1,110 @@
+package infomaniak
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Maximum length of a single character-string in bytes as defined by RFC 1035
+const maxCharacterStringLength = 255
+
+// quoteCharacterStrings returns the value in zone file presentation format: split into quoted character-strings
+// of at most 255 bytes, with quotes and backslashes escaped and non-printable bytes escaped as \DDD
+func quoteCharacterStrings(value string) string {
+	var builder strings.Builder
+	builder.Grow(len(value) + 2)
+	for start := 0; ; start += maxCharacterStringLength {
+		end := start + maxCharacterStringLength
+		if end > len(value) {
+			end = len(value)
+		}
+		if start > 0 {
+			builder.WriteByte(' ')
+		}
+		builder.WriteByte('"')
+		for i := start; i < end; i++ {
+			c := value[i]
+			switch {
+			case c == '"' || c == '\\':
+				builder.WriteByte('\\')
+				builder.WriteByte(c)
+			case c < 0x20 || c >= 0x7f:
+				fmt.Fprintf(&builder, "\\%03d", c)
+			default:
+				builder.WriteByte(c)
+			}
+		}
+		builder.WriteByte('"')
+		if end >= len(value) {
+			return builder.String()
+		}
+	}
+}
+
+// unquoteCharacterStrings parses one or multiple character-strings in zone file presentation format,
+// which are either quoted or delimited by whitespace, and returns their concatenated unescaped content
+func unquoteCharacterStrings(s string) (string, error) {
+	var builder strings.Builder
+	builder.Grow(len(s))
+	i := 0
+	for {
+		for i < len(s) && (s[i] == ' ' || s[i] == '\t') {
+			i++
+		}
+		if i >= len(s) {
+			return builder.String(), nil
+		}
+
+		quoted := s[i] == '"'
+		closed := false
+		if quoted {
+			i++
+		}
+		for i < len(s) {
+			c := s[i]
+			if quoted && c == '"' {
+				closed = true
+				i++
+				break
+			}
+			if !quoted && (c == ' ' || c == '\t') {
+				break
+			}
+			if !quoted && c == '"' {
+				return "", fmt.Errorf("unexpected quote at position %d", i)
+			}
+			if c != '\\' {
+				builder.WriteByte(c)
+				i++
+				continue
+			}
+
+			if i+1 >= len(s) {
+				return "", errors.New("unterminated escape sequence at end of value")
+			}
+			if !isDigit(s[i+1]) {
+				builder.WriteByte(s[i+1])
+				i += 2
+				continue
+			}
+			if i+3 >= len(s) || !isDigit(s[i+2]) || !isDigit(s[i+3]) {
+				return "", fmt.Errorf("invalid decimal escape sequence at position %d", i)
+			}
+			value := int(s[i+1]-'0')*100 + int(s[i+2]-'0')*10 + int(s[i+3]-'0')
+			if value > 255 {
+				return "", fmt.Errorf("decimal escape sequence at position %d exceeds 255", i)
+			}
+			builder.WriteByte(byte(value))
+			i += 4
+		}
+		if quoted && !closed {
+			return "", errors.New("unterminated quoted string")
+		}
+	}
+}
+
+// isDigit returns if the byte is an ASCII digit
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
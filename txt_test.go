@@ -0,0 +1,74 @@
+package infomaniak
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_QuoteCharacterStrings_EscapesQuotesBackslashesAndNonPrintableBytes(t *testing.T) {
+	assertEquals(t, "quoted", `"a\"b\\c\010d\195\164"`, quoteCharacterStrings("a\"b\\c\ndä"))
+}
+
+func Test_QuoteCharacterStrings_QuotesEmptyValue(t *testing.T) {
+	assertEquals(t, "quoted", `""`, quoteCharacterStrings(""))
+}
+
+func Test_QuoteCharacterStrings_SplitsLongValues(t *testing.T) {
+	quoted := quoteCharacterStrings(strings.Repeat("a", 300))
+	assertEquals(t, "quoted", `"`+strings.Repeat("a", 255)+`" "`+strings.Repeat("a", 45)+`"`, quoted)
+}
+
+func Test_UnquoteCharacterStrings_ConcatenatesMultipleStrings(t *testing.T) {
+	value, err := unquoteCharacterStrings(`"v=DKIM1; k=rsa; " "p=abc" def`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "value", "v=DKIM1; k=rsa; p=abcdef", value)
+}
+
+func Test_UnquoteCharacterStrings_UnescapesSequences(t *testing.T) {
+	value, err := unquoteCharacterStrings(`"a\"b\\c\010d;"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "value", "a\"b\\c\nd;", value)
+}
+
+func Test_UnquoteCharacterStrings_ReturnsErrorForMalformedValues(t *testing.T) {
+	for _, malformed := range []string{`"abc`, `abc\`, `"\25"`, `"\256"`, `ab"c`} {
+		if _, err := unquoteCharacterStrings(malformed); err == nil {
+			t.Fatalf("Expected error for malformed value %s", malformed)
+		}
+	}
+}
+
+func Fuzz_QuoteCharacterStrings_RoundTrips(f *testing.F) {
+	for _, seed := range []string{"", "simple", `with "quotes"`, `back\slash`, "new\nline", "äöü", strings.Repeat("x", 600)} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, value string) {
+		unquoted, err := unquoteCharacterStrings(quoteCharacterStrings(value))
+		if err != nil {
+			t.Fatalf("Could not unquote quoted value %q: %v", value, err)
+		}
+		if unquoted != value {
+			t.Fatalf("Expected %q after round trip, got %q", value, unquoted)
+		}
+	})
+}
+
+func Fuzz_UnquoteCharacterStrings_IsStableForValidInput(f *testing.F) {
+	for _, seed := range []string{`""`, `"a" "b"`, `abc def`, `"\"\\"`, `"\065"`, `"unterminated`, `\`} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, input string) {
+		value, err := unquoteCharacterStrings(input)
+		if err != nil {
+			return
+		}
+		requoted, err := unquoteCharacterStrings(quoteCharacterStrings(value))
+		if err != nil || requoted != value {
+			t.Fatalf("Expected %q to be stable after requoting, got %q (%v)", value, requoted, err)
+		}
+	})
+}
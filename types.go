@@ -3,6 +3,9 @@ package infomaniak
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
 )
 
 // IkRecord infomaniak API record return type
@@ -28,6 +31,41 @@ type IkRecord struct {
 	// Priority of this record - default value on infomaniak's side
 	// for records that do not have a priority is 10
 	Priority uint `json:"priority,omitempty"`
+
+	// UpdatedAt point in time the record was last changed, zero if not returned by the API
+	UpdatedAt time.Time `json:"-"`
+
+	// DyndnsID ID of the dynamic DNS configuration that manages this record, empty if the record is not managed by dynamic DNS
+	DyndnsID string `json:"-"`
+
+	// Description attributes of the record, nil if the record was listed without description
+	Description *IkRecordDescription `json:"-"`
+
+	// DescriptionRaw all attributes of the record's description as returned by the API, including attributes
+	// that are not modeled by IkRecordDescription - they are sent back to the API when the record is written
+	DescriptionRaw map[string]json.RawMessage `json:"description,omitempty"`
+}
+
+// IkRecordDescription priority-like attributes of a record that infomaniak returns in the record's description,
+// attributes that are not set for the record's type are zero
+type IkRecordDescription struct {
+	// Priority of MX, SRV, URI, HTTPS and SVCB records
+	Priority uint
+
+	// Weight of SRV and URI records
+	Weight uint
+
+	// Port of SRV records
+	Port uint
+
+	// Order of NAPTR records
+	Order uint
+
+	// Preference of NAPTR records
+	Preference uint
+
+	// Flags of NAPTR and CAA records
+	Flags string
 }
 
 // IkResponse infomaniak API response
@@ -35,11 +73,27 @@ type IkResponse struct {
 	// Result of the API call: either "success" or "error"
 	Result string `json:"result"`
 
-	// Data is set if API call was successful and contains the actual response
+	// Data is set if API call was successful and contains the actual response,
+	// unless the data was decoded directly into the struct passed by the caller
 	Data json.RawMessage `json:"data,omitempty"`
 
 	// Error is set if the API call failed and contains all errors that occurred
 	Error json.RawMessage `json:"error,omitempty"`
+
+	// Page number of paginated responses, starting at 1 - zero if the response is not paginated
+	Page int `json:"page,omitempty"`
+
+	// Pages total number of pages of paginated responses - zero if the response is not paginated
+	Pages int `json:"pages,omitempty"`
+
+	// StatusCode of the HTTP response
+	StatusCode int `json:"-"`
+
+	// Header of the HTTP response, e.g. containing rate limit counters
+	Header http.Header `json:"-"`
+
+	// RawBody of the HTTP response, only set for responses passed to a response hook
+	RawBody []byte `json:"-"`
 }
 
 // IkDomain infomaniak API domain return type
@@ -62,3 +116,43 @@ type IkClient interface {
 	// GetDnsRecordsForZone returns all records of the given zone
 	GetDnsRecordsForZone(ctx context.Context, zone string) ([]IkRecord, error)
 }
+
+// IkRecordPatcher is implemented by clients that can update single attributes of a record, SetRecords uses it for updates
+// that only change the target or TTL of a record instead of sending the whole record again. Middlewares only pass partial
+// updates on if they implement it as well, otherwise the whole record is written through them.
+type IkRecordPatcher interface {
+	// PatchRecord updates the attributes of the record with the given ID that are set in changes
+	PatchRecord(ctx context.Context, zone string, id string, changes IkRecordPatch) error
+}
+
+// zoneResolver is implemented by clients that can resolve a zone to the domain whose records are managed for it, e.g. so
+// that the records of a domain are loaded only once for names of different subzones
+type zoneResolver interface {
+	// resolveZone returns the name of the domain whose records are managed for the zone
+	resolveZone(ctx context.Context, zone string) (string, error)
+}
+
+// errPatchNotSupported is returned by decorating clients whose decorated client does not implement IkRecordPatcher,
+// the whole record is written instead
+var errPatchNotSupported = errors.New("partial updates are not supported by the client")
+
+// IkRecordPatch attributes of a record changed by a partial update, attributes that are nil are not changed
+type IkRecordPatch struct {
+	// Target new value of the record
+	Target *string `json:"target,omitempty"`
+
+	// TtlInSec new TTL in seconds
+	TtlInSec *uint `json:"ttl,omitempty"`
+}
+
+// ZoneResolution describes which infomaniak domain was chosen for a requested zone that is not itself a domain
+type ZoneResolution struct {
+	// Zone as requested by the caller
+	Zone string
+
+	// Domain whose records are managed for the zone
+	Domain IkDomain
+
+	// Reason why the domain was chosen
+	Reason string
+}
@@ -0,0 +1,40 @@
+package infomaniak
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Validate checks that the provider is configured correctly to manage the records of the zone by listing them once, and
+// returns an error with guidance on how to fix the configuration otherwise. The infomaniak API offers no endpoint to
+// introspect the scopes of a token, so missing scopes are derived from the status codes of the API.
+func (p *Provider) Validate(ctx context.Context, zone string) error {
+	zone = getWithoutTrailingDot(zone)
+	if p.APIToken == "" && p.TokenSource == nil && p.client == nil {
+		return errors.New("no API token configured, issue a token with the scope 'domain' at https://manager.infomaniak.com/v3/infomaniak-api")
+	}
+
+	client, err := p.getClient()
+	if err != nil {
+		return err
+	}
+	_, err = client.GetDnsRecordsForZone(ctx, zone)
+
+	var mappingErr *RecordMappingError
+	var notFoundErr *ZoneNotFoundError
+	var apiErr *ApiError
+	switch {
+	case err == nil || errors.As(err, &mappingErr):
+		return nil
+	case errors.As(err, &notFoundErr):
+		return fmt.Errorf("zone '%s' is not managed by a domain of the account the API token belongs to, check that the domain is part of the account: %w", zone, err)
+	case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized:
+		return fmt.Errorf("the API token is invalid or expired, issue a new token with the scope 'domain': %w", err)
+	case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("the API token is not allowed to manage the records of zone '%s', its scopes have to include 'domain': %w", zone, err)
+	default:
+		return err
+	}
+}
@@ -0,0 +1,37 @@
+package infomaniak
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func Test_Validate_ReturnsGuidanceForMissingScope(t *testing.T) {
+	apiErr := &ApiError{StatusCode: 403}
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			return nil, apiErr
+		},
+	}
+	provider := Provider{client: &client}
+
+	err := provider.Validate(context.TODO(), "example.com")
+	if !errors.Is(err, apiErr) || !strings.Contains(err.Error(), "'domain'") {
+		t.Fatalf("Expected guidance about the scope, got %v", err)
+	}
+}
+
+func Test_Validate_ReturnsNoErrorForValidConfiguration(t *testing.T) {
+	provider := Provider{client: &TestClient{}}
+
+	err := provider.Validate(context.TODO(), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = (&Provider{}).Validate(context.TODO(), "example.com")
+	if err == nil {
+		t.Fatalf("Expected error for missing token")
+	}
+}
@@ -0,0 +1,103 @@
+package infomaniak
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/libdns/libdns"
+)
+
+// Property tags of CAA records registered by IANA
+var knownCaaTags = map[string]bool{
+	"issue":        true,
+	"issuewild":    true,
+	"iodef":        true,
+	"issuemail":    true,
+	"issuevmc":     true,
+	"contactemail": true,
+	"contactphone": true,
+}
+
+// validateStrict checks the whole zone state of the model against the RFC record
+// constraints that are enforced in strict mode and returns all violations
+func (z *zoneModel) validateStrict(zone string) []string {
+	violations := make([]string, 0)
+	soaCount := 0
+	for _, name := range z.names() {
+		cnameCount := 0
+		for _, rec := range z.resolve(name) {
+			switch RecordType(normalizeType(rec.Type)) {
+			case TypeCNAME:
+				cnameCount++
+			case TypeSOA:
+				soaCount++
+			case TypeCAA:
+				if err := validateCaaValue(rec.Value); err != nil {
+					violations = append(violations, fmt.Sprintf("CAA record at '%s': %v", name, err))
+				}
+			case TypeSRV:
+				if z.isAlias(srvTarget(rec), zone) {
+					violations = append(violations, fmt.Sprintf("SRV record at '%s' points to alias '%s'", name, srvTarget(rec)))
+				}
+			default:
+				if err := validateRareTypeValue(rec.Type, rec.Value); err != nil {
+					violations = append(violations, fmt.Sprintf("%s record at '%s': %v", rec.Type, name, err))
+				}
+			}
+		}
+		if cnameCount > 1 {
+			violations = append(violations, fmt.Sprintf("name '%s' has %d CNAME records", name, cnameCount))
+		}
+	}
+	if soaCount > 1 {
+		violations = append(violations, fmt.Sprintf("zone has %d SOA records", soaCount))
+	}
+	return violations
+}
+
+// isAlias returns if the given fully qualified name is part of the zone and holds a CNAME record
+func (z *zoneModel) isAlias(fqdn string, zone string) bool {
+	fqdn = getWithoutTrailingDot(fqdn)
+	if fqdn == "" || !isInZone(fqdn, zone) {
+		return false
+	}
+	for _, rec := range z.resolve(libdns.RelativeName(fqdn, zone)) {
+		if RecordType(normalizeType(rec.Type)) == TypeCNAME {
+			return true
+		}
+	}
+	return false
+}
+
+// isInZone returns if the given fully qualified name equals the zone or is part of it
+func isInZone(fqdn string, zone string) bool {
+	fqdn = strings.ToLower(fqdn)
+	zone = strings.ToLower(zone)
+	return fqdn == zone || strings.HasSuffix(fqdn, "."+zone)
+}
+
+// srvTarget returns the target of a SRV record whose value is in the form "[weight] port target"
+func srvTarget(rec libdns.Record) string {
+	fields := strings.Fields(rec.Value)
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[len(fields)-1]
+}
+
+// validateCaaValue checks if the value of a CAA record has the form "flags tag value" with a known tag
+func validateCaaValue(value string) error {
+	fields := strings.Fields(value)
+	if len(fields) < 3 {
+		return fmt.Errorf("malformed value '%s', expected: '<flags> <tag> <value>'", value)
+	}
+	flags, err := strconv.Atoi(fields[0])
+	if err != nil || flags < 0 || flags > 255 {
+		return fmt.Errorf("invalid flags '%s'", fields[0])
+	}
+	if !knownCaaTags[strings.ToLower(fields[1])] {
+		return fmt.Errorf("unknown tag '%s'", fields[1])
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package infomaniak
+
+import (
+	"testing"
+
+	"github.com/libdns/libdns"
+)
+
+func Test_ValidateStrict_ReturnsNoViolationsForValidZone(t *testing.T) {
+	model := newZoneModel([]libdns.Record{
+		{Name: "", Type: "SOA", Value: "ns11.infomaniak.ch. hostmaster.infomaniak.ch. 1 10800 3600 605800 3600"},
+		{Name: "", Type: "CAA", Value: `0 issue "letsencrypt.org"`},
+		{Name: "_sip._tcp", Type: "SRV", Value: "5060 sip.example.com"},
+		{Name: "sip", Type: "A", Value: "127.0.0.1"},
+	})
+	violations := model.validateStrict("example.com")
+	if len(violations) > 0 {
+		t.Fatalf("Expected no violations, got %v", violations)
+	}
+}
+
+func Test_ValidateStrict_DetectsMultipleSoaRecords(t *testing.T) {
+	model := newZoneModel([]libdns.Record{{Name: "", Type: "SOA"}, {Name: "sub", Type: "SOA"}})
+	assertEqualsInt(t, "violations", 1, len(model.validateStrict("example.com")))
+}
+
+func Test_ValidateStrict_DetectsUnknownCaaTag(t *testing.T) {
+	model := newZoneModel([]libdns.Record{{Name: "", Type: "CAA", Value: `0 issues "letsencrypt.org"`}})
+	assertEqualsInt(t, "violations", 1, len(model.validateStrict("example.com")))
+}
+
+func Test_ValidateStrict_DetectsSrvTargetPointingToAlias(t *testing.T) {
+	model := newZoneModel([]libdns.Record{
+		{Name: "_sip._tcp", Type: "SRV", Value: "5060 sip.example.com."},
+		{Name: "sip", Type: "CNAME", Value: "example.org"},
+	})
+	assertEqualsInt(t, "violations", 1, len(model.validateStrict("example.com")))
+}
+
+func Test_ValidateStrict_DetectsMultipleCnameRecords(t *testing.T) {
+	model := newZoneModel([]libdns.Record{{Name: "www", Type: "CNAME", Value: "a.org"}, {Name: "www", Type: "CNAME", Value: "b.org"}})
+	assertEqualsInt(t, "violations", 1, len(model.validateStrict("example.com")))
+}
+
+func Test_ValidateStrict_DetectsViolationsOfLowercaseTypes(t *testing.T) {
+	model := newZoneModel([]libdns.Record{
+		{Name: "www", Type: "cname", Value: "a.org"},
+		{Name: "www", Type: "Cname", Value: "b.org"},
+		{Name: "", Type: "caa", Value: `0 issues "letsencrypt.org"`},
+		{Name: "_sip._tcp", Type: "srv", Value: "5060 www.example.com."},
+	})
+	assertEqualsInt(t, "violations", 3, len(model.validateStrict("example.com")))
+}
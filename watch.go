@@ -0,0 +1,162 @@
+package infomaniak
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+// Default interval between two polls of a watched zone
+const defaultWatchInterval = time.Minute
+
+// ChangeType kind of change of a record detected by WatchZone
+type ChangeType string
+
+const (
+	// ChangeAdded the record was added to the zone
+	ChangeAdded ChangeType = "added"
+
+	// ChangeRemoved the record was removed from the zone
+	ChangeRemoved ChangeType = "removed"
+
+	// ChangeModified the record with the same ID was modified
+	ChangeModified ChangeType = "modified"
+)
+
+// ZoneEvent change of a record of a watched zone, or an error if the zone could not be polled
+type ZoneEvent struct {
+	// Zone that changed
+	Zone string
+
+	// Type of the change, not set for errors
+	Type ChangeType
+
+	// Record after the change, or the removed record
+	Record libdns.Record
+
+	// Previous state of modified records
+	Previous libdns.Record
+
+	// Err is set if the records of the zone could not be loaded, the zone is polled again in the next interval
+	Err error
+}
+
+// WatchZone polls the records of the zone in the given interval and sends an event for every record that was added, removed or modified
+// between two polls. The records loaded by the first poll are the baseline for which no events are sent, unless a WatchStore is configured
+// that contains the records last seen by a previous watcher. As the infomaniak API offers no webhooks, changes are only detected by polling.
+// The zone is polled every minute if the interval is not positive. The returned channel is closed once the context is done.
+func (p *Provider) WatchZone(ctx context.Context, zone string, interval time.Duration) <-chan ZoneEvent {
+	zone = getWithoutTrailingDot(zone)
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+	events := make(chan ZoneEvent)
+	go func() {
+		defer close(events)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		lastSeen, err := p.loadLastSeen(zone)
+		if err != nil && !sendEvent(ctx, events, ZoneEvent{Zone: zone, Err: err}) {
+			return
+		}
+		for {
+			records, err := p.getRecords(ctx, zone)
+			if err != nil && ctx.Err() == nil {
+				if !sendEvent(ctx, events, ZoneEvent{Zone: zone, Err: err}) {
+					return
+				}
+			} else if err == nil {
+				current := indexRecordsById(records)
+				if lastSeen != nil {
+					for _, event := range diffRecords(zone, lastSeen, current) {
+						if !sendEvent(ctx, events, event) {
+							return
+						}
+					}
+				}
+				lastSeen = current
+				if err := p.storeLastSeen(zone, records); err != nil && !sendEvent(ctx, events, ZoneEvent{Zone: zone, Err: err}) {
+					return
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return events
+}
+
+// loadLastSeen returns the records of the zone last seen by a watcher indexed by ID, or nil if no WatchStore is configured or nothing was stored yet
+func (p *Provider) loadLastSeen(zone string) (map[string]libdns.Record, error) {
+	if p.WatchStore == nil {
+		return nil, nil
+	}
+	records, _, ok, err := p.WatchStore.Load(zone)
+	if err != nil {
+		return nil, fmt.Errorf("could not load last seen records of zone '%s': %v", zone, err)
+	}
+	if !ok {
+		return nil, nil
+	}
+	return indexRecordsById(records), nil
+}
+
+// storeLastSeen stores the records of the zone last seen by a watcher if a WatchStore is configured
+func (p *Provider) storeLastSeen(zone string, records []libdns.Record) error {
+	if p.WatchStore == nil {
+		return nil
+	}
+	if err := p.WatchStore.Store(zone, records); err != nil {
+		return fmt.Errorf("could not store last seen records of zone '%s': %v", zone, err)
+	}
+	return nil
+}
+
+// sendEvent sends the event unless the context is done first, it returns if the event was sent
+func sendEvent(ctx context.Context, events chan<- ZoneEvent, event ZoneEvent) bool {
+	select {
+	case events <- event:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// indexRecordsById returns the records by their ID, records without ID are indexed by their coordinates and value
+func indexRecordsById(records []libdns.Record) map[string]libdns.Record {
+	index := make(map[string]libdns.Record, len(records))
+	for _, rec := range records {
+		key := rec.ID
+		if key == "" {
+			key = getCoordinates(rec) + "-" + rec.Value
+		}
+		index[key] = rec
+	}
+	return index
+}
+
+// diffRecords returns the events that lead from the previous to the current records, both indexed by ID
+func diffRecords(zone string, previous map[string]libdns.Record, current map[string]libdns.Record) []ZoneEvent {
+	events := make([]ZoneEvent, 0)
+	for id, rec := range current {
+		prevRec, ok := previous[id]
+		if !ok {
+			events = append(events, ZoneEvent{Zone: zone, Type: ChangeAdded, Record: rec})
+		} else if prevRec != rec {
+			events = append(events, ZoneEvent{Zone: zone, Type: ChangeModified, Record: rec, Previous: prevRec})
+		}
+	}
+	for id, rec := range previous {
+		if _, ok := current[id]; !ok {
+			events = append(events, ZoneEvent{Zone: zone, Type: ChangeRemoved, Record: rec})
+		}
+	}
+	return events
+}
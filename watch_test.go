@@ -0,0 +1,102 @@
+package infomaniak
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+func Test_WatchZone_SendsEventsForChangesBetweenPolls(t *testing.T) {
+	snapshots := [][]IkRecord{
+		{
+			{ID: "1", Type: "A", SourceIdn: "www.example.com", Target: "192.0.2.1"},
+			{ID: "2", Type: "TXT", SourceIdn: "example.com", Target: "token"},
+		},
+		{
+			{ID: "1", Type: "A", SourceIdn: "www.example.com", Target: "192.0.2.2"},
+			{ID: "3", Type: "MX", SourceIdn: "example.com", Target: "mail.example.com"},
+		},
+	}
+	var calls int
+	var mu sync.Mutex
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			snapshot := snapshots[len(snapshots)-1]
+			if calls < len(snapshots) {
+				snapshot = snapshots[calls]
+			}
+			calls++
+			return snapshot, nil
+		},
+	}
+	provider := Provider{client: &client}
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+
+	events := provider.WatchZone(ctx, "example.com.", time.Millisecond)
+	changes := make(map[ChangeType]ZoneEvent)
+	for len(changes) < 3 {
+		event := <-events
+		if event.Err != nil {
+			t.Fatal(event.Err)
+		}
+		changes[event.Type] = event
+	}
+	assertEquals(t, "added ID", "3", changes[ChangeAdded].Record.ID)
+	assertEquals(t, "removed ID", "2", changes[ChangeRemoved].Record.ID)
+	assertEquals(t, "modified value", "192.0.2.2", changes[ChangeModified].Record.Value)
+	assertEquals(t, "previous value", "192.0.2.1", changes[ChangeModified].Previous.Value)
+
+	cancel()
+	for range events {
+	}
+}
+
+func Test_WatchZone_ReportsChangesSinceLastStoredState(t *testing.T) {
+	store := &FileZoneStore{Dir: t.TempDir()}
+	err := store.Store("example.com", []libdns.Record{{ID: "1", Type: "A", Name: "www", Value: "192.0.2.1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			return []IkRecord{{ID: "1", Type: "A", SourceIdn: "www.example.com", Target: "192.0.2.1"}, {ID: "2", Type: "A", SourceIdn: "api.example.com", Target: "192.0.2.3"}}, nil
+		},
+	}
+	provider := Provider{client: &client, WatchStore: store}
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+
+	event := <-provider.WatchZone(ctx, "example.com", time.Hour)
+	if event.Err != nil {
+		t.Fatal(event.Err)
+	}
+	assertEquals(t, "change", string(ChangeAdded), string(event.Type))
+	assertEquals(t, "added ID", "2", event.Record.ID)
+}
+
+func Test_WatchZone_PollsWithDefaultIntervalIfIntervalIsNotPositive(t *testing.T) {
+	polled := make(chan bool, 1)
+	client := TestClient{
+		getter: func(ctx context.Context, zone string) ([]IkRecord, error) {
+			select {
+			case polled <- true:
+			default:
+			}
+			return []IkRecord{}, nil
+		},
+	}
+	provider := Provider{client: &client}
+	ctx, cancel := context.WithCancel(context.TODO())
+
+	events := provider.WatchZone(ctx, "example.com", 0)
+	<-polled
+	cancel()
+	for range events {
+	}
+}
@@ -0,0 +1,123 @@
+package infomaniak
+
+import (
+	"strings"
+
+	"github.com/libdns/libdns"
+)
+
+// zoneModel is an in-memory representation of a zone's records that is used
+// to simulate planned changes locally before any API call is made
+type zoneModel struct {
+	// records of the zone grouped by their lower case relative name
+	recordsByName map[string][]libdns.Record
+
+	// normalized names of the records with an ID by their ID
+	namesById map[string]string
+}
+
+// newZoneModel returns a zone model that contains the given records
+func newZoneModel(records []libdns.Record) *zoneModel {
+	model := &zoneModel{
+		recordsByName: make(map[string][]libdns.Record, len(records)),
+		namesById:     make(map[string]string, len(records)),
+	}
+	for _, rec := range records {
+		model.put(rec)
+	}
+	return model
+}
+
+// put adds a record to the model - if the record has an ID and a record with the same ID
+// already exists, the existing record is replaced
+func (z *zoneModel) put(record libdns.Record) {
+	name := normalizeName(record.Name)
+	if record.ID != "" {
+		z.removeById(record.ID)
+		z.namesById[record.ID] = name
+	}
+	z.recordsByName[name] = append(z.recordsByName[name], record)
+}
+
+// count returns the number of records in the model
+func (z *zoneModel) count() int {
+	count := 0
+	for _, recs := range z.recordsByName {
+		count += len(recs)
+	}
+	return count
+}
+
+// remove removes a record from the model - records with an ID are removed by their ID,
+// records without ID are removed if name, type and value match
+func (z *zoneModel) remove(record libdns.Record) {
+	if record.ID != "" {
+		z.removeById(record.ID)
+		return
+	}
+	name := normalizeName(record.Name)
+	remaining := make([]libdns.Record, 0)
+	for _, rec := range z.recordsByName[name] {
+		if rec.Type != record.Type || rec.Value != record.Value {
+			remaining = append(remaining, rec)
+		} else if rec.ID != "" {
+			delete(z.namesById, rec.ID)
+		}
+	}
+	z.recordsByName[name] = remaining
+}
+
+// removeById removes the record with the given ID from the model if it exists
+func (z *zoneModel) removeById(id string) {
+	name, ok := z.namesById[id]
+	if !ok {
+		return
+	}
+	delete(z.namesById, id)
+	recs := z.recordsByName[name]
+	for i, rec := range recs {
+		if rec.ID == id {
+			z.recordsByName[name] = append(recs[:i:i], recs[i+1:]...)
+			return
+		}
+	}
+}
+
+// resolve returns all records the model contains for the given relative name
+func (z *zoneModel) resolve(name string) []libdns.Record {
+	return z.recordsByName[normalizeName(name)]
+}
+
+// names returns all relative names the model contains records for
+func (z *zoneModel) names() []string {
+	names := make([]string, 0, len(z.recordsByName))
+	for name, recs := range z.recordsByName {
+		if len(recs) > 0 {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// normalizeName returns the given relative name in the form used as key by the zone model
+func normalizeName(name string) string {
+	name = strings.ToLower(getWithoutTrailingDot(name))
+	if name == "@" {
+		return ""
+	}
+	return name
+}
+
+// cnameConflict returns an error if the given name holds a CNAME record alongside other records
+func (z *zoneModel) cnameConflict(name string) error {
+	recs := z.resolve(name)
+	if len(recs) <= 1 {
+		return nil
+	}
+	for _, rec := range recs {
+		if RecordType(normalizeType(rec.Type)) == TypeCNAME {
+			return &CnameConflictError{Name: normalizeName(name), Records: recs}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package infomaniak
+
+import (
+	"testing"
+
+	"github.com/libdns/libdns"
+)
+
+func Test_ZoneModel_ResolveReturnsRecordsOfName(t *testing.T) {
+	model := newZoneModel([]libdns.Record{
+		{ID: "1", Name: "www", Type: "A", Value: "127.0.0.1"},
+		{ID: "2", Name: "mail", Type: "A", Value: "127.0.0.2"},
+	})
+
+	result := model.resolve("www")
+	if len(result) != 1 {
+		t.Fatalf("Expected %d records, got %d", 1, len(result))
+	}
+	assertEquals(t, "ID", "1", result[0].ID)
+}
+
+func Test_ZoneModel_TreatsAtAndEmptyNameAsApex(t *testing.T) {
+	model := newZoneModel([]libdns.Record{{ID: "1", Name: "@", Type: "A"}})
+	if len(model.resolve("")) != 1 {
+		t.Fatalf("Expected apex record to be resolved by empty name")
+	}
+}
+
+func Test_ZoneModel_PutReplacesRecordWithSameId(t *testing.T) {
+	model := newZoneModel([]libdns.Record{{ID: "1", Name: "www", Type: "A", Value: "127.0.0.1"}})
+	model.put(libdns.Record{ID: "1", Name: "other", Type: "CNAME", Value: "example.com"})
+
+	if len(model.resolve("www")) != 0 {
+		t.Fatalf("Expected replaced record to be removed from its old name")
+	}
+	if len(model.resolve("other")) != 1 {
+		t.Fatalf("Expected replaced record to be resolvable by its new name")
+	}
+}
+
+func Test_ZoneModel_RemoveWithoutIdRemovesMatchingRecords(t *testing.T) {
+	model := newZoneModel([]libdns.Record{
+		{ID: "1", Name: "www", Type: "A", Value: "127.0.0.1"},
+		{ID: "2", Name: "www", Type: "A", Value: "127.0.0.2"},
+	})
+	model.remove(libdns.Record{Name: "www", Type: "A", Value: "127.0.0.1"})
+
+	result := model.resolve("www")
+	if len(result) != 1 {
+		t.Fatalf("Expected %d records, got %d", 1, len(result))
+	}
+	assertEquals(t, "ID", "2", result[0].ID)
+}
+
+func Test_ZoneModel_CnameConflictDetectsCnameNextToOtherRecords(t *testing.T) {
+	model := newZoneModel([]libdns.Record{
+		{ID: "1", Name: "www", Type: "A", Value: "127.0.0.1"},
+		{Name: "www", Type: "CNAME", Value: "example.com"},
+	})
+	if model.cnameConflict("www") == nil {
+		t.Fatalf("Expected conflict to be detected")
+	}
+}
+
+func Test_ZoneModel_CnameConflictAllowsSingleCname(t *testing.T) {
+	model := newZoneModel([]libdns.Record{{Name: "www", Type: "CNAME", Value: "example.com"}})
+	if err := model.cnameConflict("www"); err != nil {
+		t.Fatalf("Expected no conflict, got %v", err)
+	}
+}
+
+func Test_ZoneModel_CnameConflictDetectsLowercaseCname(t *testing.T) {
+	model := newZoneModel([]libdns.Record{
+		{ID: "1", Name: "www", Type: "A", Value: "127.0.0.1"},
+		{Name: "www", Type: "cname", Value: "example.com"},
+	})
+	if model.cnameConflict("www") == nil {
+		t.Fatalf("Expected conflict to be detected")
+	}
+}